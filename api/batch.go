@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/token"
+	"github.com/maslow123/todoapp-services/util"
+)
+
+const maxBatchTodoItems = 500
+
+// batchTodo applies a list of operations to many todos at once. Items that
+// fail the ownership checks are reported in the results without failing the
+// rest of the batch.
+func (server *Server) batchTodo(ctx *gin.Context) {
+	var req BatchTodoRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	items := 0
+	for _, op := range req.Operations {
+		items += len(op.TodoIDs)
+	}
+	if items > maxBatchTodoItems {
+		abortWithError(ctx, http.StatusBadRequest, errors.New("too-many-items"))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	var loc *time.Location
+	arg := db.BatchTodoTxParams{
+		UserEmail:  authPayload.Username,
+		Operations: make([]db.BatchTodoOperation, 0, len(req.Operations)),
+	}
+	for i, op := range req.Operations {
+		operation := db.BatchTodoOperation{
+			Op:         op.Op,
+			TodoIDs:    op.TodoIDs,
+			CategoryID: op.CategoryID,
+		}
+
+		switch op.Op {
+		case db.BatchOpMove:
+			if op.CategoryID == 0 {
+				abortWithError(ctx, http.StatusBadRequest, fmt.Errorf("operations-%d-missing-category-id", i))
+				return
+			}
+		case db.BatchOpSetPriority:
+			if op.IsPriority == nil {
+				abortWithError(ctx, http.StatusBadRequest, fmt.Errorf("operations-%d-missing-is-priority", i))
+				return
+			}
+			operation.IsPriority = *op.IsPriority
+		case db.BatchOpReschedule:
+			if loc == nil {
+				var err error
+				loc, err = server.userLocation(ctx, authPayload.Username)
+				if err != nil {
+					abortWithError(ctx, http.StatusInternalServerError, err)
+					return
+				}
+			}
+
+			var err error
+			operation.Date, operation.AllDay, err = util.ParseDueDate(op.Date, loc)
+			if err != nil {
+				abortWithError(ctx, http.StatusBadRequest, fmt.Errorf("operations-%d-invalid-date", i))
+				return
+			}
+		}
+
+		arg.Operations = append(arg.Operations, operation)
+	}
+
+	result, err := server.store.BatchTodoTx(ctx, arg)
+	if err != nil {
+		log.Println(err)
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	for _, item := range result.Results {
+		switch {
+		case item.Error != "":
+		case item.Op == db.BatchOpDelete:
+			server.publish(arg.UserEmail, db.EventTodoDeleted, db.DeleteTodoTxParams{
+				ID:        item.TodoID,
+				UserEmail: arg.UserEmail,
+			})
+		case item.Op == db.BatchOpComplete:
+			server.publish(arg.UserEmail, db.EventTodoCompleted, item.Todo)
+		default:
+			server.publish(arg.UserEmail, db.EventTodoUpdated, item.Todo)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
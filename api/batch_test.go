@@ -0,0 +1,219 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchTodoAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	user.TimeZone = "Asia/Jakarta"
+	todo := randomTodo(t)
+	todo.UserEmail = user.Email
+
+	manyIDs := make([]int32, maxBatchTodoItems+1)
+	for i := range manyIDs {
+		manyIDs[i] = int32(i + 1)
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"operations": []gin.H{
+					{"op": "complete", "todo_ids": []int32{todo.ID, 1000}},
+					{"op": "move", "todo_ids": []int32{todo.ID}, "category_id": 7},
+					{"op": "set_priority", "todo_ids": []int32{todo.ID}, "is_priority": true},
+					{"op": "reschedule", "todo_ids": []int32{todo.ID}, "date": "2020-01-02T09:30"},
+					{"op": "delete", "todo_ids": []int32{todo.ID}},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				jakarta, err := time.LoadLocation("Asia/Jakarta")
+				require.NoError(t, err)
+
+				arg := db.BatchTodoTxParams{
+					UserEmail: user.Email,
+					Operations: []db.BatchTodoOperation{
+						{Op: db.BatchOpComplete, TodoIDs: []int32{todo.ID, 1000}},
+						{Op: db.BatchOpMove, TodoIDs: []int32{todo.ID}, CategoryID: 7},
+						{Op: db.BatchOpSetPriority, TodoIDs: []int32{todo.ID}, IsPriority: true},
+						{Op: db.BatchOpReschedule, TodoIDs: []int32{todo.ID}, Date: time.Date(2020, 1, 2, 9, 30, 0, 0, jakarta)},
+						{Op: db.BatchOpDelete, TodoIDs: []int32{todo.ID}},
+					},
+				}
+
+				completed := todo
+				completed.Status = true
+
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					BatchTodoTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.BatchTodoTxResult{Results: []db.BatchTodoItemResult{
+						{Operation: 0, Op: db.BatchOpComplete, TodoID: todo.ID, Todo: &completed},
+						{Operation: 0, Op: db.BatchOpComplete, TodoID: 1000, Error: db.ErrTodoNotFound.Error()},
+						{Operation: 4, Op: db.BatchOpDelete, TodoID: todo.ID},
+					}}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.BatchTodoTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got.Results, 3)
+				require.True(t, got.Results[0].Todo.Status)
+				require.Equal(t, "todo-not-found", got.Results[1].Error)
+			},
+		},
+		{
+			name: "MissingCategoryID",
+			body: gin.H{
+				"operations": []gin.H{{"op": "move", "todo_ids": []int32{todo.ID}}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BatchTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "operations-0-missing-category-id")
+			},
+		},
+		{
+			name: "MissingIsPriority",
+			body: gin.H{
+				"operations": []gin.H{{"op": "set_priority", "todo_ids": []int32{todo.ID}}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BatchTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidDate",
+			body: gin.H{
+				"operations": []gin.H{{"op": "reschedule", "todo_ids": []int32{todo.ID}, "date": "tomorrow"}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					BatchTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidOp",
+			body: gin.H{
+				"operations": []gin.H{{"op": "archive", "todo_ids": []int32{todo.ID}}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BatchTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoOperations",
+			body: gin.H{
+				"operations": []gin.H{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BatchTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "TooManyItems",
+			body: gin.H{
+				"operations": []gin.H{{"op": "complete", "todo_ids": manyIDs}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BatchTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{
+				"operations": []gin.H{{"op": "reopen", "todo_ids": []int32{todo.ID}}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BatchTodoTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.BatchTodoTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/todo/batch", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maslow123/todoapp-services/calendar"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/token"
+	"github.com/maslow123/todoapp-services/util"
+)
+
+const (
+	maxCalendarUploadSize = 1 << 20
+	importedTodoColor     = "#888"
+	calendarContentType   = "text/calendar; charset=utf-8"
+)
+
+func (server *Server) createCalendarToken(ctx *gin.Context) {
+	calendarToken, err := newCalendarToken()
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	// a new token replaces the old one, so leaked feed URLs stop working
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.SetCalendarTokenParams{
+		Email:         authPayload.Username,
+		CalendarToken: sql.NullString{String: calendarToken, Valid: true},
+	}
+
+	_, err = server.store.SetCalendarToken(ctx, arg)
+	if err != nil {
+		log.Println(err)
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, http.StatusNotFound, errors.New("invalid-user"))
+			return
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, CalendarTokenResponse{
+		Token: calendarToken,
+		URL:   calendarFeedURL(ctx, calendarToken),
+	})
+}
+
+func (server *Server) deleteCalendarToken(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.SetCalendarTokenParams{
+		Email: authPayload.Username,
+	}
+
+	_, err := server.store.SetCalendarToken(ctx, arg)
+	if err != nil {
+		log.Println(err)
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, http.StatusNotFound, errors.New("invalid-user"))
+			return
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+// calendarFeed serves the user's open todos without an access token, since
+// calendar apps subscribe to a plain URL. The secret token authorizes it.
+func (server *Server) calendarFeed(ctx *gin.Context) {
+	var req CalendarFeedRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var query CalendarFeedQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	calendarToken := strings.TrimSuffix(req.Token, ".ics")
+	user, err := server.store.GetUserByCalendarToken(ctx, sql.NullString{String: calendarToken, Valid: true})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, http.StatusNotFound, errors.New("calendar-not-found"))
+			return
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	loc, err := util.LoadTimeZone(user.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	todos, err := server.store.ListOpenTodosForCalendar(ctx, user.Email)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	cal := calendar.Calendar{
+		Name:  fmt.Sprintf("%s's todos", user.Name),
+		Items: make([]calendar.Item, 0, len(todos)),
+	}
+	for _, todo := range todos {
+		cal.Items = append(cal.Items, newCalendarItem(todo, loc))
+	}
+
+	component := calendar.ComponentTodo
+	if query.Component == "vevent" {
+		component = calendar.ComponentEvent
+	}
+
+	ctx.Header("Content-Type", calendarContentType)
+	ctx.Header("Content-Disposition", `inline; filename="todos.ics"`)
+	ctx.Status(http.StatusOK)
+	if err := calendar.Encode(ctx.Writer, cal, component); err != nil {
+		log.Println(err)
+	}
+}
+
+func newCalendarItem(todo db.ListOpenTodosForCalendarRow, loc *time.Location) calendar.Item {
+	item := calendar.Item{
+		UID:          fmt.Sprintf("todo-%d@todoapp-services", todo.ID),
+		Summary:      todo.Title,
+		Description:  todo.Content,
+		Categories:   []string{todo.CategoryName},
+		Status:       calendar.StatusNeedsAction,
+		Due:          todo.Date.In(loc),
+		AllDay:       todo.AllDay,
+		Created:      todo.CreatedAt,
+		LastModified: todo.UpdatedAt,
+	}
+	if todo.IsPriority {
+		item.Priority = 1
+	}
+	if todo.Status {
+		item.Status = calendar.StatusCompleted
+	}
+	return item
+}
+
+func (server *Server) importCalendar(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxCalendarUploadSize)
+	formFile, _, err := ctx.Request.FormFile("file")
+	if err != nil {
+		abortWithError(ctx, http.StatusBadRequest, errors.New("invalid-file"))
+		return
+	}
+	defer formFile.Close()
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	loc, err := server.userLocation(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	items, err := calendar.Decode(formFile, loc)
+	if err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	resp := ImportCalendarResponse{
+		Created: []db.Todo{},
+		Errors:  []ImportRowError{},
+	}
+	for i, item := range items {
+		todo, err := server.importCalendarItem(ctx, authPayload.Username, item, loc)
+		if err != nil {
+			log.Println(err)
+			resp.Errors = append(resp.Errors, ImportRowError{Index: i, Error: err.Error()})
+			continue
+		}
+		resp.Created = append(resp.Created, todo)
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (server *Server) importCalendarItem(ctx *gin.Context, userEmail string, item calendar.Item, loc *time.Location) (db.Todo, error) {
+	if item.Summary == "" {
+		return db.Todo{}, errors.New("missing-summary")
+	}
+
+	category, err := server.importCategory(ctx, userEmail, item.Categories)
+	if err != nil {
+		return db.Todo{}, err
+	}
+
+	// todos without a due date are due today
+	due, allDay := item.Due, item.AllDay
+	if due.IsZero() {
+		due, allDay = util.StartOfDay(time.Now(), loc), true
+	}
+
+	arg := db.CreateTodoTxParams{
+		CreateTodoParams: db.CreateTodoParams{
+			UserEmail:  userEmail,
+			CategoryID: category.ID,
+			Title:      item.Summary,
+			Content:    item.Description,
+			Date:       due,
+			AllDay:     allDay,
+			Color:      importedTodoColor,
+			// RFC 5545 priorities 1-4 are the high ones
+			IsPriority: item.Priority >= 1 && item.Priority <= 4,
+		},
+	}
+
+	result, err := server.store.CreateTodoTx(ctx, arg)
+	if err != nil {
+		return db.Todo{}, err
+	}
+	server.publish(userEmail, db.EventTodoCreated, newTodoResponse(result))
+
+	todo := result.Todo
+	if item.Status == calendar.StatusCompleted {
+		todo, err = server.store.CompleteTodoTx(ctx, db.CompleteTodoTxParams{ID: todo.ID})
+		if err != nil {
+			return db.Todo{}, err
+		}
+		server.publish(userEmail, db.EventTodoCompleted, todo)
+	}
+
+	return todo, nil
+}
+
+// importCategory picks the first known category of an imported item, falling
+// back to the default category.
+func (server *Server) importCategory(ctx *gin.Context, userEmail string, names []string) (db.Category, error) {
+	for _, name := range names {
+		category, err := server.store.GetCategoryByName(ctx, name)
+		if err == nil {
+			return category, nil
+		}
+		if err != sql.ErrNoRows {
+			return db.Category{}, err
+		}
+	}
+
+	category, err := server.store.GetDefaultCategory(ctx)
+	if err == sql.ErrNoRows {
+		category, err = server.store.CreateCategoryTx(ctx, db.CreateCategoryTxParams{
+			Name:      db.DefaultCategoryName,
+			UserEmail: userEmail,
+			IsDefault: true,
+		})
+		if err == nil {
+			server.publish("", db.EventCategoryCreated, category)
+		}
+	}
+	return category, err
+}
+
+func calendarFeedURL(ctx *gin.Context, calendarToken string) string {
+	scheme := "http"
+	if ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/v1/calendar/%s.ics", scheme, ctx.Request.Host, calendarToken)
+}
+
+func newCalendarToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,409 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateCalendarTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var saved db.SetCalendarTokenParams
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		SetCalendarToken(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ interface{}, arg db.SetCalendarTokenParams) (db.User, error) {
+			saved = arg
+			return user, nil
+		})
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPost, "/users/me/calendar_token", nil)
+	require.NoError(t, err)
+	request.Host = "todo.example.com"
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got CalendarTokenResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Len(t, got.Token, 48)
+	require.Equal(t, user.Email, saved.Email)
+	require.Equal(t, sql.NullString{String: got.Token, Valid: true}, saved.CalendarToken)
+	require.Equal(t, "http://todo.example.com/v1/calendar/"+got.Token+".ics", got.URL)
+}
+
+func TestDeleteCalendarTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		SetCalendarToken(gomock.Any(), gomock.Eq(db.SetCalendarTokenParams{Email: user.Email})).
+		Times(1).
+		Return(user, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodDelete, "/users/me/calendar_token", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestCalendarFeedAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	user.TimeZone = "Asia/Jakarta"
+	calendarToken := util.RandomString(48)
+	user.CalendarToken = sql.NullString{String: calendarToken, Valid: true}
+
+	todos := []db.ListOpenTodosForCalendarRow{
+		{
+			ID:           1,
+			Title:        "Pay rent",
+			Content:      "before noon",
+			Date:         time.Date(2019, 12, 31, 17, 0, 0, 0, time.UTC),
+			AllDay:       true,
+			IsPriority:   true,
+			CategoryName: "Home",
+		},
+		{
+			ID:           2,
+			Title:        "Standup",
+			Date:         time.Date(2020, 1, 2, 2, 30, 0, 0, time.UTC),
+			CategoryName: "Work",
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		url           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "VTODO",
+			url:  "/calendar/" + calendarToken + ".ics",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByCalendarToken(gomock.Any(), gomock.Eq(user.CalendarToken)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ListOpenTodosForCalendar(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(todos, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Type"), "text/calendar")
+
+				body := recorder.Body.String()
+				require.Equal(t, 2, strings.Count(body, "BEGIN:VTODO\r\n"))
+				// all-day dates are written in the user's zone
+				require.Contains(t, body, "DUE;VALUE=DATE:20200101\r\n")
+				require.Contains(t, body, "DUE:20200102T023000Z\r\n")
+				require.Contains(t, body, "CATEGORIES:Home\r\n")
+				require.Contains(t, body, "PRIORITY:1\r\n")
+				require.Contains(t, body, "STATUS:NEEDS-ACTION\r\n")
+			},
+		},
+		{
+			name: "VEVENT",
+			url:  "/calendar/" + calendarToken + "?component=vevent",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByCalendarToken(gomock.Any(), gomock.Eq(user.CalendarToken)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ListOpenTodosForCalendar(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(todos, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, 2, strings.Count(recorder.Body.String(), "BEGIN:VEVENT\r\n"))
+				require.Contains(t, recorder.Body.String(), "DTSTART;VALUE=DATE:20200101\r\n")
+			},
+		},
+		{
+			name: "InvalidComponent",
+			url:  "/calendar/" + calendarToken + "?component=vjournal",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByCalendarToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			url:  "/calendar/" + calendarToken + ".ics",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByCalendarToken(gomock.Any(), gomock.Eq(user.CalendarToken)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().
+					ListOpenTodosForCalendar(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			// the feed is read by calendar apps, so no authorization header
+			request, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestImportCalendarAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	user.TimeZone = util.DefaultTimeZone
+	category := randomCategory()
+	defaultCategory := db.Category{ID: category.ID + 1, Name: db.DefaultCategoryName, IsDefault: true}
+
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VTODO",
+		"SUMMARY:Pay rent",
+		"DESCRIPTION:before noon",
+		"CATEGORIES:" + category.Name,
+		"PRIORITY:2",
+		"DUE;VALUE=DATE:20200101",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"SUMMARY:Old task",
+		"CATEGORIES:Unknown",
+		"DUE:20200102T093000Z",
+		"STATUS:COMPLETED",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"DESCRIPTION:no summary",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	testCases := []struct {
+		name          string
+		file          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			file: ics,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetCategoryByName(gomock.Any(), gomock.Eq(category.Name)).
+					Times(1).
+					Return(category, nil)
+				store.EXPECT().
+					GetCategoryByName(gomock.Any(), gomock.Eq("Unknown")).
+					Times(1).
+					Return(db.Category{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetDefaultCategory(gomock.Any()).
+					Times(1).
+					Return(defaultCategory, nil)
+
+				first := db.CreateTodoTxParams{
+					CreateTodoParams: db.CreateTodoParams{
+						UserEmail:  user.Email,
+						CategoryID: category.ID,
+						Title:      "Pay rent",
+						Content:    "before noon",
+						Date:       time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+						AllDay:     true,
+						Color:      importedTodoColor,
+						IsPriority: true,
+					},
+				}
+				second := db.CreateTodoTxParams{
+					CreateTodoParams: db.CreateTodoParams{
+						UserEmail:  user.Email,
+						CategoryID: defaultCategory.ID,
+						Title:      "Old task",
+						Date:       time.Date(2020, 1, 2, 9, 30, 0, 0, time.UTC),
+						Color:      importedTodoColor,
+					},
+				}
+
+				store.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Eq(first)).
+					Times(1).
+					Return(db.TodoTxResult{Todo: db.Todo{ID: 1, Title: "Pay rent"}}, nil)
+				store.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Eq(second)).
+					Times(1).
+					Return(db.TodoTxResult{Todo: db.Todo{ID: 2, Title: "Old task"}}, nil)
+				store.EXPECT().
+					CompleteTodoTx(gomock.Any(), gomock.Eq(db.CompleteTodoTxParams{ID: 2})).
+					Times(1).
+					Return(db.Todo{ID: 2, Title: "Old task", Status: true}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got ImportCalendarResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got.Created, 2)
+				require.True(t, got.Created[1].Status)
+				require.Equal(t, []ImportRowError{{Index: 2, Error: "missing-summary"}}, got.Errors)
+			},
+		},
+		{
+			name: "NoDefaultCategory",
+			file: strings.Join([]string{
+				"BEGIN:VCALENDAR",
+				"BEGIN:VTODO",
+				"SUMMARY:Pay rent",
+				"DUE;VALUE=DATE:20200101",
+				"END:VTODO",
+				"END:VCALENDAR",
+			}, "\r\n"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetDefaultCategory(gomock.Any()).
+					Times(1).
+					Return(db.Category{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateCategoryTx(gomock.Any(), gomock.Eq(db.CreateCategoryTxParams{
+						Name:      db.DefaultCategoryName,
+						UserEmail: user.Email,
+						IsDefault: true,
+					})).
+					Times(1).
+					Return(defaultCategory, nil)
+				store.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Do(func(_ interface{}, arg db.CreateTodoTxParams) {
+						require.Equal(t, defaultCategory.ID, arg.CategoryID)
+					}).
+					Return(db.TodoTxResult{Todo: db.Todo{ID: 1, Title: "Pay rent"}}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCalendar",
+			file: "not a calendar",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoFile",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body := new(bytes.Buffer)
+			mw := multipart.NewWriter(body)
+			if tc.file != "" {
+				w, err := mw.CreateFormFile("file", "todos.ics")
+				require.NoError(t, err)
+				_, err = w.Write([]byte(tc.file))
+				require.NoError(t, err)
+			}
+			require.NoError(t, mw.Close())
+
+			request, err := http.NewRequest(http.MethodPost, "/todo/import/ics", body)
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", mw.FormDataContentType())
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
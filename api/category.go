@@ -2,9 +2,12 @@ package api
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/maslow123/todoapp-services/apierror"
 	db "github.com/maslow123/todoapp-services/db/sqlc"
 	"github.com/maslow123/todoapp-services/token"
 )
@@ -12,23 +15,32 @@ import (
 func (server *Server) createCategory(ctx *gin.Context) {
 	var req CreateCategoryRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	category, err := server.store.CreateCategory(context.Background(), req.Name)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.CreateCategoryTxParams{
+		Name:      req.Name,
+		UserEmail: authPayload.Username,
+	}
+
+	category, err := server.store.CreateCategoryTx(context.Background(), arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
+	// categories are shared, so every user hears about them
+	server.publish("", db.EventCategoryCreated, category)
+
 	ctx.JSON(http.StatusOK, category)
 }
 
 func (server *Server) listCategories(ctx *gin.Context) {
 	var req ListCategoryRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
@@ -43,7 +55,7 @@ func (server *Server) listCategories(ctx *gin.Context) {
 
 	categories, err := server.store.ListCategories(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -53,7 +65,19 @@ func (server *Server) listCategories(ctx *gin.Context) {
 func (server *Server) updateCategory(ctx *gin.Context) {
 	var req UpdateCategoryRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	categoryID, err := resourceID(ctx, "category_id", req.CategoryID)
+	if err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	req.CategoryID = categoryID
+
+	version, ok := server.categoryIfMatch(ctx, req.CategoryID)
+	if !ok {
 		return
 	}
 
@@ -61,33 +85,136 @@ func (server *Server) updateCategory(ctx *gin.Context) {
 	if authPayload == nil {
 		return
 	}
-	arg := db.UpdateCategoryParams{
-		ID:   req.CategoryID,
-		Name: req.Name,
+	arg := db.UpdateCategoryTxParams{
+		UpdateCategoryParams: db.UpdateCategoryParams{
+			ID:   req.CategoryID,
+			Name: req.Name,
+		},
+		UserEmail: authPayload.Username,
+		Version:   version,
 	}
 
-	category, err := server.store.UpdateCategory(context.Background(), arg)
+	category, err := server.store.UpdateCategoryTx(context.Background(), arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		switch err {
+		case sql.ErrNoRows:
+			abortWithError(ctx, http.StatusNotFound, errors.New("category-not-found"))
+		case db.ErrVersionMismatch:
+			abortPreconditionFailed(ctx)
+		default:
+			abortWithError(ctx, http.StatusInternalServerError, err)
+		}
 		return
 	}
 
+	server.publish("", db.EventCategoryUpdated, category)
+	ctx.Header("ETag", versionETag(category.Version))
+
 	ctx.JSON(http.StatusOK, category)
 }
 
 func (server *Server) deleteCategory(ctx *gin.Context) {
 	var req DeleteCategoryRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	err := server.store.DeleteCategory(ctx, req.CategoryID)
-	if err != nil {
+	var query DeleteCategoryQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	version, ok := server.categoryIfMatch(ctx, req.CategoryID)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.DeleteCategoryTxParams{
+		CategoryID: req.CategoryID,
+		MoveTodos:  query.Todos == "move",
+		UserEmail:  authPayload.Username,
+		Version:    version,
+	}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	result, err := server.store.DeleteCategoryTx(ctx, arg)
+	if err != nil {
+		var notEmptyErr *db.CategoryNotEmptyError
+		switch {
+		case err == sql.ErrNoRows:
+			abortWithError(ctx, http.StatusNotFound, errors.New("category-not-found"))
+		case errors.As(err, &notEmptyErr):
+			apiErr := apierror.New(http.StatusConflict, "category-has-todos", "").
+				With("todo_count", notEmptyErr.TodoCount)
+			abortWithError(ctx, http.StatusConflict, apiErr)
+		case err == db.ErrDefaultCategory:
+			abortWithError(ctx, http.StatusConflict, err)
+		case err == db.ErrVersionMismatch:
+			abortPreconditionFailed(ctx)
+		default:
+			abortWithError(ctx, http.StatusInternalServerError, err)
+		}
 		return
 	}
 
+	server.publish("", db.EventCategoryDeleted, gin.H{
+		"id":          req.CategoryID,
+		"moved_todos": result.MovedTodos,
+	})
 	ctx.JSON(http.StatusOK, "OK")
 }
+
+func (server *Server) restoreCategory(ctx *gin.Context) {
+	var req RestoreCategoryRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	category, err := server.store.RestoreCategory(ctx, req.CategoryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, http.StatusNotFound, errors.New("category-not-found"))
+			return
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, category)
+}
+
+// categoryIfMatch gives the version a category write must still find. A
+// single tag is left to the transaction to compare; a list is resolved
+// against the current category first. It aborts the request when ok is false.
+func (server *Server) categoryIfMatch(ctx *gin.Context, categoryID int32) (version int32, ok bool) {
+	versions, ok := ifMatchVersions(ctx)
+	if !ok {
+		abortPreconditionFailed(ctx)
+		return 0, false
+	}
+	if len(versions) <= 1 {
+		if versions == nil {
+			return 0, true
+		}
+		return versions[0], true
+	}
+
+	category, err := server.store.GetCategory(ctx, categoryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, http.StatusNotFound, errors.New("category-not-found"))
+			return 0, false
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return 0, false
+	}
+
+	version, ok = versions.version(category.Version)
+	if !ok {
+		abortPreconditionFailed(ctx)
+	}
+	return version, ok
+}
@@ -41,7 +41,7 @@ func TestCreateCategoryAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateCategory(gomock.Any(), gomock.Eq(category.Name)).
+					CreateCategoryTx(gomock.Any(), gomock.Eq(db.CreateCategoryTxParams{Name: category.Name, UserEmail: user.Email})).
 					Times(1).
 					Return(category, nil)
 			},
@@ -60,7 +60,7 @@ func TestCreateCategoryAPI(t *testing.T) {
 			buildStubs: func(store *mockdb.MockStore) {
 
 				store.EXPECT().
-					CreateCategory(gomock.Any(), gomock.Any()).
+					CreateCategoryTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -76,7 +76,7 @@ func TestCreateCategoryAPI(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateCategory(gomock.Any(), gomock.Any()).
+				store.EXPECT().CreateCategoryTx(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.Category{}, sql.ErrConnDone)
 			},
@@ -296,7 +296,7 @@ func TestUpdateCategoryAPI(t *testing.T) {
 				}
 
 				store.EXPECT().
-					UpdateCategory(gomock.Any(), gomock.Eq(arg)).
+					UpdateCategoryTx(gomock.Any(), gomock.Eq(db.UpdateCategoryTxParams{UpdateCategoryParams: arg, UserEmail: user.Email})).
 					Times(1).
 					Return(resp, nil)
 			},
@@ -316,7 +316,7 @@ func TestUpdateCategoryAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateCategory(gomock.Any(), gomock.Any()).
+					UpdateCategoryTx(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.Category{}, sql.ErrConnDone)
 			},
@@ -334,7 +334,7 @@ func TestUpdateCategoryAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateCategory(gomock.Any(), gomock.Any()).
+					UpdateCategoryTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -352,13 +352,110 @@ func TestUpdateCategoryAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateCategory(gomock.Any(), gomock.Any()).
+					UpdateCategoryTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name: "StaleIfMatch",
+			body: gin.H{
+				"category_id": category1.ID,
+				"name":        fmt.Sprintf("update-%s", category1.Name),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+				request.Header.Set("If-Match", `"5"`)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateCategoryTxParams{
+					UpdateCategoryParams: db.UpdateCategoryParams{
+						ID:   category1.ID,
+						Name: fmt.Sprintf("update-%s", category1.Name),
+					},
+					UserEmail: user.Email,
+					Version:   5,
+				}
+
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.Category{}, db.ErrVersionMismatch)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name: "IfMatchList",
+			body: gin.H{
+				"category_id": category1.ID,
+				"name":        fmt.Sprintf("update-%s", category1.Name),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+				request.Header.Set("If-Match", `"5", "6"`)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetCategory(gomock.Any(), gomock.Eq(category1.ID)).
+					Times(1).
+					Return(db.Category{ID: category1.ID, Version: 6}, nil)
+
+				arg := db.UpdateCategoryTxParams{
+					UpdateCategoryParams: db.UpdateCategoryParams{
+						ID:   category1.ID,
+						Name: fmt.Sprintf("update-%s", category1.Name),
+					},
+					UserEmail: user.Email,
+					Version:   6,
+				}
+
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.Category{ID: category1.ID, Version: 7}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `"7"`, recorder.Header().Get("ETag"))
+			},
+		},
+		{
+			name: "WeakInIfMatchList",
+			body: gin.H{
+				"category_id": category1.ID,
+				"name":        fmt.Sprintf("update-%s", category1.Name),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+				request.Header.Set("If-Match", `"5", W/"6"`)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateCategoryTxParams{
+					UpdateCategoryParams: db.UpdateCategoryParams{
+						ID:   category1.ID,
+						Name: fmt.Sprintf("update-%s", category1.Name),
+					},
+					UserEmail: user.Email,
+					Version:   5,
+				}
+
+				store.EXPECT().
+					GetCategory(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.Category{}, db.ErrVersionMismatch)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -395,6 +492,7 @@ func TestDeleteCategoryAPI(t *testing.T) {
 	testCases := []struct {
 		name          string
 		categoryID    int32
+		query         string
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
@@ -406,15 +504,116 @@ func TestDeleteCategoryAPI(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.DeleteCategoryTxParams{
+					CategoryID: category1.ID,
+					MoveTodos:  false,
+					UserEmail:  user.Email,
+				}
+
+				store.EXPECT().
+					DeleteCategoryTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.DeleteCategoryTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:       "MoveTodos",
+			categoryID: category1.ID,
+			query:      "move",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.DeleteCategoryTxParams{
+					CategoryID: category1.ID,
+					MoveTodos:  true,
+					UserEmail:  user.Email,
+				}
+
 				store.EXPECT().
-					DeleteCategory(gomock.Any(), gomock.Eq(category1.ID)).
+					DeleteCategoryTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(nil)
+					Return(db.DeleteCategoryTxResult{MovedTodos: 3}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name:       "HasTodos",
+			categoryID: category1.ID,
+			query:      "restrict",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteCategoryTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DeleteCategoryTxResult{}, &db.CategoryNotEmptyError{TodoCount: 4})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+
+				var body struct {
+					TodoCount int64 `json:"todo_count"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &body)
+				require.NoError(t, err)
+				require.Equal(t, int64(4), body.TodoCount)
+			},
+		},
+		{
+			name:       "DefaultCategory",
+			categoryID: category1.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteCategoryTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DeleteCategoryTxResult{}, db.ErrDefaultCategory)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:       "NotFound",
+			categoryID: category1.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteCategoryTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DeleteCategoryTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:       "InvalidStrategy",
+			categoryID: category1.ID,
+			query:      "cascade",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteCategoryTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -431,6 +630,9 @@ func TestDeleteCategoryAPI(t *testing.T) {
 			recorder := httptest.NewRecorder()
 
 			url := fmt.Sprintf("/categories/%d", tc.categoryID)
+			if tc.query != "" {
+				url = fmt.Sprintf("%s?todos=%s", url, tc.query)
+			}
 			request, err := http.NewRequest(http.MethodDelete, url, nil)
 			require.NoError(t, err)
 
@@ -442,6 +644,105 @@ func TestDeleteCategoryAPI(t *testing.T) {
 	}
 }
 
+func TestRestoreCategoryAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	category := randomCategory()
+
+	testCases := []struct {
+		name          string
+		categoryID    int32
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK",
+			categoryID: category.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RestoreCategory(gomock.Any(), gomock.Eq(category.ID)).
+					Times(1).
+					Return(category, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchCategory(t, recorder.Body, category)
+			},
+		},
+		{
+			name:       "NoAuthorization",
+			categoryID: category.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RestoreCategory(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:       "NotFound",
+			categoryID: category.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RestoreCategory(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Category{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:       "InvalidID",
+			categoryID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RestoreCategory(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/categories/%d/restore", tc.categoryID)
+			request, err := http.NewRequest(http.MethodPut, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func randomCategory() db.Category {
 	return db.Category{
 		ID:   int32(util.RandomInt(1, 1000)),
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	originHeader               = "Origin"
+	requestMethodHeader        = "Access-Control-Request-Method"
+	allowOriginHeader          = "Access-Control-Allow-Origin"
+	allowCredentialsHeader     = "Access-Control-Allow-Credentials"
+	allowMethodsHeader         = "Access-Control-Allow-Methods"
+	allowHeadersHeader         = "Access-Control-Allow-Headers"
+	exposeHeadersHeader        = "Access-Control-Expose-Headers"
+	maxAgeHeader               = "Access-Control-Max-Age"
+	anyOrigin                  = "*"
+	corsAllowedRequestHeaders  = "Authorization, Content-Type, Accept, Cache-Control, X-Requested-With, X-CSRF-Token, Idempotency-Key, If-Match, If-None-Match, Last-Event-ID, X-Request-ID"
+	corsExposedResponseHeaders = "ETag, Content-Disposition, Retry-After, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset, Idempotent-Replayed, X-Request-ID"
+)
+
+// corsAllowedMethods has every method a route is registered with.
+var corsAllowedMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+var errOriginNotAllowed = errors.New("origin-not-allowed")
+
+// CORSMiddleware allows cross-origin requests from allowedOrigins. "*"
+// allows any origin, but without credentials, since browsers reject a
+// wildcard origin on credentialed requests. Preflight responses are cached by
+// the browser for maxAge.
+func CORSMiddleware(allowedOrigins []string, maxAge time.Duration) gin.HandlerFunc {
+	allowAny := false
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		origin = strings.TrimSpace(origin)
+		if origin == anyOrigin {
+			allowAny = true
+			continue
+		}
+		allowed[strings.ToLower(origin)] = true
+	}
+
+	allowMethods := strings.Join(corsAllowedMethods, ", ")
+	maxAgeSeconds := strconv.Itoa(int(maxAge.Seconds()))
+
+	return func(ctx *gin.Context) {
+		header := ctx.Writer.Header()
+		// the response depends on Origin, so caches must key on it
+		header.Add("Vary", originHeader)
+
+		origin := ctx.GetHeader(originHeader)
+		if origin == "" {
+			ctx.Next()
+			return
+		}
+
+		preflight := ctx.Request.Method == http.MethodOptions && ctx.GetHeader(requestMethodHeader) != ""
+
+		switch {
+		case allowed[strings.ToLower(origin)]:
+			header.Set(allowOriginHeader, origin)
+			header.Set(allowCredentialsHeader, "true")
+		case allowAny:
+			header.Set(allowOriginHeader, anyOrigin)
+		default:
+			if preflight {
+				abortWithError(ctx, http.StatusForbidden, errOriginNotAllowed)
+				return
+			}
+			ctx.Next()
+			return
+		}
+
+		if !preflight {
+			header.Set(exposeHeadersHeader, corsExposedResponseHeaders)
+			ctx.Next()
+			return
+		}
+
+		header.Add("Vary", requestMethodHeader)
+		header.Set(allowMethodsHeader, allowMethods)
+		header.Set(allowHeadersHeader, corsAllowedRequestHeaders)
+		if maxAge > 0 {
+			header.Set(maxAgeHeader, maxAgeSeconds)
+		}
+		ctx.AbortWithStatus(http.StatusNoContent)
+	}
+}
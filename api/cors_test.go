@@ -0,0 +1,138 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	testCases := []struct {
+		name           string
+		allowedOrigins []string
+		method         string
+		setupRequest   func(request *http.Request)
+		checkResponse  func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:           "NoOrigin",
+			allowedOrigins: []string{"https://app.example.com"},
+			method:         http.MethodGet,
+			setupRequest:   func(request *http.Request) {},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, recorder.Header().Get(allowOriginHeader))
+				require.Equal(t, originHeader, recorder.Header().Get("Vary"))
+			},
+		},
+		{
+			name:           "AllowedOrigin",
+			allowedOrigins: []string{"https://app.example.com"},
+			method:         http.MethodGet,
+			setupRequest: func(request *http.Request) {
+				request.Header.Set(originHeader, "https://app.example.com")
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "https://app.example.com", recorder.Header().Get(allowOriginHeader))
+				require.Equal(t, "true", recorder.Header().Get(allowCredentialsHeader))
+				require.Equal(t, originHeader, recorder.Header().Get("Vary"))
+				require.Contains(t, recorder.Header().Get(exposeHeadersHeader), "ETag")
+			},
+		},
+		{
+			name:           "AnyOrigin",
+			allowedOrigins: []string{"*"},
+			method:         http.MethodGet,
+			setupRequest: func(request *http.Request) {
+				request.Header.Set(originHeader, "https://other.example.com")
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "*", recorder.Header().Get(allowOriginHeader))
+				require.Empty(t, recorder.Header().Get(allowCredentialsHeader))
+			},
+		},
+		{
+			name:           "OriginNotAllowed",
+			allowedOrigins: []string{"https://app.example.com"},
+			method:         http.MethodGet,
+			setupRequest: func(request *http.Request) {
+				request.Header.Set(originHeader, "https://evil.example.com")
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, recorder.Header().Get(allowOriginHeader))
+				require.Empty(t, recorder.Header().Get(allowCredentialsHeader))
+			},
+		},
+		{
+			name:           "Preflight",
+			allowedOrigins: []string{"https://app.example.com"},
+			method:         http.MethodOptions,
+			setupRequest: func(request *http.Request) {
+				request.Header.Set(originHeader, "https://app.example.com")
+				request.Header.Set(requestMethodHeader, http.MethodDelete)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+				require.Equal(t, "https://app.example.com", recorder.Header().Get(allowOriginHeader))
+				require.Contains(t, recorder.Header().Get(allowMethodsHeader), http.MethodPatch)
+				require.Contains(t, recorder.Header().Get(allowMethodsHeader), http.MethodDelete)
+				require.Contains(t, recorder.Header().Get(allowHeadersHeader), "Authorization")
+				require.Equal(t, "600", recorder.Header().Get(maxAgeHeader))
+				require.Equal(t, []string{originHeader, requestMethodHeader}, recorder.Header().Values("Vary"))
+			},
+		},
+		{
+			name:           "PreflightOriginNotAllowed",
+			allowedOrigins: []string{"https://app.example.com"},
+			method:         http.MethodOptions,
+			setupRequest: func(request *http.Request) {
+				request.Header.Set(originHeader, "https://evil.example.com")
+				request.Header.Set(requestMethodHeader, http.MethodDelete)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Empty(t, recorder.Header().Get(allowOriginHeader))
+				require.Empty(t, recorder.Header().Get(allowMethodsHeader))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(CORSMiddleware(tc.allowedOrigins, 10*time.Minute))
+			router.GET("/cors", func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, gin.H{})
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(tc.method, "/cors", nil)
+			require.NoError(t, err)
+			tc.setupRequest(request)
+
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestCORSAllowsRegisteredMethods(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+	for _, route := range server.router.Routes() {
+		require.Contains(t, corsAllowedMethods, route.Method, route.Path)
+	}
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/maslow123/todoapp-services/apierror"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+)
+
+// dbErrors maps Postgres errors that a client can act on. Only the code is
+// sent; the driver message names tables and constraints and is only logged.
+var dbErrors = map[string]*apierror.Error{
+	db.UniqueViolation:      apierror.New(http.StatusConflict, apierror.CodeAlreadyExists, ""),
+	db.ForeignKeyViolation:  apierror.New(http.StatusUnprocessableEntity, apierror.CodeInvalidReference, ""),
+	db.NotNullViolation:     apierror.New(http.StatusUnprocessableEntity, apierror.CodeConstraintViolation, ""),
+	db.CheckViolation:       apierror.New(http.StatusUnprocessableEntity, apierror.CodeConstraintViolation, ""),
+	db.StringDataTruncation: apierror.New(http.StatusUnprocessableEntity, apierror.CodeValueTooLong, ""),
+	db.SerializationFailure: apierror.New(http.StatusServiceUnavailable, apierror.CodeRetryRequest, ""),
+	db.DeadlockDetected:     apierror.New(http.StatusServiceUnavailable, apierror.CodeRetryRequest, ""),
+}
+
+// dbError translates a Postgres error, or gives nil for any other error.
+func dbError(err error) *apierror.Error {
+	apiErr, ok := dbErrors[db.ErrorCode(err)]
+	if !ok {
+		return nil
+	}
+
+	copied := *apiErr
+	copied.Err = err
+	return &copied
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/maslow123/todoapp-services/apierror"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func requireProblemCode(t *testing.T, body io.Reader, code apierror.Code) apierror.Problem {
+	var problem apierror.Problem
+	err := json.NewDecoder(body).Decode(&problem)
+	require.NoError(t, err)
+	require.Equal(t, code, problem.Code)
+	return problem
+}
+
+func TestDBErrors(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		err           error
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "ForeignKeyViolation",
+			err:  &pq.Error{Code: db.ForeignKeyViolation, Message: `insert or update on table "tags" violates foreign key constraint "tags_user_email_fkey"`},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				problem := requireProblemCode(t, recorder.Body, apierror.CodeInvalidReference)
+				require.Empty(t, problem.Detail)
+			},
+		},
+		{
+			name: "CheckViolation",
+			err:  &pq.Error{Code: db.CheckViolation},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder.Body, apierror.CodeConstraintViolation)
+			},
+		},
+		{
+			name: "ValueTooLong",
+			err:  &pq.Error{Code: db.StringDataTruncation},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder.Body, apierror.CodeValueTooLong)
+			},
+		},
+		{
+			name: "SerializationFailure",
+			err:  &pq.Error{Code: db.SerializationFailure},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				require.Equal(t, "1", recorder.Header().Get(retryAfterHeader))
+				requireProblemCode(t, recorder.Body, apierror.CodeRetryRequest)
+			},
+		},
+		{
+			name: "Deadlock",
+			err:  &pq.Error{Code: db.DeadlockDetected},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				requireProblemCode(t, recorder.Body, apierror.CodeRetryRequest)
+			},
+		},
+		{
+			name: "OtherError",
+			err:  errors.New("pq: relation \"tags\" does not exist"),
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				problem := requireProblemCode(t, recorder.Body, apierror.CodeInternal)
+				require.Empty(t, problem.Detail)
+				require.NotContains(t, problem.Error, "relation")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				CreateTag(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.Tag{}, tc.err)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"name": "work"})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/tags", bytes.NewReader(data))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errPreconditionFailed = errors.New("precondition-failed")
+
+func versionETag(version int32) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// ifMatch is the list of versions an If-Match header accepts. nil accepts
+// any version.
+type ifMatch []int32
+
+// ifMatchVersions reads the If-Match header. A missing header or "*" gives
+// nil; ok is false when the header can't match any version.
+func ifMatchVersions(ctx *gin.Context) (versions ifMatch, ok bool) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" {
+		return nil, true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return nil, true
+		}
+		// If-Match uses strong comparison, so weak tags never match
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		v, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 32)
+		if err != nil || v < 1 {
+			continue
+		}
+		versions = append(versions, int32(v))
+	}
+	return versions, len(versions) > 0
+}
+
+// version gives the version a write must still find, given the current one:
+// 0 when any will do, or current when it is listed. ok is false when the
+// current version isn't listed.
+func (versions ifMatch) version(current int32) (version int32, ok bool) {
+	if versions == nil {
+		return 0, true
+	}
+	for _, v := range versions {
+		if v == current {
+			return current, true
+		}
+	}
+	return 0, false
+}
+
+// notModified reports whether the If-None-Match header matches etag, in
+// which case the response should be 304.
+func notModified(ctx *gin.Context, etag string) bool {
+	header := ctx.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func abortPreconditionFailed(ctx *gin.Context) {
+	abortWithError(ctx, http.StatusPreconditionFailed, errPreconditionFailed)
+}
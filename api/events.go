@@ -0,0 +1,85 @@
+package api
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/maslow123/todoapp-services/events"
+	"github.com/maslow123/todoapp-services/token"
+)
+
+const (
+	eventBufferSize        = 32
+	defaultEventsKeepAlive = 25 * time.Second
+)
+
+// streamEvents pushes the user's todo and category changes as Server-Sent
+// Events until the client disconnects or the server shuts down.
+func (server *Server) streamEvents(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	stream, unsubscribe := server.broker.Subscribe(authPayload.Username)
+	defer unsubscribe()
+
+	keepAlive := server.config.EventsKeepAlive
+	if keepAlive <= 0 {
+		keepAlive = defaultEventsKeepAlive
+	}
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	// stop reverse proxies such as nginx from buffering the stream
+	ctx.Header("X-Accel-Buffering", "no")
+
+	// the stream outlives the write timeout; clients reconnect on shutdown
+	if conn, ok := ctx.Request.Context().Value(connContextKey{}).(net.Conn); ok {
+		conn.SetWriteDeadline(time.Time{})
+	}
+
+	// send the headers now so clients see the stream open before any event
+	ctx.Writer.WriteHeaderNow()
+	ctx.Writer.Flush()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case <-server.closing:
+			return false
+		case event, ok := <-stream:
+			if !ok {
+				return false
+			}
+			ctx.Render(-1, sse.Event{
+				Id:    formatEventID(event.ID),
+				Event: event.Type,
+				Data:  event,
+			})
+			return true
+		case <-ticker.C:
+			ctx.Render(-1, sse.Event{Event: "ping", Data: time.Now().UTC()})
+			return true
+		}
+	})
+}
+
+// publish notifies the user's open event streams. An empty userEmail reaches
+// every user.
+func (server *Server) publish(userEmail, eventType string, data interface{}) {
+	server.broker.Publish(events.Event{
+		Type:      eventType,
+		UserEmail: userEmail,
+		Data:      data,
+	})
+}
+
+func formatEventID(id uint64) string {
+	return strconv.FormatUint(id, 10)
+}
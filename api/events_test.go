@@ -0,0 +1,107 @@
+package api
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/events"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamEventsAPI(t *testing.T) {
+	todo := randomTodo(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	hub := server.broker.(*events.Hub)
+
+	httpServer := httptest.NewServer(server.router)
+	defer httpServer.Close()
+
+	request, err := http.NewRequest(http.MethodGet, httpServer.URL+"/events", nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	require.Eventually(t, func() bool {
+		return hub.Subscribers(todo.UserEmail) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	server.publish("someone-else@email.com", db.EventTodoCreated, "not for us")
+	server.publish(todo.UserEmail, db.EventTodoCompleted, todo)
+
+	reader := bufio.NewReader(response.Body)
+	var lines []string
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	require.Equal(t, "id:2", lines[0])
+	require.Equal(t, "event:"+db.EventTodoCompleted, lines[1])
+	require.True(t, strings.HasPrefix(lines[2], "data:"))
+	require.Contains(t, lines[2], todo.Title)
+}
+
+func TestStreamEventsUnauthorized(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/events", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestCreateCategoryPublishesEvent(t *testing.T) {
+	user, _ := randomUser(t)
+	category := randomCategory()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateCategoryTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(category, nil)
+
+	server := newTestServer(t, store)
+	stream, unsubscribe := server.broker.Subscribe(user.Email)
+	defer unsubscribe()
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/categories", strings.NewReader(`{"name":"`+category.Name+`"}`))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	event := <-stream
+	require.Equal(t, db.EventCategoryCreated, event.Type)
+	require.Equal(t, category, event.Data)
+}
@@ -0,0 +1,364 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/token"
+	"github.com/maslow123/todoapp-services/util"
+)
+
+const (
+	exportVersion    = 1
+	exportFormatJSON = "json"
+	exportFormatCSV  = "csv"
+	maxImportSize    = 10 << 20
+	allDayDateLayout = "2006-01-02"
+	csvTagSeparator  = ";"
+)
+
+var exportCSVHeader = []string{"title", "content", "category", "date", "color", "is_priority", "status", "tags", "created_at"}
+
+func (server *Server) exportData(ctx *gin.Context) {
+	var req ExportRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	loc, err := util.LoadTimeZone(user.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	todos, err := server.store.ListTodosForExport(ctx, user.Email)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	archive := ExportArchive{
+		Version:    exportVersion,
+		ExportedAt: time.Now().UTC(),
+		User:       newUserResponse(user),
+		Categories: []ExportCategory{},
+		Todos:      make([]ExportTodo, 0, len(todos)),
+	}
+
+	// categories are shared, so only the ones the user's todos are in are exported
+	seen := make(map[string]bool)
+	for _, todo := range todos {
+		exportTodo, err := newExportTodo(todo, loc)
+		if err != nil {
+			abortWithError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		archive.Todos = append(archive.Todos, exportTodo)
+
+		if !seen[todo.CategoryName] {
+			seen[todo.CategoryName] = true
+			archive.Categories = append(archive.Categories, ExportCategory{Name: todo.CategoryName})
+		}
+	}
+
+	filename := fmt.Sprintf("todoapp-export-%s.%s", archive.ExportedAt.Format("20060102"), exportFormat(req.Format, ""))
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if exportFormat(req.Format, "") == exportFormatCSV {
+		ctx.Header("Content-Type", "text/csv; charset=utf-8")
+		ctx.Status(http.StatusOK)
+		if err := writeExportCSV(ctx.Writer, archive.Todos); err != nil {
+			log.Println(err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, archive)
+}
+
+func newExportTodo(todo db.ListTodosForExportRow, loc *time.Location) (ExportTodo, error) {
+	var tags []db.Tag
+	if err := json.Unmarshal(todo.Tags, &tags); err != nil {
+		return ExportTodo{}, err
+	}
+
+	exportTodo := ExportTodo{
+		Title:      todo.Title,
+		Content:    todo.Content,
+		Category:   todo.CategoryName,
+		Date:       todo.Date.UTC().Format(time.RFC3339),
+		Color:      todo.Color,
+		IsPriority: todo.IsPriority,
+		Status:     todo.Status,
+		Tags:       make([]string, 0, len(tags)),
+		CreatedAt:  todo.CreatedAt,
+	}
+	if todo.AllDay {
+		exportTodo.Date = todo.Date.In(loc).Format(allDayDateLayout)
+	}
+	for _, tag := range tags {
+		exportTodo.Tags = append(exportTodo.Tags, tag.Name)
+	}
+
+	return exportTodo, nil
+}
+
+func writeExportCSV(w io.Writer, todos []ExportTodo) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportCSVHeader); err != nil {
+		return err
+	}
+
+	for _, todo := range todos {
+		err := cw.Write([]string{
+			todo.Title,
+			todo.Content,
+			todo.Category,
+			todo.Date,
+			todo.Color,
+			strconv.FormatBool(todo.IsPriority),
+			strconv.FormatBool(todo.Status),
+			strings.Join(todo.Tags, csvTagSeparator),
+			todo.CreatedAt.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// importData reads an export and creates its categories and todos. Nothing is
+// written unless every row is valid; dry_run reports what would be created.
+func (server *Server) importData(ctx *gin.Context) {
+	var req ImportRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportSize)
+	formFile, header, err := ctx.Request.FormFile("file")
+	if err != nil {
+		abortWithError(ctx, http.StatusBadRequest, errors.New("invalid-file"))
+		return
+	}
+	defer formFile.Close()
+
+	var archive ExportArchive
+	var rowErrors []ImportRowError
+	switch exportFormat(req.Format, header.Filename) {
+	case exportFormatCSV:
+		archive.Todos, rowErrors, err = readImportCSV(formFile)
+	default:
+		err = json.NewDecoder(formFile).Decode(&archive)
+		if err == nil && archive.Version > exportVersion {
+			err = errors.New("unsupported-version")
+		}
+	}
+	if err != nil {
+		log.Println(err)
+		abortWithError(ctx, http.StatusBadRequest, errors.New("invalid-file"))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	loc, err := server.userLocation(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	arg := db.ImportTxParams{
+		UserEmail: authPayload.Username,
+		Todos:     make([]db.ImportTodo, 0, len(archive.Todos)),
+		DryRun:    req.DryRun,
+	}
+	for _, category := range archive.Categories {
+		if category.Name != "" {
+			arg.Categories = append(arg.Categories, category.Name)
+		}
+	}
+
+	resp := ImportResponse{
+		DryRun: req.DryRun,
+		Errors: append([]ImportRowError{}, rowErrors...),
+	}
+	for i, todo := range archive.Todos {
+		item, err := newImportTodo(todo, loc)
+		if err != nil {
+			resp.Errors = append(resp.Errors, ImportRowError{Index: i, Error: err.Error()})
+			continue
+		}
+		arg.Todos = append(arg.Todos, item)
+	}
+	server.runImport(ctx, arg, resp)
+}
+
+// runImport writes an import unless rows were rejected, and reports how many
+// categories, tags and todos it created.
+func (server *Server) runImport(ctx *gin.Context, arg db.ImportTxParams, resp ImportResponse) {
+	if len(resp.Errors) > 0 {
+		sort.SliceStable(resp.Errors, func(i, j int) bool {
+			return resp.Errors[i].Index < resp.Errors[j].Index
+		})
+		ctx.JSON(http.StatusUnprocessableEntity, resp)
+		return
+	}
+
+	result, err := server.store.ImportTx(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp.CreatedCategories = len(result.Categories)
+	resp.CreatedTags = len(result.Tags)
+	resp.CreatedTodos = len(result.Todos)
+
+	if !arg.DryRun {
+		for _, category := range result.Categories {
+			server.publish("", db.EventCategoryCreated, category)
+		}
+		for _, todo := range result.Todos {
+			server.publish(arg.UserEmail, db.EventTodoCreated, newTodoResponse(todo))
+		}
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func newImportTodo(todo ExportTodo, loc *time.Location) (db.ImportTodo, error) {
+	if strings.TrimSpace(todo.Title) == "" {
+		return db.ImportTodo{}, errors.New("missing-title")
+	}
+
+	date, allDay, err := util.ParseDueDate(todo.Date, loc)
+	if err != nil {
+		return db.ImportTodo{}, errors.New("invalid-date")
+	}
+
+	color := todo.Color
+	if color == "" {
+		color = importedTodoColor
+	}
+
+	tags := make([]string, 0, len(todo.Tags))
+	for _, tag := range todo.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return db.ImportTodo{
+		Title:        todo.Title,
+		Content:      todo.Content,
+		CategoryName: strings.TrimSpace(todo.Category),
+		Date:         date,
+		AllDay:       allDay,
+		Color:        color,
+		IsPriority:   todo.IsPriority,
+		Status:       todo.Status,
+		Tags:         tags,
+	}, nil
+}
+
+// readImportCSV reads todos by header name, so columns may be in any order
+// and unknown ones are ignored.
+func readImportCSV(r io.Reader) ([]ExportTodo, []ImportRowError, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, nil, errors.New("missing-title-column")
+	}
+
+	var todos []ExportTodo
+	var rowErrors []ImportRowError
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return todos, rowErrors, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		todo := ExportTodo{
+			Title:    field("title"),
+			Content:  field("content"),
+			Category: field("category"),
+			Date:     field("date"),
+			Color:    field("color"),
+			Tags:     strings.Split(field("tags"), csvTagSeparator),
+		}
+		todo.IsPriority, err = parseCSVBool(field("is_priority"))
+		if err != nil {
+			rowErrors = append(rowErrors, ImportRowError{Index: len(todos), Error: "invalid-is-priority"})
+		}
+		todo.Status, err = parseCSVBool(field("status"))
+		if err != nil {
+			rowErrors = append(rowErrors, ImportRowError{Index: len(todos), Error: "invalid-status"})
+		}
+
+		todos = append(todos, todo)
+	}
+}
+
+func parseCSVBool(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// exportFormat picks the format from the request, then the file name, and
+// defaults to JSON.
+func exportFormat(format, filename string) string {
+	if format != "" {
+		return format
+	}
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		return exportFormatCSV
+	}
+	return exportFormatJSON
+}
@@ -0,0 +1,373 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportDataAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	user.TimeZone = "Asia/Jakarta"
+
+	todos := []db.ListTodosForExportRow{
+		{
+			ID:           1,
+			UserEmail:    user.Email,
+			Title:        "Pay rent",
+			Content:      "before noon",
+			Date:         time.Date(2019, 12, 31, 17, 0, 0, 0, time.UTC),
+			AllDay:       true,
+			Color:        "#abc",
+			IsPriority:   true,
+			CategoryName: "Home",
+			Tags:         json.RawMessage(`[{"id": 1, "name": "money"}, {"id": 2, "name": "monthly"}]`),
+		},
+		{
+			ID:           2,
+			UserEmail:    user.Email,
+			Title:        "Standup, daily",
+			Date:         time.Date(2020, 1, 2, 2, 30, 0, 0, time.UTC),
+			Color:        "#def",
+			Status:       true,
+			CategoryName: "Home",
+			Tags:         json.RawMessage(`[]`),
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "JSON",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ListTodosForExport(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(todos, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Disposition"), ".json")
+				require.NotContains(t, recorder.Body.String(), user.HashedPassword)
+
+				var got ExportArchive
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, exportVersion, got.Version)
+				require.Equal(t, user.Email, got.User.Email)
+				require.Equal(t, []ExportCategory{{Name: "Home"}}, got.Categories)
+				require.Len(t, got.Todos, 2)
+				require.Equal(t, "2020-01-01", got.Todos[0].Date)
+				require.Equal(t, []string{"money", "monthly"}, got.Todos[0].Tags)
+				require.Equal(t, "2020-01-02T02:30:00Z", got.Todos[1].Date)
+				require.True(t, got.Todos[1].Status)
+			},
+		},
+		{
+			name:  "CSV",
+			query: "?format=csv",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ListTodosForExport(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(todos, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Type"), "text/csv")
+
+				records, err := csv.NewReader(recorder.Body).ReadAll()
+				require.NoError(t, err)
+				require.Len(t, records, 3)
+				require.Equal(t, exportCSVHeader, records[0])
+				require.Equal(t, []string{"Pay rent", "before noon", "Home", "2020-01-01", "#abc", "true", "false", "money;monthly"}, records[1][:8])
+				require.Equal(t, "Standup, daily", records[2][0])
+			},
+		},
+		{
+			name:  "InvalidFormat",
+			query: "?format=xml",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListTodosForExport(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/users/me/export"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestImportDataAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	user.TimeZone = util.DefaultTimeZone
+
+	archive, err := json.Marshal(ExportArchive{
+		Version:    exportVersion,
+		Categories: []ExportCategory{{Name: "Home"}, {Name: "Garden"}},
+		Todos: []ExportTodo{
+			{
+				Title:      "Pay rent",
+				Content:    "before noon",
+				Category:   "Home",
+				Date:       "2020-01-01",
+				Color:      "#abc",
+				IsPriority: true,
+				Tags:       []string{"money"},
+			},
+			{
+				Title:    "Standup",
+				Category: "Work",
+				Date:     "2020-01-02T02:30:00Z",
+				Status:   true,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	importArg := db.ImportTxParams{
+		UserEmail:  user.Email,
+		Categories: []string{"Home", "Garden"},
+		Todos: []db.ImportTodo{
+			{
+				Title:        "Pay rent",
+				Content:      "before noon",
+				CategoryName: "Home",
+				Date:         time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				AllDay:       true,
+				Color:        "#abc",
+				IsPriority:   true,
+				Tags:         []string{"money"},
+			},
+			{
+				Title:        "Standup",
+				CategoryName: "Work",
+				Date:         time.Date(2020, 1, 2, 2, 30, 0, 0, time.UTC),
+				Color:        importedTodoColor,
+				Status:       true,
+				Tags:         []string{},
+			},
+		},
+	}
+	importResult := db.ImportTxResult{
+		Categories: []db.Category{{ID: 1, Name: "Garden"}, {ID: 2, Name: "Work"}},
+		Tags:       []db.Tag{{ID: 1, Name: "money"}},
+		Todos:      []db.TodoTxResult{{Todo: db.Todo{ID: 1}}, {Todo: db.Todo{ID: 2}}},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		filename      string
+		file          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "JSON",
+			filename: "export.json",
+			file:     string(archive),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ImportTx(gomock.Any(), gomock.Eq(importArg)).
+					Times(1).
+					Return(importResult, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got ImportResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, ImportResponse{
+					CreatedCategories: 2,
+					CreatedTags:       1,
+					CreatedTodos:      2,
+					Errors:            []ImportRowError{},
+				}, got)
+			},
+		},
+		{
+			name:     "CSVDryRun",
+			query:    "?dry_run=true",
+			filename: "export.csv",
+			file: strings.Join([]string{
+				"category,title,date,is_priority,tags,unknown",
+				"Home,Pay rent,2020-01-01,true,money,x",
+			}, "\n"),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ImportTxParams{
+					UserEmail: user.Email,
+					Todos: []db.ImportTodo{{
+						Title:        "Pay rent",
+						CategoryName: "Home",
+						Date:         time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+						AllDay:       true,
+						Color:        importedTodoColor,
+						IsPriority:   true,
+						Tags:         []string{"money"},
+					}},
+					DryRun: true,
+				}
+
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ImportTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ImportTxResult{Todos: []db.TodoTxResult{{}}}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got ImportResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.True(t, got.DryRun)
+				require.Equal(t, 1, got.CreatedTodos)
+			},
+		},
+		{
+			name:     "RowErrors",
+			filename: "export.csv",
+			file: strings.Join([]string{
+				"title,date,status",
+				"Pay rent,2020-01-01,",
+				",2020-01-01,",
+				"Standup,tomorrow,",
+				"Review,2020-01-01,maybe",
+			}, "\n"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ImportTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				var got ImportResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, []ImportRowError{
+					{Index: 1, Error: "missing-title"},
+					{Index: 2, Error: "invalid-date"},
+					{Index: 3, Error: "invalid-status"},
+				}, got.Errors)
+			},
+		},
+		{
+			name:     "UnsupportedVersion",
+			filename: "export.json",
+			file:     `{"version": 99, "todos": []}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ImportTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoFile",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ImportTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body := new(bytes.Buffer)
+			mw := multipart.NewWriter(body)
+			if tc.filename != "" {
+				w, err := mw.CreateFormFile("file", tc.filename)
+				require.NoError(t, err)
+				_, err = w.Write([]byte(tc.file))
+				require.NoError(t, err)
+			}
+			require.NoError(t, mw.Close())
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/import"+tc.query, body)
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", mw.FormDataContentType())
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/maslow123/todoapp-services/apierror"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/token"
+)
+
+// graphqlContext is what resolvers get from the request.
+type graphqlContext struct {
+	payload    *token.Payload
+	categories *categoryLoader
+}
+
+type graphqlContextKey struct{}
+
+func graphqlRequestContext(ctx context.Context) *graphqlContext {
+	return ctx.Value(graphqlContextKey{}).(*graphqlContext)
+}
+
+func (server *Server) graphql(ctx *gin.Context) {
+	var req GraphQLRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+
+	validation := graphql.ValidateDocument(&server.graphqlSchema, doc, nil)
+	if !validation.IsValid {
+		ctx.JSON(http.StatusBadRequest, graphql.Result{Errors: validation.Errors})
+		return
+	}
+
+	usage, introspection := measureGraphQL(doc, req.OperationName, req.Variables)
+	limitErr := checkGraphQLUsage(usage, server.config.GraphQLMaxDepth, server.config.GraphQLMaxComplexity)
+	if limitErr == nil {
+		limitErr = checkGraphQLUsage(introspection, graphqlIntrospectionMaxDepth, graphqlIntrospectionMaxComplexity)
+	}
+	if limitErr != nil {
+		ctx.JSON(http.StatusBadRequest, graphql.Result{Errors: []gqlerrors.FormattedError{formatGraphQLError(limitErr)}})
+		return
+	}
+
+	resolverCtx := context.WithValue(ctx.Request.Context(), graphqlContextKey{}, &graphqlContext{
+		payload:    ctx.MustGet(authorizationPayloadKey).(*token.Payload),
+		categories: newCategoryLoader(server.store),
+	})
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        server.graphqlSchema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       resolverCtx,
+	})
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// graphqlError is a resolver error. Its message is the same code the REST
+// API would answer with; the status and field errors go in the extensions.
+type graphqlError struct {
+	err *apierror.Error
+}
+
+func newGraphQLError(status int, err error) error {
+	apiErr := dbError(err)
+	if apiErr == nil {
+		apiErr = apierror.From(status, err)
+	}
+	if apiErr.Status >= http.StatusInternalServerError || apiErr.Detail == apierror.DetailUnknown {
+		log.Println("graphql", err)
+	}
+	return graphqlError{apiErr}
+}
+
+func (e graphqlError) Error() string {
+	return string(e.err.Code)
+}
+
+func (e graphqlError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{
+		"code":   e.err.Code,
+		"status": e.err.Status,
+	}
+	if len(e.err.Fields) > 0 {
+		extensions["errors"] = e.err.Fields
+	}
+	for key, value := range e.err.Extra {
+		extensions[key] = value
+	}
+	return extensions
+}
+
+func formatGraphQLError(err *apierror.Error) gqlerrors.FormattedError {
+	e := graphqlError{err}
+	return gqlerrors.FormattedError{Message: e.Error(), Extensions: e.Extensions()}
+}
+
+// graphqlArgs checks resolver arguments the way binding tags check REST
+// requests.
+type graphqlArgs struct {
+	args   map[string]interface{}
+	fields []apierror.FieldError
+}
+
+func (a *graphqlArgs) int32(name string, min, max int) int32 {
+	n, _ := a.args[name].(int)
+	switch {
+	case n < min:
+		a.fields = append(a.fields, apierror.FieldError{Field: name, Code: "min", Param: strconv.Itoa(min)})
+	case max > 0 && n > max:
+		a.fields = append(a.fields, apierror.FieldError{Field: name, Code: "max", Param: strconv.Itoa(max)})
+	}
+	return int32(n)
+}
+
+func (a *graphqlArgs) optionalInt32(name string, min int) int32 {
+	if _, ok := a.args[name]; !ok {
+		return 0
+	}
+	return a.int32(name, min, 0)
+}
+
+func (a *graphqlArgs) string(name string) string {
+	s, _ := a.args[name].(string)
+	if s == "" {
+		a.fields = append(a.fields, apierror.FieldError{Field: name, Code: "required"})
+	}
+	return s
+}
+
+func (a *graphqlArgs) ids(name string) []int32 {
+	values, _ := a.args[name].([]interface{})
+	if values == nil {
+		return nil
+	}
+
+	ids := make([]int32, len(values))
+	for i, value := range values {
+		n, _ := value.(int)
+		if n < 1 {
+			a.fields = append(a.fields, apierror.FieldError{Field: name, Code: "min", Param: "1"})
+			return nil
+		}
+		ids[i] = int32(n)
+	}
+	return ids
+}
+
+func (a *graphqlArgs) err() error {
+	if len(a.fields) == 0 {
+		return nil
+	}
+	return graphqlError{&apierror.Error{
+		Status: http.StatusBadRequest,
+		Code:   apierror.CodeValidationFailed,
+		Detail: "request-has-invalid-fields",
+		Fields: a.fields,
+	}}
+}
+
+type graphqlUser struct {
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Pic      string `json:"pic"`
+	Email    string `json:"email"`
+	TimeZone string `json:"timeZone"`
+}
+
+type graphqlCategory struct {
+	ID        int32     `json:"id"`
+	Name      string    `json:"name"`
+	Version   int32     `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// graphqlTodo is a todo from any of the todo queries. The list queries don't
+// return the version and GetTodo doesn't return the status, so those are
+// null when unknown.
+type graphqlTodo struct {
+	ID         int32     `json:"id"`
+	CategoryID int32     `json:"categoryId"`
+	Title      string    `json:"title"`
+	Content    string    `json:"content"`
+	Color      string    `json:"color"`
+	Date       time.Time `json:"date"`
+	AllDay     bool      `json:"allDay"`
+	IsPriority bool      `json:"isPriority"`
+	Status     *bool     `json:"status"`
+	Version    *int32    `json:"version"`
+	Tags       []db.Tag  `json:"tags"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+func newGraphQLUser(user db.User) graphqlUser {
+	return graphqlUser{
+		Name:     user.Name,
+		Address:  user.Address,
+		Pic:      user.Pic,
+		Email:    user.Email,
+		TimeZone: user.TimeZone,
+	}
+}
+
+func newGraphQLCategory(category db.Category) graphqlCategory {
+	return graphqlCategory{
+		ID:        category.ID,
+		Name:      category.Name,
+		Version:   category.Version,
+		CreatedAt: category.CreatedAt,
+		UpdatedAt: category.UpdatedAt,
+	}
+}
+
+func newGraphQLTodo(todo db.Todo, tags []db.Tag) graphqlTodo {
+	if tags == nil {
+		tags = []db.Tag{}
+	}
+	return graphqlTodo{
+		ID:         todo.ID,
+		CategoryID: todo.CategoryID,
+		Title:      todo.Title,
+		Content:    todo.Content,
+		Color:      todo.Color,
+		Date:       todo.Date,
+		AllDay:     todo.AllDay,
+		IsPriority: todo.IsPriority,
+		Status:     &todo.Status,
+		Version:    &todo.Version,
+		Tags:       tags,
+		CreatedAt:  todo.CreatedAt,
+		UpdatedAt:  todo.UpdatedAt,
+	}
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/maslow123/todoapp-services/apierror"
+)
+
+// graphqlDefaultPageSize is the pageSize of list fields that don't set one.
+const graphqlDefaultPageSize = 10
+
+// Introspection fields are measured apart from the rest against fixed caps,
+// which the standard introspection query of GraphiQL and other tools fits in
+// whatever limits are configured for data queries.
+const (
+	graphqlIntrospectionMaxDepth      = 15
+	graphqlIntrospectionMaxComplexity = 500
+)
+
+type graphqlUsage struct {
+	depth      int
+	complexity int
+}
+
+// graphqlCost measures the operation a request runs before it is executed.
+// Depth counts nested fields. Complexity counts every field once, with the
+// fields under a paginated list counted once per item of a full page.
+type graphqlCost struct {
+	fragments     map[string]*ast.FragmentDefinition
+	variables     map[string]interface{}
+	introspection *graphqlUsage
+}
+
+// measureGraphQL returns the usage of the data fields and, separately, of the
+// introspection fields of the operation.
+func measureGraphQL(doc *ast.Document, operationName string, variables map[string]interface{}) (usage, introspection graphqlUsage) {
+	cost := graphqlCost{
+		fragments:     make(map[string]*ast.FragmentDefinition),
+		variables:     variables,
+		introspection: &introspection,
+	}
+
+	var operation *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			cost.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operation = definition
+			}
+		}
+	}
+	if operation == nil {
+		return usage, introspection
+	}
+	usage.depth, usage.complexity = cost.selectionSet(operation.SelectionSet, 1, map[string]bool{})
+	return usage, introspection
+}
+
+// checkGraphQLUsage fails when usage is over a limit. A zero limit is off.
+func checkGraphQLUsage(usage graphqlUsage, maxDepth, maxComplexity int) *apierror.Error {
+	if maxDepth > 0 && usage.depth > maxDepth {
+		return apierror.New(http.StatusBadRequest, "query-too-deep", "").
+			With("depth", usage.depth).With("max_depth", maxDepth)
+	}
+	if maxComplexity > 0 && usage.complexity > maxComplexity {
+		return apierror.New(http.StatusBadRequest, "query-too-complex", "").
+			With("complexity", usage.complexity).With("max_complexity", maxComplexity)
+	}
+	return nil
+}
+
+func (cost graphqlCost) selectionSet(set *ast.SelectionSet, depth int, spreading map[string]bool) (maxDepth, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	for _, selection := range set.Selections {
+		var d, c int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				d, c = cost.field(selection, 1, spreading)
+				if d > cost.introspection.depth {
+					cost.introspection.depth = d
+				}
+				cost.introspection.complexity += c
+				continue
+			}
+			d, c = cost.field(selection, depth, spreading)
+		case *ast.InlineFragment:
+			d, c = cost.selectionSet(selection.SelectionSet, depth, spreading)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := cost.fragments[name]
+			if !ok || spreading[name] {
+				continue
+			}
+			spreading[name] = true
+			d, c = cost.selectionSet(fragment.SelectionSet, depth, spreading)
+			delete(spreading, name)
+		}
+
+		if d > maxDepth {
+			maxDepth = d
+		}
+		complexity += c
+	}
+	return maxDepth, complexity
+}
+
+func (cost graphqlCost) field(field *ast.Field, depth int, spreading map[string]bool) (maxDepth, complexity int) {
+	if field.SelectionSet == nil {
+		return depth, 1
+	}
+	childDepth, childComplexity := cost.selectionSet(field.SelectionSet, depth+1, spreading)
+	return childDepth, 1 + childComplexity*cost.pageSize(field)
+}
+
+// pageSize gives how many items a list field can return, or 1 for fields
+// that aren't paginated.
+func (cost graphqlCost) pageSize(field *ast.Field) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "pageSize" {
+			continue
+		}
+
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(value.Value); err == nil && n > 0 {
+				return n
+			}
+		case *ast.Variable:
+			switch n := cost.variables[value.Name.Value].(type) {
+			case float64:
+				if n > 0 {
+					return int(n)
+				}
+			case int:
+				if n > 0 {
+					return n
+				}
+			}
+		}
+		return graphqlDefaultPageSize
+	}
+
+	if graphqlPaginatedFields[field.Name.Value] {
+		return graphqlDefaultPageSize
+	}
+	return 1
+}
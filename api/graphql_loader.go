@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"sync"
+
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+)
+
+// categoryLoader batches the category lookups of one GraphQL request. Each
+// todo's category resolver queues its id and returns a thunk; the executor
+// runs the thunks after the whole list is resolved, and the first one loads
+// every queued category with a single query.
+type categoryLoader struct {
+	store db.Store
+
+	mu      sync.Mutex
+	pending []int32
+	loaded  map[int32]*db.Category
+	err     error
+}
+
+func newCategoryLoader(store db.Store) *categoryLoader {
+	return &categoryLoader{
+		store:  store,
+		loaded: make(map[int32]*db.Category),
+	}
+}
+
+func (loader *categoryLoader) load(ctx context.Context, id int32) func() (interface{}, error) {
+	loader.mu.Lock()
+	if _, ok := loader.loaded[id]; !ok {
+		loader.pending = append(loader.pending, id)
+	}
+	loader.mu.Unlock()
+
+	return func() (interface{}, error) {
+		loader.mu.Lock()
+		defer loader.mu.Unlock()
+
+		if err := loader.dispatch(ctx); err != nil {
+			return nil, err
+		}
+		category := loader.loaded[id]
+		if category == nil {
+			// the field is nullable; a missing category isn't an error
+			return nil, nil
+		}
+		return newGraphQLCategory(*category), nil
+	}
+}
+
+// dispatch loads the pending ids. The caller holds mu.
+func (loader *categoryLoader) dispatch(ctx context.Context) error {
+	if loader.err != nil || len(loader.pending) == 0 {
+		return loader.err
+	}
+
+	ids := make([]int32, 0, len(loader.pending))
+	for _, id := range loader.pending {
+		if _, ok := loader.loaded[id]; ok {
+			continue
+		}
+		loader.loaded[id] = nil
+		ids = append(ids, id)
+	}
+	loader.pending = nil
+	if len(ids) == 0 {
+		return nil
+	}
+
+	categories, err := loader.store.ListCategoriesByIDs(ctx, ids)
+	if err != nil {
+		loader.err = err
+		return err
+	}
+	for i := range categories {
+		loader.loaded[categories[i].ID] = &categories[i]
+	}
+	return nil
+}
@@ -0,0 +1,614 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/util"
+)
+
+// graphqlPaginatedFields are the list fields that take page and pageSize.
+var graphqlPaginatedFields = map[string]bool{
+	"categories": true,
+	"todos":      true,
+}
+
+var errGraphQLNotFound = errors.New("not-found")
+
+func newGraphQLSchema(server *Server) (graphql.Schema, error) {
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"name":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"address":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"pic":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"email":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"timeZone": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	categoryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Category",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"name":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"version":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"updatedAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+
+	tagType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Tag",
+		Fields: graphql.Fields{
+			"id":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	todoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Todo",
+		Fields: graphql.Fields{
+			"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"categoryId": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"category": &graphql.Field{
+				Type: categoryType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					todo := p.Source.(graphqlTodo)
+					return graphqlRequestContext(p.Context).categories.load(p.Context, todo.CategoryID), nil
+				},
+			},
+			"title":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"content":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"color":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"date":       &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"allDay":     &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"isPriority": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"status":     &graphql.Field{Type: graphql.Boolean},
+			"version":    &graphql.Field{Type: graphql.Int},
+			"tags":       &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(tagType)))},
+			"createdAt":  &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"updatedAt":  &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+
+	todoFilterType := graphql.NewEnum(graphql.EnumConfig{
+		Name: "TodoFilter",
+		Values: graphql.EnumValueConfigMap{
+			"TODAY":    &graphql.EnumValueConfig{Value: "today"},
+			"UPCOMING": &graphql.EnumValueConfig{Value: "upcoming"},
+			"DONE":     &graphql.EnumValueConfig{Value: "done"},
+		},
+	})
+
+	todoInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "TodoInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"categoryId": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+			"title":      &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"content":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"date":       &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"color":      &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"isPriority": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Boolean)},
+			"tagIds":     &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.Int))},
+		},
+	})
+
+	pageArgs := func(pageSize int) graphql.FieldConfigArgument {
+		return graphql.FieldConfigArgument{
+			"page":     &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+			"pageSize": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: pageSize},
+		}
+	}
+	idArgs := func(versioned bool) graphql.FieldConfigArgument {
+		args := graphql.FieldConfigArgument{
+			"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+		}
+		if versioned {
+			args["version"] = &graphql.ArgumentConfig{Type: graphql.Int}
+		}
+		return args
+	}
+
+	todosArgs := pageArgs(graphqlDefaultPageSize)
+	todosArgs["filter"] = &graphql.ArgumentConfig{Type: todoFilterType, DefaultValue: "today"}
+	todosArgs["tagId"] = &graphql.ArgumentConfig{Type: graphql.Int}
+
+	updateCategoryArgs := idArgs(true)
+	updateCategoryArgs["name"] = &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}
+
+	updateTodoArgs := idArgs(true)
+	updateTodoArgs["input"] = &graphql.ArgumentConfig{Type: graphql.NewNonNull(todoInputType)}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"me": &graphql.Field{
+				Type:    graphql.NewNonNull(userType),
+				Resolve: server.resolveMe,
+			},
+			"categories": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(categoryType))),
+				Args:    pageArgs(graphqlDefaultPageSize),
+				Resolve: server.resolveCategories,
+			},
+			"category": &graphql.Field{
+				Type: categoryType,
+				Args: idArgs(false),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					args := graphqlArgs{args: p.Args}
+					id := args.int32("id", 1, 0)
+					if err := args.err(); err != nil {
+						return nil, err
+					}
+					return graphqlRequestContext(p.Context).categories.load(p.Context, id), nil
+				},
+			},
+			"todos": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(todoType))),
+				Args:    todosArgs,
+				Resolve: server.resolveTodos,
+			},
+			"todo": &graphql.Field{
+				Type:    todoType,
+				Args:    idArgs(false),
+				Resolve: server.resolveTodo,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createCategory": &graphql.Field{
+				Type: graphql.NewNonNull(categoryType),
+				Args: graphql.FieldConfigArgument{
+					"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: server.resolveCreateCategory,
+			},
+			"updateCategory": &graphql.Field{
+				Type:    graphql.NewNonNull(categoryType),
+				Args:    updateCategoryArgs,
+				Resolve: server.resolveUpdateCategory,
+			},
+			"createTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(todoInputType)},
+				},
+				Resolve: server.resolveCreateTodo,
+			},
+			"updateTodo": &graphql.Field{
+				Type:    graphql.NewNonNull(todoType),
+				Args:    updateTodoArgs,
+				Resolve: server.resolveUpdateTodo,
+			},
+			"completeTodo": &graphql.Field{
+				Type:    graphql.NewNonNull(todoType),
+				Args:    idArgs(true),
+				Resolve: server.resolveCompleteTodo,
+			},
+			"deleteTodo": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    idArgs(true),
+				Resolve: server.resolveDeleteTodo,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+func (server *Server) resolveMe(p graphql.ResolveParams) (interface{}, error) {
+	payload := graphqlRequestContext(p.Context).payload
+	user, err := server.store.GetUser(p.Context, payload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newGraphQLError(http.StatusNotFound, errors.New("invalid-user"))
+		}
+		return nil, newGraphQLError(http.StatusInternalServerError, err)
+	}
+	return newGraphQLUser(user), nil
+}
+
+func (server *Server) resolveCategories(p graphql.ResolveParams) (interface{}, error) {
+	args := graphqlArgs{args: p.Args}
+	page := args.int32("page", 1, 0)
+	pageSize := args.int32("pageSize", 5, 10)
+	if err := args.err(); err != nil {
+		return nil, err
+	}
+
+	categories, err := server.store.ListCategories(p.Context, db.ListCategoriesParams{
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	})
+	if err != nil {
+		return nil, newGraphQLError(http.StatusInternalServerError, err)
+	}
+
+	resp := make([]graphqlCategory, len(categories))
+	for i, category := range categories {
+		resp[i] = newGraphQLCategory(category)
+	}
+	return resp, nil
+}
+
+func (server *Server) resolveTodos(p graphql.ResolveParams) (interface{}, error) {
+	args := graphqlArgs{args: p.Args}
+	page := args.int32("page", 1, 0)
+	pageSize := args.int32("pageSize", 5, 100)
+	tagID := args.optionalInt32("tagId", 1)
+	if err := args.err(); err != nil {
+		return nil, err
+	}
+
+	email := graphqlRequestContext(p.Context).payload.Username
+	offset := (page - 1) * pageSize
+
+	var rows []db.ListTodayTodoRow
+	var err error
+	switch p.Args["filter"] {
+	case "upcoming":
+		var upcoming []db.ListUpcomingTodoRow
+		upcoming, err = server.store.ListUpcomingTodo(p.Context, db.ListUpcomingTodoParams{
+			UserEmail: email,
+			Limit:     pageSize,
+			Offset:    offset,
+			TagID:     tagID,
+		})
+		for _, row := range upcoming {
+			rows = append(rows, db.ListTodayTodoRow(row))
+		}
+	case "done":
+		var done []db.ListDoneTodoRow
+		done, err = server.store.ListDoneTodo(p.Context, db.ListDoneTodoParams{
+			UserEmail: email,
+			Limit:     pageSize,
+			Offset:    offset,
+			TagID:     tagID,
+		})
+		for _, row := range done {
+			rows = append(rows, db.ListTodayTodoRow(row))
+		}
+	default:
+		rows, err = server.store.ListTodayTodo(p.Context, db.ListTodayTodoParams{
+			UserEmail: email,
+			Limit:     pageSize,
+			Offset:    offset,
+			TagID:     tagID,
+		})
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return nil, newGraphQLError(http.StatusInternalServerError, err)
+	}
+
+	resp := make([]graphqlTodo, len(rows))
+	for i, row := range rows {
+		tags, err := decodeGraphQLTags(row.Tags)
+		if err != nil {
+			return nil, newGraphQLError(http.StatusInternalServerError, err)
+		}
+		status := row.Status
+		resp[i] = graphqlTodo{
+			ID:         row.ID,
+			CategoryID: row.CategoryID,
+			Title:      row.Title,
+			Content:    row.Content,
+			Color:      row.Color,
+			Date:       row.Date,
+			AllDay:     row.AllDay,
+			IsPriority: row.IsPriority,
+			Status:     &status,
+			Tags:       tags,
+			CreatedAt:  row.CreatedAt,
+			UpdatedAt:  row.UpdatedAt,
+		}
+	}
+	return resp, nil
+}
+
+func (server *Server) resolveTodo(p graphql.ResolveParams) (interface{}, error) {
+	args := graphqlArgs{args: p.Args}
+	id := args.int32("id", 1, 0)
+	if err := args.err(); err != nil {
+		return nil, err
+	}
+
+	todo, err := server.ownTodo(p, id, 0)
+	if err != nil {
+		if err == errGraphQLNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	tags, err := decodeGraphQLTags(todo.Tags)
+	if err != nil {
+		return nil, newGraphQLError(http.StatusInternalServerError, err)
+	}
+	version := todo.Version
+	return graphqlTodo{
+		ID:         todo.ID,
+		CategoryID: todo.CategoryID,
+		Title:      todo.Title,
+		Content:    todo.Content,
+		Color:      todo.Color,
+		Date:       todo.Date,
+		AllDay:     todo.AllDay,
+		IsPriority: todo.IsPriority,
+		Version:    &version,
+		Tags:       tags,
+		CreatedAt:  todo.CreatedAt,
+		UpdatedAt:  todo.UpdatedAt,
+	}, nil
+}
+
+// ownTodo loads a todo of the current user. version is checked when it's not 0.
+// A todo that doesn't exist gives errGraphQLNotFound unwrapped, so queries can
+// answer null where mutations answer an error.
+func (server *Server) ownTodo(p graphql.ResolveParams, id, version int32) (db.GetTodoRow, error) {
+	todo, err := server.store.GetTodo(p.Context, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return todo, errGraphQLNotFound
+		}
+		return todo, newGraphQLError(http.StatusInternalServerError, err)
+	}
+
+	if todo.UserEmail != graphqlRequestContext(p.Context).payload.Username {
+		return todo, newGraphQLError(http.StatusUnauthorized, errors.New("wrong-user"))
+	}
+	if version != 0 && todo.Version != version {
+		return todo, newGraphQLError(http.StatusPreconditionFailed, errPreconditionFailed)
+	}
+	return todo, nil
+}
+
+func (server *Server) resolveCreateCategory(p graphql.ResolveParams) (interface{}, error) {
+	args := graphqlArgs{args: p.Args}
+	name := args.string("name")
+	if err := args.err(); err != nil {
+		return nil, err
+	}
+
+	category, err := server.store.CreateCategoryTx(p.Context, db.CreateCategoryTxParams{
+		Name:      name,
+		UserEmail: graphqlRequestContext(p.Context).payload.Username,
+	})
+	if err != nil {
+		return nil, newGraphQLError(http.StatusInternalServerError, err)
+	}
+
+	server.publish("", db.EventCategoryCreated, category)
+	return newGraphQLCategory(category), nil
+}
+
+func (server *Server) resolveUpdateCategory(p graphql.ResolveParams) (interface{}, error) {
+	args := graphqlArgs{args: p.Args}
+	id := args.int32("id", 1, 0)
+	name := args.string("name")
+	version := args.optionalInt32("version", 1)
+	if err := args.err(); err != nil {
+		return nil, err
+	}
+
+	category, err := server.store.UpdateCategoryTx(p.Context, db.UpdateCategoryTxParams{
+		UpdateCategoryParams: db.UpdateCategoryParams{
+			ID:   id,
+			Name: name,
+		},
+		UserEmail: graphqlRequestContext(p.Context).payload.Username,
+		Version:   version,
+	})
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, newGraphQLError(http.StatusNotFound, errors.New("category-not-found"))
+		case db.ErrVersionMismatch:
+			return nil, newGraphQLError(http.StatusPreconditionFailed, errPreconditionFailed)
+		}
+		return nil, newGraphQLError(http.StatusInternalServerError, err)
+	}
+
+	server.publish("", db.EventCategoryUpdated, category)
+	return newGraphQLCategory(category), nil
+}
+
+// todoInput is the TodoInput argument of the todo mutations, checked and with
+// the date read in the user's time zone.
+type todoInput struct {
+	db.UpdateTodoByUserParams
+	TagIDs []int32
+}
+
+func (server *Server) todoInput(p graphql.ResolveParams, args *graphqlArgs) (todoInput, error) {
+	input, _ := p.Args["input"].(map[string]interface{})
+	fields := graphqlArgs{args: input}
+
+	var in todoInput
+	in.CategoryID = fields.int32("categoryId", 1, 0)
+	in.Title = fields.string("title")
+	in.Content = fields.string("content")
+	rawDate := fields.string("date")
+	in.Color = fields.string("color")
+	in.IsPriority, _ = input["isPriority"].(bool)
+	in.TagIDs = fields.ids("tagIds")
+
+	args.fields = append(args.fields, fields.fields...)
+	if err := args.err(); err != nil {
+		return in, err
+	}
+
+	loc, err := server.userLocation(p.Context, graphqlRequestContext(p.Context).payload.Username)
+	if err != nil {
+		return in, newGraphQLError(http.StatusInternalServerError, err)
+	}
+	in.Date, in.AllDay, err = util.ParseDueDate(rawDate, loc)
+	if err != nil {
+		return in, newGraphQLError(http.StatusBadRequest, errors.New("invalid-date"))
+	}
+
+	_, err = server.store.GetCategory(p.Context, in.CategoryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return in, newGraphQLError(http.StatusNotFound, errors.New("invalid-category"))
+		}
+		return in, newGraphQLError(http.StatusInternalServerError, err)
+	}
+	return in, nil
+}
+
+func (server *Server) resolveCreateTodo(p graphql.ResolveParams) (interface{}, error) {
+	in, err := server.todoInput(p, &graphqlArgs{})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.CreateTodoTx(p.Context, db.CreateTodoTxParams{
+		CreateTodoParams: db.CreateTodoParams{
+			UserEmail:  graphqlRequestContext(p.Context).payload.Username,
+			CategoryID: in.CategoryID,
+			Title:      in.Title,
+			Content:    in.Content,
+			Date:       in.Date,
+			AllDay:     in.AllDay,
+			Color:      in.Color,
+			IsPriority: in.IsPriority,
+		},
+		TagIDs: in.TagIDs,
+	})
+	if err != nil {
+		if err == db.ErrInvalidTags {
+			return nil, newGraphQLError(http.StatusBadRequest, err)
+		}
+		return nil, newGraphQLError(http.StatusInternalServerError, err)
+	}
+
+	resp := newTodoResponse(result)
+	server.publish(resp.UserEmail, db.EventTodoCreated, resp)
+	return newGraphQLTodo(result.Todo, result.Tags), nil
+}
+
+func (server *Server) resolveUpdateTodo(p graphql.ResolveParams) (interface{}, error) {
+	args := graphqlArgs{args: p.Args}
+	id := args.int32("id", 1, 0)
+	version := args.optionalInt32("version", 1)
+	in, err := server.todoInput(p, &args)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := server.ownTodo(p, id, version); err != nil {
+		return nil, graphqlNotFound(err, "todo-not-found")
+	}
+
+	in.ID = id
+	result, err := server.store.UpdateTodoTx(p.Context, db.UpdateTodoTxParams{
+		UpdateTodoByUserParams: in.UpdateTodoByUserParams,
+		TagIDs:                 in.TagIDs,
+		Version:                version,
+	})
+	if err != nil {
+		switch err {
+		case db.ErrInvalidTags:
+			return nil, newGraphQLError(http.StatusBadRequest, err)
+		case db.ErrVersionMismatch:
+			return nil, newGraphQLError(http.StatusPreconditionFailed, errPreconditionFailed)
+		}
+		return nil, newGraphQLError(http.StatusInternalServerError, err)
+	}
+
+	resp := newTodoResponse(result)
+	server.publish(resp.UserEmail, db.EventTodoUpdated, resp)
+	return newGraphQLTodo(result.Todo, result.Tags), nil
+}
+
+func (server *Server) resolveCompleteTodo(p graphql.ResolveParams) (interface{}, error) {
+	args := graphqlArgs{args: p.Args}
+	id := args.int32("id", 1, 0)
+	version := args.optionalInt32("version", 1)
+	if err := args.err(); err != nil {
+		return nil, err
+	}
+
+	current, err := server.ownTodo(p, id, version)
+	if err != nil {
+		return nil, graphqlNotFound(err, "todo-not-found")
+	}
+
+	todo, err := server.store.CompleteTodoTx(p.Context, db.CompleteTodoTxParams{
+		ID:      id,
+		Version: version,
+	})
+	if err != nil {
+		if err == db.ErrVersionMismatch {
+			return nil, newGraphQLError(http.StatusPreconditionFailed, errPreconditionFailed)
+		}
+		return nil, newGraphQLError(http.StatusInternalServerError, err)
+	}
+
+	server.publish(todo.UserEmail, db.EventTodoCompleted, todo)
+
+	tags, err := decodeGraphQLTags(current.Tags)
+	if err != nil {
+		return nil, newGraphQLError(http.StatusInternalServerError, err)
+	}
+	return newGraphQLTodo(todo, tags), nil
+}
+
+func (server *Server) resolveDeleteTodo(p graphql.ResolveParams) (interface{}, error) {
+	args := graphqlArgs{args: p.Args}
+	id := args.int32("id", 1, 0)
+	version := args.optionalInt32("version", 1)
+	if err := args.err(); err != nil {
+		return nil, err
+	}
+
+	todo, err := server.ownTodo(p, id, version)
+	if err != nil {
+		return nil, graphqlNotFound(err, "not-found")
+	}
+
+	arg := db.DeleteTodoTxParams{
+		ID:        todo.ID,
+		UserEmail: todo.UserEmail,
+		Version:   version,
+	}
+	err = server.store.DeleteTodoTx(p.Context, arg)
+	if err != nil {
+		if err == db.ErrVersionMismatch {
+			return nil, newGraphQLError(http.StatusPreconditionFailed, errPreconditionFailed)
+		}
+		return nil, newGraphQLError(http.StatusInternalServerError, err)
+	}
+
+	server.publish(arg.UserEmail, db.EventTodoDeleted, arg)
+	return true, nil
+}
+
+// graphqlNotFound turns errGraphQLNotFound from ownTodo into the error the REST
+// endpoint answers with.
+func graphqlNotFound(err error, code string) error {
+	if err == errGraphQLNotFound {
+		return newGraphQLError(http.StatusNotFound, errors.New(code))
+	}
+	return err
+}
+
+func decodeGraphQLTags(raw json.RawMessage) ([]db.Tag, error) {
+	tags := []db.Tag{}
+	if len(raw) == 0 {
+		return tags, nil
+	}
+	err := json.Unmarshal(raw, &tags)
+	return tags, err
+}
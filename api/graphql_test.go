@@ -0,0 +1,403 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/graphql-go/graphql/testutil"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/token"
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/stretchr/testify/require"
+)
+
+type graphqlTestResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func decodeGraphQLResponse(t *testing.T, recorder *httptest.ResponseRecorder) graphqlTestResponse {
+	var resp graphqlTestResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestGraphQLAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	work := db.Category{ID: 1, Name: "work"}
+	home := db.Category{ID: 2, Name: "home"}
+
+	todayRow := func(id int32, category db.Category) db.ListTodayTodoRow {
+		return db.ListTodayTodoRow{
+			ID:           id,
+			CategoryID:   category.ID,
+			UserEmail:    user.Email,
+			Title:        util.RandomString(10),
+			Content:      util.RandomString(30),
+			Color:        util.RandomColor(),
+			CategoryName: category.Name,
+			Tags:         json.RawMessage(`[{"id":3,"name":"urgent"}]`),
+		}
+	}
+	rows := []db.ListTodayTodoRow{todayRow(1, work), todayRow(2, home), todayRow(3, work)}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "QueryBatchesCategories",
+			body: gin.H{
+				"query": `query Home($pageSize: Int) {
+					me { email }
+					todos(pageSize: $pageSize) { id title tags { name } category { id name } }
+				}`,
+				"variables": gin.H{"pageSize": 5},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ListTodayTodo(gomock.Any(), gomock.Eq(db.ListTodayTodoParams{UserEmail: user.Email, Limit: 5, Offset: 0})).
+					Times(1).
+					Return(rows, nil)
+				store.EXPECT().
+					ListCategoriesByIDs(gomock.Any(), gomock.Eq([]int32{work.ID, home.ID})).
+					Times(1).
+					Return([]db.Category{work, home}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				resp := decodeGraphQLResponse(t, recorder)
+				require.Empty(t, resp.Errors)
+
+				require.Equal(t, user.Email, resp.Data["me"].(map[string]interface{})["email"])
+				todos := resp.Data["todos"].([]interface{})
+				require.Len(t, todos, len(rows))
+				for i, item := range todos {
+					todo := item.(map[string]interface{})
+					require.Equal(t, rows[i].Title, todo["title"])
+					require.Equal(t, rows[i].CategoryName, todo["category"].(map[string]interface{})["name"])
+					require.Equal(t, "urgent", todo["tags"].([]interface{})[0].(map[string]interface{})["name"])
+				}
+			},
+		},
+		{
+			name: "CreateCategory",
+			body: gin.H{
+				"query": `mutation { createCategory(name: "work") { id name } }`,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateCategoryTx(gomock.Any(), gomock.Eq(db.CreateCategoryTxParams{Name: work.Name, UserEmail: user.Email})).
+					Times(1).
+					Return(work, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				resp := decodeGraphQLResponse(t, recorder)
+				require.Empty(t, resp.Errors)
+
+				category := resp.Data["createCategory"].(map[string]interface{})
+				require.Equal(t, float64(work.ID), category["id"])
+				require.Equal(t, work.Name, category["name"])
+			},
+		},
+		{
+			name: "CompleteTodoVersionMismatch",
+			body: gin.H{
+				"query": `mutation { completeTodo(id: 1, version: 2) { id } }`,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(int32(1))).
+					Times(1).
+					Return(db.GetTodoRow{ID: 1, UserEmail: user.Email, Version: 3}, nil)
+				store.EXPECT().
+					CompleteTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				resp := decodeGraphQLResponse(t, recorder)
+				require.Len(t, resp.Errors, 1)
+				require.Equal(t, "precondition-failed", resp.Errors[0].Message)
+				require.Equal(t, float64(http.StatusPreconditionFailed), resp.Errors[0].Extensions["status"])
+			},
+		},
+		{
+			name: "WrongUser",
+			body: gin.H{
+				"query": `{ todo(id: 1) { id } }`,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(int32(1))).
+					Times(1).
+					Return(db.GetTodoRow{ID: 1, UserEmail: util.RandomEmail()}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				resp := decodeGraphQLResponse(t, recorder)
+				require.Len(t, resp.Errors, 1)
+				require.Equal(t, "wrong-user", resp.Errors[0].Message)
+				require.Nil(t, resp.Data["todo"])
+			},
+		},
+		{
+			name: "TodoNotFound",
+			body: gin.H{
+				"query": `{ todo(id: 1) { id } }`,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(int32(1))).
+					Times(1).
+					Return(db.GetTodoRow{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				resp := decodeGraphQLResponse(t, recorder)
+				require.Empty(t, resp.Errors)
+				require.Contains(t, resp.Data, "todo")
+				require.Nil(t, resp.Data["todo"])
+			},
+		},
+		{
+			name: "InvalidPageSize",
+			body: gin.H{
+				"query": `{ categories(pageSize: 20) { id } }`,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListCategories(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				resp := decodeGraphQLResponse(t, recorder)
+				require.Len(t, resp.Errors, 1)
+				require.Equal(t, "validation-failed", resp.Errors[0].Message)
+
+				fields := resp.Errors[0].Extensions["errors"].([]interface{})
+				require.Len(t, fields, 1)
+				field := fields[0].(map[string]interface{})
+				require.Equal(t, "pageSize", field["field"])
+				require.Equal(t, "max", field["code"])
+			},
+		},
+		{
+			name: "InvalidQuery",
+			body: gin.H{
+				"query": `{ todos { unknown } }`,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListTodayTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				resp := decodeGraphQLResponse(t, recorder)
+				require.NotEmpty(t, resp.Errors)
+			},
+		},
+		{
+			name: "MissingQuery",
+			body: gin.H{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder.Body, "validation-failed")
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{
+				"query": `{ me { email } }`,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/v1/graphql", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGraphQLLimits(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name    string
+		query   string
+		message string
+	}{
+		{
+			name:    "TooDeep",
+			query:   `{ todos(pageSize: 5) { category { name } } }`,
+			message: "query-too-deep",
+		},
+		{
+			name:    "TooDeepThroughFragment",
+			query:   `{ todos(pageSize: 5) { ...fields } } fragment fields on Todo { tags { name } }`,
+			message: "query-too-deep",
+		},
+		{
+			name:    "TooComplex",
+			query:   `{ todos(pageSize: 100) { id title } }`,
+			message: "query-too-complex",
+		},
+		{
+			name:    "TooComplexByDefaultPageSize",
+			query:   `{ categories { id name } todos { id title content color date allDay } }`,
+			message: "query-too-complex",
+		},
+		{
+			name:    "IntrospectionTooDeep",
+			query:   `{ __schema { types { fields { type { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { name } } } } } } } } } } } } } } } } }`,
+			message: "query-too-deep",
+		},
+		{
+			name:    "IntrospectionTooComplex",
+			query:   `{ t0: __type(name: "Todo") { name kind description } t1: __type(name: "Todo") { name kind description } t2: __type(name: "Todo") { name kind description } t3: __type(name: "Todo") { name kind description } t4: __type(name: "Todo") { name kind description } t5: __type(name: "Todo") { name kind description } t6: __type(name: "Todo") { name kind description } t7: __type(name: "Todo") { name kind description } t8: __type(name: "Todo") { name kind description } t9: __type(name: "Todo") { name kind description } t10: __type(name: "Todo") { name kind description } t11: __type(name: "Todo") { name kind description } t12: __type(name: "Todo") { name kind description } t13: __type(name: "Todo") { name kind description } t14: __type(name: "Todo") { name kind description } t15: __type(name: "Todo") { name kind description } t16: __type(name: "Todo") { name kind description } t17: __type(name: "Todo") { name kind description } t18: __type(name: "Todo") { name kind description } t19: __type(name: "Todo") { name kind description } t20: __type(name: "Todo") { name kind description } t21: __type(name: "Todo") { name kind description } t22: __type(name: "Todo") { name kind description } t23: __type(name: "Todo") { name kind description } t24: __type(name: "Todo") { name kind description } t25: __type(name: "Todo") { name kind description } t26: __type(name: "Todo") { name kind description } t27: __type(name: "Todo") { name kind description } t28: __type(name: "Todo") { name kind description } t29: __type(name: "Todo") { name kind description } t30: __type(name: "Todo") { name kind description } t31: __type(name: "Todo") { name kind description } t32: __type(name: "Todo") { name kind description } t33: __type(name: "Todo") { name kind description } t34: __type(name: "Todo") { name kind description } t35: __type(name: "Todo") { name kind description } t36: __type(name: "Todo") { name kind description } t37: __type(name: "Todo") { name kind description } t38: __type(name: "Todo") { name kind description } t39: __type(name: "Todo") { name kind description } t40: __type(name: "Todo") { name kind description } t41: __type(name: "Todo") { name kind description } t42: __type(name: "Todo") { name kind description } t43: __type(name: "Todo") { name kind description } t44: __type(name: "Todo") { name kind description } t45: __type(name: "Todo") { name kind description } t46: __type(name: "Todo") { name kind description } t47: __type(name: "Todo") { name kind description } t48: __type(name: "Todo") { name kind description } t49: __type(name: "Todo") { name kind description } t50: __type(name: "Todo") { name kind description } t51: __type(name: "Todo") { name kind description } t52: __type(name: "Todo") { name kind description } t53: __type(name: "Todo") { name kind description } t54: __type(name: "Todo") { name kind description } t55: __type(name: "Todo") { name kind description } t56: __type(name: "Todo") { name kind description } t57: __type(name: "Todo") { name kind description } t58: __type(name: "Todo") { name kind description } t59: __type(name: "Todo") { name kind description } t60: __type(name: "Todo") { name kind description } t61: __type(name: "Todo") { name kind description } t62: __type(name: "Todo") { name kind description } t63: __type(name: "Todo") { name kind description } t64: __type(name: "Todo") { name kind description } t65: __type(name: "Todo") { name kind description } t66: __type(name: "Todo") { name kind description } t67: __type(name: "Todo") { name kind description } t68: __type(name: "Todo") { name kind description } t69: __type(name: "Todo") { name kind description } t70: __type(name: "Todo") { name kind description } t71: __type(name: "Todo") { name kind description } t72: __type(name: "Todo") { name kind description } t73: __type(name: "Todo") { name kind description } t74: __type(name: "Todo") { name kind description } t75: __type(name: "Todo") { name kind description } t76: __type(name: "Todo") { name kind description } t77: __type(name: "Todo") { name kind description } t78: __type(name: "Todo") { name kind description } t79: __type(name: "Todo") { name kind description } t80: __type(name: "Todo") { name kind description } t81: __type(name: "Todo") { name kind description } t82: __type(name: "Todo") { name kind description } t83: __type(name: "Todo") { name kind description } t84: __type(name: "Todo") { name kind description } t85: __type(name: "Todo") { name kind description } t86: __type(name: "Todo") { name kind description } t87: __type(name: "Todo") { name kind description } t88: __type(name: "Todo") { name kind description } t89: __type(name: "Todo") { name kind description } t90: __type(name: "Todo") { name kind description } t91: __type(name: "Todo") { name kind description } t92: __type(name: "Todo") { name kind description } t93: __type(name: "Todo") { name kind description } t94: __type(name: "Todo") { name kind description } t95: __type(name: "Todo") { name kind description } t96: __type(name: "Todo") { name kind description } t97: __type(name: "Todo") { name kind description } t98: __type(name: "Todo") { name kind description } t99: __type(name: "Todo") { name kind description } t100: __type(name: "Todo") { name kind description } t101: __type(name: "Todo") { name kind description } t102: __type(name: "Todo") { name kind description } t103: __type(name: "Todo") { name kind description } t104: __type(name: "Todo") { name kind description } t105: __type(name: "Todo") { name kind description } t106: __type(name: "Todo") { name kind description } t107: __type(name: "Todo") { name kind description } t108: __type(name: "Todo") { name kind description } t109: __type(name: "Todo") { name kind description } t110: __type(name: "Todo") { name kind description } t111: __type(name: "Todo") { name kind description } t112: __type(name: "Todo") { name kind description } t113: __type(name: "Todo") { name kind description } t114: __type(name: "Todo") { name kind description } t115: __type(name: "Todo") { name kind description } t116: __type(name: "Todo") { name kind description } t117: __type(name: "Todo") { name kind description } t118: __type(name: "Todo") { name kind description } t119: __type(name: "Todo") { name kind description } t120: __type(name: "Todo") { name kind description } t121: __type(name: "Todo") { name kind description } t122: __type(name: "Todo") { name kind description } t123: __type(name: "Todo") { name kind description } t124: __type(name: "Todo") { name kind description } t125: __type(name: "Todo") { name kind description } t126: __type(name: "Todo") { name kind description } t127: __type(name: "Todo") { name kind description } t128: __type(name: "Todo") { name kind description } t129: __type(name: "Todo") { name kind description } }`,
+			message: "query-too-complex",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ListTodayTodo(gomock.Any(), gomock.Any()).Times(0)
+			store.EXPECT().ListCategories(gomock.Any(), gomock.Any()).Times(0)
+
+			config := util.Config{
+				TokenSymmetricKey:    util.RandomString(32),
+				AccessTokenDuration:  time.Minute,
+				GraphQLMaxDepth:      2,
+				GraphQLMaxComplexity: 50,
+			}
+			server, err := NewServer(config, store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"query": tc.query})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/v1/graphql", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+			resp := decodeGraphQLResponse(t, recorder)
+			require.Len(t, resp.Errors, 1)
+			require.Equal(t, tc.message, resp.Errors[0].Message)
+		})
+	}
+}
+
+func TestGraphQLIntrospection(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// tight data limits don't get in the way of loading the schema
+	config := util.Config{
+		TokenSymmetricKey:    util.RandomString(32),
+		AccessTokenDuration:  time.Minute,
+		GraphQLMaxDepth:      2,
+		GraphQLMaxComplexity: 50,
+	}
+	server, err := NewServer(config, mockdb.NewMockStore(ctrl))
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{"query": testutil.IntrospectionQuery})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/v1/graphql", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	resp := decodeGraphQLResponse(t, recorder)
+	require.Empty(t, resp.Errors)
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maslow123/todoapp-services/apierror"
+)
+
+const (
+	defaultReadinessTimeout = 2 * time.Second
+
+	checkOK = "ok"
+)
+
+// healthz answers as long as the process serves requests; it checks nothing
+// else, so a database outage doesn't get the process restarted.
+func (server *Server) healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, HealthResponse{Status: checkOK})
+}
+
+// readyz tells whether the server should get traffic: the database answers
+// within the timeout, it is migrated to this build's schema and, if
+// configured, the upload backend answers. It fails once shutdown begins so
+// the load balancer stops routing here while requests drain.
+func (server *Server) readyz(ctx *gin.Context) {
+	timeout := server.config.ReadinessTimeout
+	if timeout <= 0 {
+		timeout = defaultReadinessTimeout
+	}
+	checkCtx, cancel := context.WithTimeout(ctx.Request.Context(), timeout)
+	defer cancel()
+
+	checks := map[string]string{}
+	select {
+	case <-server.draining:
+		checks["server"] = "shutting-down"
+	default:
+		checks["server"] = checkOK
+	}
+
+	if err := server.store.Ping(checkCtx); err != nil {
+		log.Println("readyz database", err)
+		checks["database"] = "unreachable"
+		checks["migrations"] = "unknown"
+	} else {
+		checks["database"] = checkOK
+		checks["migrations"] = server.checkMigrations(checkCtx)
+	}
+
+	if server.uploadCheck != nil {
+		checks["uploads"] = checkOK
+		if err := server.uploadCheck(checkCtx); err != nil {
+			log.Println("readyz uploads", err)
+			checks["uploads"] = "unreachable"
+		}
+	}
+
+	for _, result := range checks {
+		if result != checkOK {
+			err := apierror.New(http.StatusServiceUnavailable, "not-ready", "").With("checks", checks)
+			abortWithError(ctx, http.StatusServiceUnavailable, err)
+			return
+		}
+	}
+	ctx.JSON(http.StatusOK, ReadinessResponse{Status: checkOK, Checks: checks})
+}
+
+// checkMigrations accepts a schema newer than this build, which is what a
+// rolling deploy looks like once the new build has migrated.
+func (server *Server) checkMigrations(ctx context.Context) string {
+	version, dirty, err := server.store.MigrationVersion(ctx)
+	switch {
+	case err != nil:
+		log.Println("readyz migrations", err)
+		return "unknown"
+	case dirty:
+		return "dirty"
+	case version < server.migrationVersion:
+		return "pending"
+	}
+	return checkOK
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthzAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().Ping(gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp HealthResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.Equal(t, "ok", resp.Status)
+}
+
+func TestReadyzAPI(t *testing.T) {
+	errUnreachable := errors.New("dial tcp: connection refused")
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore, version int64)
+		setupServer   func(server *Server)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, version int64) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(version, false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireReadinessChecks(t, recorder.Body, map[string]string{
+					"server":     "ok",
+					"database":   "ok",
+					"migrations": "ok",
+				})
+			},
+		},
+		{
+			name: "NewerSchema",
+			buildStubs: func(store *mockdb.MockStore, version int64) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(version+1, false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "DatabaseUnreachable",
+			buildStubs: func(store *mockdb.MockStore, version int64) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(errUnreachable)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				requireProblemCode(t, bytes.NewReader(recorder.Body.Bytes()), "not-ready")
+				requireReadinessChecks(t, recorder.Body, map[string]string{
+					"server":     "ok",
+					"database":   "unreachable",
+					"migrations": "unknown",
+				})
+			},
+		},
+		{
+			name: "MigrationsPending",
+			buildStubs: func(store *mockdb.MockStore, version int64) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(version-1, false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				requireReadinessChecks(t, recorder.Body, map[string]string{
+					"server":     "ok",
+					"database":   "ok",
+					"migrations": "pending",
+				})
+			},
+		},
+		{
+			name: "MigrationDirty",
+			buildStubs: func(store *mockdb.MockStore, version int64) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(version, true, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				requireReadinessChecks(t, recorder.Body, map[string]string{
+					"server":     "ok",
+					"database":   "ok",
+					"migrations": "dirty",
+				})
+			},
+		},
+		{
+			name: "NotMigrated",
+			buildStubs: func(store *mockdb.MockStore, version int64) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(int64(0), false, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				requireReadinessChecks(t, recorder.Body, map[string]string{
+					"server":     "ok",
+					"database":   "ok",
+					"migrations": "unknown",
+				})
+			},
+		},
+		{
+			name: "UploadsUnreachable",
+			buildStubs: func(store *mockdb.MockStore, version int64) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(version, false, nil)
+			},
+			setupServer: func(server *Server) {
+				server.uploadCheck = func(ctx context.Context) error {
+					return errUnreachable
+				}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				requireReadinessChecks(t, recorder.Body, map[string]string{
+					"server":     "ok",
+					"database":   "ok",
+					"migrations": "ok",
+					"uploads":    "unreachable",
+				})
+			},
+		},
+		{
+			name: "ShuttingDown",
+			buildStubs: func(store *mockdb.MockStore, version int64) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(version, false, nil)
+			},
+			setupServer: func(server *Server) {
+				require.NoError(t, server.Shutdown(context.Background()))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				requireReadinessChecks(t, recorder.Body, map[string]string{
+					"server":     "shutting-down",
+					"database":   "ok",
+					"migrations": "ok",
+				})
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			tc.buildStubs(store, server.migrationVersion)
+			if tc.setupServer != nil {
+				tc.setupServer(server)
+			}
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func requireReadinessChecks(t *testing.T, body *bytes.Buffer, checks map[string]string) {
+	// the problem body has a numeric status, so only decode the checks
+	var resp struct {
+		Checks map[string]string `json:"checks"`
+	}
+	require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+	require.Equal(t, checks, resp.Checks)
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maslow123/todoapp-services/apierror"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/token"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	defaultIdempotencyKeyTTL  = 24 * time.Hour
+	idempotencyResponseFormat = "application/json; charset=utf-8"
+)
+
+var (
+	errInvalidIdempotencyKey = errors.New("invalid-idempotency-key")
+	errIdempotencyKeyReused  = errors.New("idempotency-key-reused")
+	errIdempotencyKeyInUse   = errors.New("idempotency-key-in-use")
+)
+
+// idempotencyMiddleware makes retries of a request with the same
+// Idempotency-Key header safe: the first response is stored for ttl and
+// replayed instead of running the handler again. It needs authMiddleware,
+// since keys are scoped to the user.
+func idempotencyMiddleware(store db.Store, ttl time.Duration) gin.HandlerFunc {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyKeyTTL
+	}
+
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(ctx, http.StatusBadRequest, errInvalidIdempotencyKey)
+			return
+		}
+
+		body, err := ioutil.ReadAll(ctx.Request.Body)
+		if err != nil {
+			abortWithError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		ctx.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		arg := db.CreateIdempotencyKeyParams{
+			UserEmail:   authPayload.Username,
+			Key:         key,
+			RequestHash: requestHash(ctx, body),
+			ExpiresAt:   time.Now().Add(ttl),
+		}
+
+		// only one request can claim a key; an expired key can be claimed again
+		_, err = store.CreateIdempotencyKey(ctx, arg)
+		if err == sql.ErrNoRows {
+			replayIdempotentResponse(ctx, store, arg)
+			return
+		}
+		if err != nil {
+			abortWithError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		// a panicking handler must not leave the key claimed until it expires
+		defer func() {
+			if r := recover(); r != nil {
+				if err := releaseIdempotencyKey(store, arg); err != nil {
+					log.Println("cannot-release-idempotency-key: ", err)
+				}
+				panic(r)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+		ctx.Next()
+
+		// the request may be gone by now, but the key must not stay claimed
+		if status := recorder.Status(); status >= http.StatusInternalServerError {
+			err = releaseIdempotencyKey(store, arg)
+		} else {
+			err = store.SaveIdempotencyResponse(context.Background(), db.SaveIdempotencyResponseParams{
+				UserEmail:      arg.UserEmail,
+				Key:            arg.Key,
+				ResponseStatus: int32(status),
+				ResponseBody:   recorder.body.Bytes(),
+			})
+		}
+		if err != nil {
+			log.Println("cannot-store-idempotent-response: ", err)
+		}
+	}
+}
+
+// releaseIdempotencyKey deletes a claim so the request can be retried.
+func releaseIdempotencyKey(store db.Store, arg db.CreateIdempotencyKeyParams) error {
+	return store.DeleteIdempotencyKey(context.Background(), db.DeleteIdempotencyKeyParams{
+		UserEmail: arg.UserEmail,
+		Key:       arg.Key,
+	})
+}
+
+func replayIdempotentResponse(ctx *gin.Context, store db.Store, arg db.CreateIdempotencyKeyParams) {
+	stored, err := store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		UserEmail: arg.UserEmail,
+		Key:       arg.Key,
+	})
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	switch {
+	case stored.RequestHash != arg.RequestHash:
+		abortWithError(ctx, http.StatusUnprocessableEntity, errIdempotencyKeyReused)
+	case stored.ResponseStatus == 0:
+		abortWithError(ctx, http.StatusConflict, errIdempotencyKeyInUse)
+	default:
+		contentType := idempotencyResponseFormat
+		if stored.ResponseStatus >= http.StatusBadRequest {
+			contentType = apierror.ContentType
+		}
+		ctx.Header(idempotentReplayedHeader, "true")
+		ctx.Data(int(stored.ResponseStatus), contentType, stored.ResponseBody)
+		ctx.Abort()
+	}
+}
+
+// requestHash identifies a request by its handler, path params and body, so
+// a key can't be replayed against a different request. The handler rather
+// than the path is hashed, so a deprecated route and its /v1 successor share
+// keys.
+func requestHash(ctx *gin.Context, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, ctx.Request.Method+" "+ctx.HandlerName()+"\n")
+	for _, param := range ctx.Params {
+		io.WriteString(hash, param.Value+"\n")
+	}
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body as it is written.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,303 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	user, _ := randomUser(t)
+	category := randomCategory()
+	body, err := json.Marshal(gin.H{"name": category.Name})
+	require.NoError(t, err)
+
+	hash := handlerRequestHash(t, http.MethodPost, "/categories", (&Server{}).createCategory, body)
+
+	storedKey := db.GetIdempotencyKeyParams{UserEmail: user.Email, Key: "retry-1"}
+
+	testCases := []struct {
+		name          string
+		path          string
+		key           string
+		body          []byte
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "NoKey",
+			key:  "",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateCategoryTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(category, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "FirstRequest",
+			key:  "retry-1",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Do(func(_ interface{}, arg db.CreateIdempotencyKeyParams) {
+						require.Equal(t, user.Email, arg.UserEmail)
+						require.Equal(t, "retry-1", arg.Key)
+						require.Equal(t, hash, arg.RequestHash)
+						require.WithinDuration(t, time.Now().Add(defaultIdempotencyKeyTTL), arg.ExpiresAt, time.Minute)
+					}).
+					Return(db.IdempotencyKey{}, nil)
+				store.EXPECT().
+					CreateCategoryTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(category, nil)
+				store.EXPECT().
+					SaveIdempotencyResponse(gomock.Any(), gomock.Any()).
+					Times(1).
+					Do(func(_ interface{}, arg db.SaveIdempotencyResponseParams) {
+						require.Equal(t, int32(http.StatusOK), arg.ResponseStatus)
+
+						var got db.Category
+						err := json.Unmarshal(arg.ResponseBody, &got)
+						require.NoError(t, err)
+						require.Equal(t, category.ID, got.ID)
+					}).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, recorder.Header().Get(idempotentReplayedHeader))
+			},
+		},
+		{
+			name: "Replay",
+			key:  "retry-1",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(storedKey)).
+					Times(1).
+					Return(db.IdempotencyKey{
+						RequestHash:    hash,
+						ResponseStatus: http.StatusOK,
+						ResponseBody:   []byte(`{"id":42}`),
+					}, nil)
+				store.EXPECT().
+					CreateCategoryTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(idempotentReplayedHeader))
+				require.JSONEq(t, `{"id":42}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "ReplayOnSuccessor",
+			path: "/v1/categories",
+			key:  "retry-1",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(storedKey)).
+					Times(1).
+					Return(db.IdempotencyKey{
+						RequestHash:    hash,
+						ResponseStatus: http.StatusOK,
+						ResponseBody:   []byte(`{"id":42}`),
+					}, nil)
+				store.EXPECT().
+					CreateCategoryTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(idempotentReplayedHeader))
+			},
+		},
+		{
+			name: "DifferentBody",
+			key:  "retry-1",
+			body: []byte(`{"name": "something else"}`),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(storedKey)).
+					Times(1).
+					Return(db.IdempotencyKey{RequestHash: hash, ResponseStatus: http.StatusOK}, nil)
+				store.EXPECT().
+					CreateCategoryTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "InProgress",
+			key:  "retry-1",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(storedKey)).
+					Times(1).
+					Return(db.IdempotencyKey{RequestHash: hash}, nil)
+				store.EXPECT().
+					CreateCategoryTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "HandlerError",
+			key:  "retry-1",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, nil)
+				store.EXPECT().
+					CreateCategoryTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Category{}, sql.ErrConnDone)
+				// released so the client can retry
+				store.EXPECT().
+					DeleteIdempotencyKey(gomock.Any(), gomock.Eq(db.DeleteIdempotencyKeyParams{UserEmail: user.Email, Key: "retry-1"})).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					SaveIdempotencyResponse(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "HandlerPanic",
+			key:  "retry-1",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, nil)
+				store.EXPECT().
+					CreateCategoryTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Do(func(_ interface{}, _ db.CreateCategoryTxParams) {
+						panic("boom")
+					})
+				store.EXPECT().
+					DeleteIdempotencyKey(gomock.Any(), gomock.Eq(db.DeleteIdempotencyKeyParams{UserEmail: user.Email, Key: "retry-1"})).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					SaveIdempotencyResponse(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "KeyTooLong",
+			key:  strings.Repeat("k", maxIdempotencyKeyLength+1),
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateCategoryTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			path := tc.path
+			if path == "" {
+				path = "/categories"
+			}
+			request, err := http.NewRequest(http.MethodPost, path, bytes.NewReader(tc.body))
+			require.NoError(t, err)
+			if tc.key != "" {
+				request.Header.Set(idempotencyKeyHeader, tc.key)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+// handlerRequestHash gives the requestHash of a request routed to handler.
+func handlerRequestHash(t *testing.T, method, path string, handler gin.HandlerFunc, body []byte) string {
+	var hash string
+	router := gin.New()
+	router.Handle(method, path, func(ctx *gin.Context) {
+		hash = requestHash(ctx, body)
+		ctx.Abort()
+	}, handler)
+
+	request, err := http.NewRequest(method, path, nil)
+	require.NoError(t, err)
+	router.ServeHTTP(httptest.NewRecorder(), request)
+	require.NotEmpty(t, hash)
+	return hash
+}
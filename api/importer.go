@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/importer"
+	"github.com/maslow123/todoapp-services/token"
+	"github.com/maslow123/todoapp-services/util"
+)
+
+// importFromSource imports the export file of another todo app, such as a
+// Todoist CSV or a Trello board.
+func (server *Server) importFromSource(ctx *gin.Context) {
+	var uri ImportSourceRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var query ImportSourceQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	source, err := importer.Lookup(uri.Source)
+	if err != nil {
+		abortWithError(ctx, http.StatusNotFound, err)
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportSize)
+	formFile, _, err := ctx.Request.FormFile("file")
+	if err != nil {
+		abortWithError(ctx, http.StatusBadRequest, errors.New("invalid-file"))
+		return
+	}
+	defer formFile.Close()
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	loc, err := server.userLocation(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	result, err := source.Import(formFile, loc)
+	if err != nil {
+		log.Println(err)
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	arg := db.ImportTxParams{
+		UserEmail: authPayload.Username,
+		Todos:     make([]db.ImportTodo, 0, len(result.Items)),
+		DryRun:    query.DryRun,
+	}
+	for _, item := range result.Items {
+		arg.Todos = append(arg.Todos, newImportedItem(item, loc))
+	}
+
+	resp := ImportResponse{
+		DryRun: query.DryRun,
+		Errors: make([]ImportRowError, 0, len(result.Errors)),
+	}
+	for _, rowErr := range result.Errors {
+		resp.Errors = append(resp.Errors, ImportRowError{Index: rowErr.Index, Error: rowErr.Err})
+	}
+
+	server.runImport(ctx, arg, resp)
+}
+
+func newImportedItem(item importer.Item, loc *time.Location) db.ImportTodo {
+	// todos without a due date are due today
+	due, allDay := item.Due, item.AllDay
+	if due.IsZero() {
+		due, allDay = util.StartOfDay(time.Now(), loc), true
+	}
+
+	tags := item.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return db.ImportTodo{
+		Title:        item.Title,
+		Content:      item.Content,
+		CategoryName: item.Category,
+		Date:         due,
+		AllDay:       allDay,
+		Color:        importedTodoColor,
+		IsPriority:   item.Priority,
+		Status:       item.Completed,
+		Tags:         tags,
+	}
+}
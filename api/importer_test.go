@@ -0,0 +1,163 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/importer"
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportFromSourceAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	user.TimeZone = util.DefaultTimeZone
+
+	board := `{
+		"lists": [{"id": "l1", "name": "Errands"}],
+		"cards": [{"name": "Pay rent", "desc": "before noon", "idList": "l1",
+		           "due": "2020-01-02T09:30:00.000Z", "labels": [{"name": "money"}]}]
+	}`
+
+	testCases := []struct {
+		name          string
+		url           string
+		file          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Trello",
+			url:  "/users/me/import/" + importer.SourceTrello,
+			file: board,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ImportTxParams{
+					UserEmail: user.Email,
+					Todos: []db.ImportTodo{{
+						Title:        "Pay rent",
+						Content:      "before noon",
+						CategoryName: "Errands",
+						Date:         time.Date(2020, 1, 2, 9, 30, 0, 0, time.UTC),
+						Color:        importedTodoColor,
+						Tags:         []string{"money"},
+					}},
+				}
+
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ImportTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ImportTxResult{
+						Categories: []db.Category{{ID: 1, Name: "Errands"}},
+						Tags:       []db.Tag{{ID: 1, Name: "money"}},
+						Todos:      []db.TodoTxResult{{Todo: db.Todo{ID: 1}}},
+					}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got ImportResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, 1, got.CreatedCategories)
+				require.Equal(t, 1, got.CreatedTodos)
+				require.Empty(t, got.Errors)
+			},
+		},
+		{
+			name: "RowErrors",
+			url:  "/users/me/import/" + importer.SourceTodoist + "?dry_run=true",
+			file: "TYPE,CONTENT,PRIORITY,DATE\ntask,Gym,4,every monday\n",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ImportTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				var got ImportResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.True(t, got.DryRun)
+				require.Equal(t, []ImportRowError{{Index: 0, Error: "unsupported-date"}}, got.Errors)
+			},
+		},
+		{
+			name: "InvalidFile",
+			url:  "/users/me/import/" + importer.SourceMicrosoftTodo,
+			file: "not json",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ImportTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownSource",
+			url:  "/users/me/import/wunderlist",
+			file: board,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ImportTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body := new(bytes.Buffer)
+			mw := multipart.NewWriter(body)
+			w, err := mw.CreateFormFile("file", "export")
+			require.NoError(t, err)
+			_, err = w.Write([]byte(tc.file))
+			require.NoError(t, err)
+			require.NoError(t, mw.Close())
+
+			request, err := http.NewRequest(http.MethodPost, tc.url, body)
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", mw.FormDataContentType())
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
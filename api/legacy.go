@@ -0,0 +1,107 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	deprecationHeader = "Deprecation"
+	sunsetHeader      = "Sunset"
+	linkHeader        = "Link"
+	legacySunsetDate  = "2006-01-02"
+)
+
+// legacyRoutes registers the routes from before /v1. Each alias runs the same
+// handlers as its successor and tells clients where to move.
+type legacyRoutes struct {
+	successors map[string]string
+	sunset     time.Time
+}
+
+func newLegacyRoutes(sunset time.Time) *legacyRoutes {
+	return &legacyRoutes{
+		successors: make(map[string]string),
+		sunset:     sunset,
+	}
+}
+
+// alias registers method and path on group as a deprecated alias of the /v1
+// route successor.
+func (legacy *legacyRoutes) alias(group gin.IRoutes, method, path, successor string, handlers ...gin.HandlerFunc) {
+	legacy.successors[method+" "+path] = successor
+	group.Handle(method, path, handlers...)
+}
+
+// middleware adds the deprecation headers. It goes on the group, ahead of
+// auth, so even rejected requests learn that the route is going away.
+func (legacy *legacyRoutes) middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		successor, ok := legacy.successors[ctx.Request.Method+" "+ctx.FullPath()]
+		if !ok {
+			ctx.Next()
+			return
+		}
+
+		ctx.Header(deprecationHeader, "true")
+		if !legacy.sunset.IsZero() {
+			ctx.Header(sunsetHeader, legacy.sunset.UTC().Format(http.TimeFormat))
+		}
+		if link, ok := successorPath(ctx, successor); ok {
+			ctx.Header(linkHeader, fmt.Sprintf(`<%s>; rel="successor-version"`, link))
+		}
+		ctx.Next()
+	}
+}
+
+// successorPath fills the successor's path params from the request. ok is
+// false when the old route didn't have them in its path.
+func successorPath(ctx *gin.Context, successor string) (string, bool) {
+	parts := strings.Split(successor, "/")
+	for i, part := range parts {
+		if !strings.HasPrefix(part, ":") {
+			continue
+		}
+		value := ctx.Param(part[1:])
+		if value == "" {
+			return "", false
+		}
+		parts[i] = value
+	}
+	return strings.Join(parts, "/"), true
+}
+
+func parseLegacySunset(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(legacySunsetDate, value)
+}
+
+// resourceID gives the id of the resource a request changes. /v1 routes have
+// it in the path; the deprecated routes that sent it in the body still can.
+func resourceID(ctx *gin.Context, param string, bodyID int32) (int32, error) {
+	name := strings.ReplaceAll(param, "_", "-")
+
+	value := ctx.Param(param)
+	if value == "" {
+		if bodyID == 0 {
+			return 0, fmt.Errorf("%s-is-required", name)
+		}
+		return bodyID, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid-%s", name)
+	}
+	if bodyID != 0 && bodyID != int32(id) {
+		return 0, fmt.Errorf("%s-mismatch", name)
+	}
+	return int32(id), nil
+}
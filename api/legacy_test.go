@@ -0,0 +1,367 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/token"
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteTable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+
+	probes := []string{
+		"GET /healthz",
+		"GET /readyz",
+	}
+
+	v1 := []string{
+		"POST /v1/users",
+		"POST /v1/users/login",
+		"GET /v1/calendar/:token",
+		"GET /v1/openapi.json",
+		"GET /v1/docs",
+		"GET /v1/docs/assets/:file",
+		"GET /v1/users/me",
+		"PUT /v1/users/me/time_zone",
+		"POST /v1/users/me/calendar_token",
+		"DELETE /v1/users/me/calendar_token",
+		"GET /v1/users/me/export",
+		"POST /v1/users/me/import",
+		"POST /v1/users/me/import/:source",
+		"POST /v1/users/me/photo",
+		"POST /v1/categories",
+		"GET /v1/categories",
+		"PATCH /v1/categories/:category_id",
+		"DELETE /v1/categories/:category_id",
+		"PUT /v1/categories/:category_id/restore",
+		"POST /v1/todos",
+		"GET /v1/todos",
+		"GET /v1/todos/:todo_id",
+		"DELETE /v1/todos/:todo_id",
+		"PUT /v1/todos/:todo_id",
+		"PATCH /v1/todos/:todo_id",
+		"POST /v1/todos/:todo_id/complete",
+		"PUT /v1/todos/:todo_id/restore",
+		"POST /v1/todos/import/ics",
+		"POST /v1/todos/batch",
+		"GET /v1/sync",
+		"POST /v1/sync",
+		"POST /v1/todos/:todo_id/reminders",
+		"GET /v1/todos/:todo_id/reminders",
+		"DELETE /v1/todos/:todo_id/reminders/:reminder_id",
+		"POST /v1/tags",
+		"GET /v1/tags",
+		"PATCH /v1/tags/:tag_id",
+		"DELETE /v1/tags/:tag_id",
+		"POST /v1/webhooks",
+		"GET /v1/webhooks",
+		"DELETE /v1/webhooks/:webhook_id",
+		"GET /v1/webhooks/:webhook_id/deliveries",
+		"GET /v1/events",
+		"GET /v1/trash",
+		"POST /v1/uploads",
+		"POST /v1/graphql",
+	}
+	legacy := []string{
+		"POST /users/register",
+		"POST /users/login",
+		"GET /calendar/:token",
+		"GET /openapi.json",
+		"GET /docs",
+		"GET /docs/assets/:file",
+		"GET /users/me",
+		"PUT /users/me/time_zone",
+		"POST /users/me/calendar_token",
+		"DELETE /users/me/calendar_token",
+		"GET /users/me/export",
+		"POST /users/me/import",
+		"POST /users/me/import/:source",
+		"POST /categories",
+		"GET /categories",
+		"PATCH /categories",
+		"DELETE /categories/:category_id",
+		"PUT /categories/:category_id/restore",
+		"POST /todo",
+		"GET /todo",
+		"GET /todo/:todo_id",
+		"DELETE /todo/:todo_id",
+		"PUT /todo",
+		"PUT /todo/:todo_id",
+		"PATCH /todo/:todo_id",
+		"PUT /todo/:todo_id/restore",
+		"POST /todo/import/ics",
+		"POST /todo/batch",
+		"GET /sync",
+		"POST /sync",
+		"POST /todo/:todo_id/reminders",
+		"GET /todo/:todo_id/reminders",
+		"DELETE /todo/:todo_id/reminders/:reminder_id",
+		"POST /tags",
+		"GET /tags",
+		"PATCH /tags/:tag_id",
+		"DELETE /tags/:tag_id",
+		"POST /webhooks",
+		"GET /webhooks",
+		"DELETE /webhooks/:webhook_id",
+		"GET /webhooks/:webhook_id/deliveries",
+		"GET /events",
+		"GET /trash",
+		"POST /file",
+		"POST /remote",
+	}
+
+	var routes []string
+	for _, route := range server.router.Routes() {
+		routes = append(routes, route.Method+" "+route.Path)
+	}
+	require.ElementsMatch(t, append(append(probes, v1...), legacy...), routes)
+}
+
+func TestLegacyRouteHeaders(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Alias",
+			method: http.MethodDelete,
+			url:    "/todo/7/reminders/3",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(deprecationHeader))
+				require.Equal(t, "Fri, 30 Apr 2027 00:00:00 GMT", recorder.Header().Get(sunsetHeader))
+				require.Equal(t, `</v1/todos/7/reminders/3>; rel="successor-version"`, recorder.Header().Get(linkHeader))
+			},
+		},
+		{
+			name:   "RenamedAlias",
+			method: http.MethodPut,
+			url:    "/todo/7",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, "true", recorder.Header().Get(deprecationHeader))
+				require.Equal(t, `</v1/todos/7/complete>; rel="successor-version"`, recorder.Header().Get(linkHeader))
+			},
+		},
+		{
+			name:   "IDInBody",
+			method: http.MethodPatch,
+			url:    "/categories",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(deprecationHeader))
+				require.NotEmpty(t, recorder.Header().Get(sunsetHeader))
+				require.Empty(t, recorder.Header().Get(linkHeader))
+			},
+		},
+		{
+			name:   "Docs",
+			method: http.MethodGet,
+			url:    "/docs",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(deprecationHeader))
+				require.Equal(t, `</v1/docs>; rel="successor-version"`, recorder.Header().Get(linkHeader))
+			},
+		},
+		{
+			name:   "OpenAPI",
+			method: http.MethodGet,
+			url:    "/openapi.json",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(deprecationHeader))
+				require.Equal(t, `</v1/openapi.json>; rel="successor-version"`, recorder.Header().Get(linkHeader))
+			},
+		},
+		{
+			name:   "V1",
+			method: http.MethodDelete,
+			url:    "/v1/todos/7/reminders/3",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Empty(t, recorder.Header().Get(deprecationHeader))
+				require.Empty(t, recorder.Header().Get(sunsetHeader))
+				require.Empty(t, recorder.Header().Get(linkHeader))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server, err := NewServer(util.Config{
+				TokenSymmetricKey:   util.RandomString(32),
+				AccessTokenDuration: time.Minute,
+				LegacyRoutesSunset:  "2027-04-30",
+			}, store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.url, bytes.NewReader([]byte(`{}`)))
+			require.NoError(t, err)
+			tc.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestInvalidLegacySunset(t *testing.T) {
+	_, err := NewServer(util.Config{
+		TokenSymmetricKey:  util.RandomString(32),
+		LegacyRoutesSunset: "next year",
+	}, nil)
+	require.EqualError(t, err, "invalid-legacy-routes-sunset")
+}
+
+func TestUpdateCategoryByPathAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	category := randomCategory()
+
+	testCases := []struct {
+		name          string
+		url           string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			url:  fmt.Sprintf("/v1/categories/%d", category.ID),
+			body: gin.H{"name": category.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateCategoryTxParams{
+					UpdateCategoryParams: db.UpdateCategoryParams{ID: category.ID, Name: category.Name},
+					UserEmail:            user.Email,
+				}
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(category, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "MatchingBodyID",
+			url:  fmt.Sprintf("/v1/categories/%d", category.ID),
+			body: gin.H{"category_id": category.ID, "name": category.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(category, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Mismatch",
+			url:  fmt.Sprintf("/v1/categories/%d", category.ID),
+			body: gin.H{"category_id": category.ID + 1, "name": category.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder.Body, "category-id-mismatch")
+			},
+		},
+		{
+			name: "InvalidID",
+			url:  "/v1/categories/abc",
+			body: gin.H{"name": category.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder.Body, "invalid-category-id")
+			},
+		},
+		{
+			name: "LegacyMissingID",
+			url:  "/categories",
+			body: gin.H{"name": category.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder.Body, "category-id-is-required")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPatch, tc.url, bytes.NewReader(data))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
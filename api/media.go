@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -13,13 +14,13 @@ import (
 func (server *Server) UpdateUserPhoto(ctx *gin.Context) {
 	formFile, _, err := ctx.Request.FormFile("file")
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, http.StatusBadRequest, errors.New("invalid-file"))
 		return
 	}
 
 	uploadUrl, err := models.NewMediaUpload().FileUpload(models.File{File: formFile})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -32,7 +33,7 @@ func (server *Server) UpdateUserPhoto(ctx *gin.Context) {
 
 	user, err := server.store.UpdateUserPhoto(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -43,15 +44,14 @@ func RemoteUpload() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var url models.Url
 
-		if err := c.BindJSON(&url); err != nil {
-			util.GenericUploadResponse(c, http.StatusBadRequest, "error", map[string]interface{}{"data": err.Error()})
+		if err := c.ShouldBindJSON(&url); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
 			return
 		}
 
 		uploadUrl, err := models.NewMediaUpload().RemoteUpload(url)
 		if err != nil {
-
-			util.GenericUploadResponse(c, http.StatusInternalServerError, "error", map[string]interface{}{"data": "Error uploading file"})
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
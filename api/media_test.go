@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/maslow123/todoapp-services/apierror"
 	mockdb "github.com/maslow123/todoapp-services/db/mock"
 	db "github.com/maslow123/todoapp-services/db/sqlc"
 	"github.com/maslow123/todoapp-services/token"
@@ -59,7 +60,7 @@ func TestUploadImageWithFormData(t *testing.T) {
 			},
 		},
 		{
-			name:     "InternalServerError",
+			name:     "MissingFile",
 			filePath: "",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", time.Minute)
@@ -70,8 +71,7 @@ func TestUploadImageWithFormData(t *testing.T) {
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
-				requireBodyMatch(t, recorder.Body, user)
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 	}
@@ -119,6 +119,38 @@ func TestUploadImageWithFormData(t *testing.T) {
 	}
 }
 
+func TestUpdateUserPhotoMissingFile(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		UpdateUserPhoto(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	require.NoError(t, mw.WriteField("photo", "not a file"))
+	require.NoError(t, mw.Close())
+
+	request, err := http.NewRequest(http.MethodPost, "/v1/users/me/photo", body)
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", mw.FormDataContentType())
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	var problem apierror.Problem
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	require.Equal(t, apierror.Code("invalid-file"), problem.Code)
+}
+
 // func TestUploadImageWithRemoteURL(t *testing.T) {
 // 	testCases := []struct {
 // 		name          string
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type jsonObject = map[string]interface{}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	uuidType       = reflect.TypeOf(uuid.UUID{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	byteSliceType  = reflect.TypeOf([]byte{})
+)
+
+// schemaRegistry turns Go types into OpenAPI schemas the way encoding/json
+// and gin's binding see them. Named structs become components referenced by
+// $ref.
+type schemaRegistry struct {
+	schemas jsonObject
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{schemas: jsonObject{}}
+}
+
+func (registry *schemaRegistry) schemaFor(t reflect.Type) jsonObject {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return jsonObject{"type": "string", "format": "date-time"}
+	case uuidType:
+		return jsonObject{"type": "string", "format": "uuid"}
+	case rawMessageType:
+		return jsonObject{}
+	case byteSliceType:
+		return jsonObject{"type": "string", "format": "byte"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return jsonObject{"type": "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return jsonObject{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return jsonObject{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return jsonObject{"type": "number"}
+	case reflect.String:
+		return jsonObject{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return jsonObject{"type": "array", "items": registry.schemaFor(t.Elem())}
+	case reflect.Map:
+		return jsonObject{"type": "object", "additionalProperties": registry.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return registry.structSchema(t)
+		}
+		name := t.Name()
+		if _, ok := registry.schemas[name]; !ok {
+			// register first so recursive types end in a $ref
+			registry.schemas[name] = jsonObject{}
+			registry.schemas[name] = registry.structSchema(t)
+		}
+		return jsonObject{"$ref": "#/components/schemas/" + name}
+	}
+	return jsonObject{}
+}
+
+func (registry *schemaRegistry) structSchema(t reflect.Type) jsonObject {
+	properties := jsonObject{}
+	var required []string
+	registry.addFields(t, properties, &required)
+
+	schema := jsonObject{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (registry *schemaRegistry) addFields(t reflect.Type, properties jsonObject, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omit := jsonName(field, "json")
+		if omit {
+			continue
+		}
+
+		// encoding/json promotes the fields of untagged embedded structs
+		if field.Anonymous && field.Tag.Get("json") == "" && field.Type.Kind() == reflect.Struct {
+			registry.addFields(field.Type, properties, required)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+
+		schema, isRequired := registry.fieldSchema(field)
+		properties[name] = schema
+		if isRequired {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// fieldSchema applies the field's binding rules to its schema. Rules after
+// "dive" apply to the items of a slice.
+func (registry *schemaRegistry) fieldSchema(field reflect.StructField) (jsonObject, bool) {
+	schema := copySchema(registry.schemaFor(field.Type))
+	binding := field.Tag.Get("binding")
+	if binding == "" {
+		return schema, false
+	}
+
+	rules := strings.Split(binding, ",")
+	target := schema
+	isRequired := false
+	for i, rule := range rules {
+		if rule == "dive" {
+			items, ok := schema["items"].(jsonObject)
+			if !ok {
+				break
+			}
+			items = copySchema(items)
+			schema["items"] = items
+			target = items
+			continue
+		}
+		if rule == "required" && i == 0 {
+			isRequired = true
+		}
+		applyRule(target, rule)
+	}
+	return schema, isRequired
+}
+
+func copySchema(schema jsonObject) jsonObject {
+	copied := make(jsonObject, len(schema))
+	for key, value := range schema {
+		copied[key] = value
+	}
+	return copied
+}
+
+func applyRule(schema jsonObject, rule string) {
+	name, param := rule, ""
+	if i := strings.Index(rule, "="); i >= 0 {
+		name, param = rule[:i], rule[i+1:]
+	}
+
+	// a $ref can't take sibling keywords in OpenAPI 3.0
+	if _, ok := schema["$ref"]; ok {
+		return
+	}
+
+	switch name {
+	case "oneof":
+		schema["enum"] = strings.Fields(param)
+	case "url":
+		schema["format"] = "uri"
+	case "email":
+		schema["format"] = "email"
+	case "min", "max":
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		schema[limitKeyword(schema["type"], name)] = n
+	}
+}
+
+func limitKeyword(schemaType interface{}, rule string) string {
+	switch schemaType {
+	case "string":
+		return rule + "Length"
+	case "array":
+		return rule + "Items"
+	}
+	return rule + "imum"
+}
+
+// jsonName gives the name a field has under tag, and whether it is left out.
+func jsonName(field reflect.StructField, tag string) (string, bool) {
+	value := field.Tag.Get(tag)
+	if value == "-" {
+		return "", true
+	}
+	name := strings.SplitN(value, ",", 2)[0]
+	if name == "" {
+		name = field.Name
+	}
+	return name, false
+}
+
+// parameters describes the fields of a uri or form struct as parameters in
+// the path or the query.
+func (registry *schemaRegistry) parameters(v interface{}, tag, in string) []jsonObject {
+	if v == nil {
+		return nil
+	}
+
+	var params []jsonObject
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omit := jsonName(field, tag)
+		if omit {
+			continue
+		}
+
+		schema, isRequired := registry.fieldSchema(field)
+		params = append(params, jsonObject{
+			"name":     name,
+			"in":       in,
+			"required": isRequired || in == "path",
+			"schema":   schema,
+		})
+	}
+	return params
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func getOpenAPISpec(t *testing.T, server *Server) map[string]interface{} {
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/v1/openapi.json", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var spec map[string]interface{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &spec)
+	require.NoError(t, err)
+	return spec
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+	paths := getOpenAPISpec(t, server)["paths"].(map[string]interface{})
+
+	routes := map[string]bool{}
+	for _, route := range server.router.Routes() {
+		path := openAPIPath(route.Path)
+		method := strings.ToLower(route.Method)
+		routes[method+" "+path] = true
+
+		item, ok := paths[path].(map[string]interface{})
+		require.True(t, ok, "%s %s is missing from the OpenAPI spec", route.Method, route.Path)
+		require.Contains(t, item, method, "%s %s is missing from the OpenAPI spec", route.Method, route.Path)
+	}
+
+	// and the spec has no routes that were removed
+	for path, item := range paths {
+		for method := range item.(map[string]interface{}) {
+			require.True(t, routes[method+" "+path], "%s %s is in the OpenAPI spec but not registered", method, path)
+		}
+	}
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+	spec := getOpenAPISpec(t, server)
+	require.Equal(t, "3.0.3", spec["openapi"])
+
+	schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+
+	// every $ref points at a schema
+	var checkRefs func(v interface{})
+	checkRefs = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok {
+				name := strings.TrimPrefix(ref, "#/components/schemas/")
+				require.Contains(t, schemas, name)
+			}
+			for _, child := range v {
+				checkRefs(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				checkRefs(child)
+			}
+		}
+	}
+	checkRefs(spec)
+
+	createTodo := schemas["CreateTodoRequest"].(map[string]interface{})
+	require.ElementsMatch(t,
+		[]interface{}{"category_id", "title", "content", "date", "color", "is_priority"},
+		createTodo["required"])
+	properties := createTodo["properties"].(map[string]interface{})
+	require.Equal(t, float64(1), properties["category_id"].(map[string]interface{})["minimum"])
+	require.Equal(t, float64(1), properties["tag_ids"].(map[string]interface{})["items"].(map[string]interface{})["minimum"])
+
+	webhook := schemas["CreateWebhookRequest"].(map[string]interface{})["properties"].(map[string]interface{})
+	require.Equal(t, "uri", webhook["url"].(map[string]interface{})["format"])
+	events := webhook["events"].(map[string]interface{})
+	require.Equal(t, float64(1), events["minItems"])
+	require.Contains(t, events["items"].(map[string]interface{})["enum"], "todo.created")
+
+	// embedded structs are flattened like encoding/json does
+	todoResponse := schemas["TodoResponse"].(map[string]interface{})["properties"].(map[string]interface{})
+	require.Contains(t, todoResponse, "title")
+	require.Contains(t, todoResponse, "tags")
+
+	paths := spec["paths"].(map[string]interface{})
+	getTodo := paths["/v1/todos/{todo_id}"].(map[string]interface{})["get"].(map[string]interface{})
+	require.NotEmpty(t, getTodo["security"])
+	responses := getTodo["responses"].(map[string]interface{})
+	require.Contains(t, responses, "304")
+	require.Contains(t, responses["404"].(map[string]interface{})["content"], "application/problem+json")
+
+	var params []string
+	for _, param := range getTodo["parameters"].([]interface{}) {
+		param := param.(map[string]interface{})
+		params = append(params, param["in"].(string)+":"+param["name"].(string))
+	}
+	require.Equal(t, []string{"path:todo_id", "header:If-None-Match"}, params)
+
+	listTodo := paths["/v1/todos"].(map[string]interface{})["get"].(map[string]interface{})
+	pageSize := listTodo["parameters"].([]interface{})[1].(map[string]interface{})
+	require.Equal(t, "page_size", pageSize["name"])
+	require.Equal(t, "query", pageSize["in"])
+	require.Equal(t, true, pageSize["required"])
+	require.Equal(t, float64(100), pageSize["schema"].(map[string]interface{})["maximum"])
+
+	login := paths["/v1/users/login"].(map[string]interface{})["post"].(map[string]interface{})
+	require.NotContains(t, login, "security")
+}
+
+func TestSwaggerUI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/v1/docs", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Header().Get("Content-Type"), "text/html")
+	require.Contains(t, recorder.Body.String(), "SwaggerUIBundle")
+	require.Contains(t, recorder.Body.String(), "openapi.json")
+	require.NotContains(t, recorder.Body.String(), "https://")
+}
+
+func TestSwaggerUIAssets(t *testing.T) {
+	testCases := []struct {
+		name          string
+		path          string
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Script",
+			path: "/v1/docs/assets/swagger-ui-bundle.js",
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Type"), "javascript")
+				require.Contains(t, recorder.Body.String(), "SwaggerUIBundle")
+			},
+		},
+		{
+			name: "Stylesheet",
+			path: "/v1/docs/assets/swagger-ui.css",
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Type"), "text/css")
+			},
+		},
+		{
+			name: "NotFound",
+			path: "/v1/docs/assets/LICENSE",
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := newTestServer(t, mockdb.NewMockStore(ctrl))
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maslow123/todoapp-services/ratelimit"
+	"github.com/maslow123/todoapp-services/token"
+)
+
+const (
+	rateLimitLimitHeader     = "X-RateLimit-Limit"
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+	rateLimitResetHeader     = "X-RateLimit-Reset"
+	retryAfterHeader         = "Retry-After"
+)
+
+var errRateLimited = errors.New("too-many-requests")
+
+// rateLimitMiddleware limits requests per key. A nil limiter turns it off.
+func rateLimitMiddleware(limiter *ratelimit.Limiter, key func(ctx *gin.Context) string) gin.HandlerFunc {
+	if limiter == nil {
+		return func(ctx *gin.Context) {
+			ctx.Next()
+		}
+	}
+
+	return func(ctx *gin.Context) {
+		result := limiter.Allow(key(ctx))
+
+		header := ctx.Writer.Header()
+		header.Set(rateLimitLimitHeader, strconv.Itoa(limiter.Limit()))
+		header.Set(rateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+		header.Set(rateLimitResetHeader, strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			header.Set(retryAfterHeader, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			abortWithError(ctx, http.StatusTooManyRequests, errRateLimited)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+func clientIPKey(ctx *gin.Context) string {
+	return ctx.ClientIP()
+}
+
+// userKey needs authMiddleware to have run first.
+func userKey(ctx *gin.Context) string {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	return authPayload.Username
+}
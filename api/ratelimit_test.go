@@ -0,0 +1,197 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newRateLimitedServer(t *testing.T, store *mockdb.MockStore) *Server {
+	config := util.Config{
+		TokenSymmetricKey:       util.RandomString(32),
+		AccessTokenDuration:     time.Minute,
+		RateLimitPublicRequests: 1,
+		RateLimitPublicWindow:   time.Minute,
+		RateLimitUserRequests:   1,
+		RateLimitUserWindow:     time.Minute,
+	}
+
+	server, err := NewServer(config, store)
+	require.NoError(t, err)
+	return server
+}
+
+func TestRateLimitPublicRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newRateLimitedServer(t, store)
+
+	send := func(remoteAddr string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodPost, "/users/login", nil)
+		require.NoError(t, err)
+		request.RemoteAddr = remoteAddr
+
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := send("10.0.0.1:1234")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	require.Equal(t, "1", recorder.Header().Get(rateLimitLimitHeader))
+	require.Equal(t, "0", recorder.Header().Get(rateLimitRemainingHeader))
+	require.Equal(t, "60", recorder.Header().Get(rateLimitResetHeader))
+
+	recorder = send("10.0.0.1:1234")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "60", recorder.Header().Get(retryAfterHeader))
+
+	recorder = send("10.0.0.2:1234")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestRateLimitAuthRoutes(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Any()).
+		Times(2).
+		Return(user, nil)
+
+	server := newRateLimitedServer(t, store)
+
+	send := func(email string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/users/me", nil)
+		require.NoError(t, err)
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, email, time.Minute)
+
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	require.Equal(t, http.StatusOK, send(user.Email).Code)
+
+	recorder := send(user.Email)
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.NotEmpty(t, recorder.Header().Get(retryAfterHeader))
+
+	// the limit is per user, not per IP
+	require.Equal(t, http.StatusOK, send(other.Email).Code)
+}
+
+func TestRateLimitForwardedFor(t *testing.T) {
+	testCases := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		// limited tells whether the second request, with another
+		// X-Forwarded-For, is limited
+		limited bool
+	}{
+		{
+			name:       "UntrustedPeer",
+			remoteAddr: "10.0.0.1:1234",
+			limited:    true,
+		},
+		{
+			name:           "PeerIsNotATrustedProxy",
+			trustedProxies: []string{"192.168.0.0/16"},
+			remoteAddr:     "10.0.0.1:1234",
+			limited:        true,
+		},
+		{
+			name:           "TrustedProxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.1:1234",
+			limited:        false,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			config := util.Config{
+				TokenSymmetricKey:       util.RandomString(32),
+				AccessTokenDuration:     time.Minute,
+				RateLimitPublicRequests: 1,
+				RateLimitPublicWindow:   time.Minute,
+				TrustedProxies:          tc.trustedProxies,
+			}
+			server, err := NewServer(config, mockdb.NewMockStore(ctrl))
+			require.NoError(t, err)
+
+			send := func(forwardedFor string) *httptest.ResponseRecorder {
+				recorder := httptest.NewRecorder()
+				request, err := http.NewRequest(http.MethodPost, "/v1/users/login", nil)
+				require.NoError(t, err)
+				request.RemoteAddr = tc.remoteAddr
+				request.Header.Set("X-Forwarded-For", forwardedFor)
+
+				server.router.ServeHTTP(recorder, request)
+				return recorder
+			}
+
+			require.Equal(t, http.StatusBadRequest, send("203.0.113.1").Code)
+
+			recorder := send("203.0.113.2")
+			if tc.limited {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			} else {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestRateLimitSeparateLoginAndRegister(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newRateLimitedServer(t, mockdb.NewMockStore(ctrl))
+
+	send := func(url string) int {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodPost, url, nil)
+		require.NoError(t, err)
+		request.RemoteAddr = "10.0.0.1:1234"
+
+		server.router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	require.Equal(t, http.StatusBadRequest, send("/v1/users/login"))
+	require.Equal(t, http.StatusBadRequest, send("/v1/users"))
+
+	// the deprecated routes share the quota of their /v1 route
+	require.Equal(t, http.StatusTooManyRequests, send("/users/login"))
+	require.Equal(t, http.StatusTooManyRequests, send("/users/register"))
+}
+
+func TestInvalidTrustedProxies(t *testing.T) {
+	config := util.Config{
+		TokenSymmetricKey:   util.RandomString(32),
+		AccessTokenDuration: time.Minute,
+		TrustedProxies:      []string{"not-an-ip"},
+	}
+
+	_, err := NewServer(config, nil)
+	require.EqualError(t, err, "invalid-trusted-proxies")
+}
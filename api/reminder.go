@@ -0,0 +1,149 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/token"
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/maslow123/todoapp-services/worker"
+)
+
+func (server *Server) createReminder(ctx *gin.Context) {
+	var uri ReminderTodoURIRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req CreateReminderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	// a reminder is either at a fixed time or relative to the due date
+	if (req.RemindAt == "") == (req.OffsetMinutes == nil) {
+		abortWithError(ctx, http.StatusBadRequest, errors.New("invalid-reminder-time"))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if req.Channel == worker.ChannelWebhook && !server.isWebhookURL(req.Target) ||
+		req.Channel == worker.ChannelEmail && !isOwnAddress(req.Target, authPayload.Username) {
+		abortWithError(ctx, http.StatusBadRequest, errors.New("invalid-target"))
+		return
+	}
+
+	todo, err := server.store.GetTodo(ctx, uri.TodoID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, http.StatusNotFound, errors.New("todo-not-found"))
+			return
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if todo.UserEmail != authPayload.Username {
+		abortWithError(ctx, http.StatusUnauthorized, errors.New("wrong-user"))
+		return
+	}
+
+	arg := db.CreateReminderParams{
+		TodoID:    todo.ID,
+		UserEmail: authPayload.Username,
+		Channel:   req.Channel,
+		Target:    req.Target,
+	}
+
+	if req.OffsetMinutes != nil {
+		arg.OffsetMinutes = sql.NullInt32{Int32: *req.OffsetMinutes, Valid: true}
+		arg.RemindAt = todo.Date.Add(-time.Duration(*req.OffsetMinutes) * time.Minute)
+	} else {
+		loc, err := server.userLocation(ctx, authPayload.Username)
+		if err != nil {
+			abortWithError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		arg.RemindAt, _, err = util.ParseDueDate(req.RemindAt, loc)
+		if err != nil {
+			abortWithError(ctx, http.StatusBadRequest, errors.New("invalid-reminder-time"))
+			return
+		}
+	}
+
+	reminder, err := server.store.CreateReminder(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reminder)
+}
+
+func (server *Server) listReminders(ctx *gin.Context) {
+	var uri ReminderTodoURIRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.ListRemindersByTodoParams{
+		TodoID:    uri.TodoID,
+		UserEmail: authPayload.Username,
+	}
+
+	reminders, err := server.store.ListRemindersByTodo(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reminders)
+}
+
+func (server *Server) deleteReminder(ctx *gin.Context) {
+	var uri ReminderURIRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.DeleteReminderParams{
+		ID:        uri.ReminderID,
+		TodoID:    uri.TodoID,
+		UserEmail: authPayload.Username,
+	}
+
+	rows, err := server.store.DeleteReminder(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if rows == 0 {
+		abortWithError(ctx, http.StatusNotFound, errors.New("reminder-not-found"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, "OK")
+}
+
+// isOwnAddress tells whether an email reminder target is empty or the user's
+// own address, the only one reminders are mailed to.
+func isOwnAddress(target, userEmail string) bool {
+	if target == "" {
+		return true
+	}
+	address, err := mail.ParseAddress(target)
+	return err == nil && strings.EqualFold(address.Address, userEmail)
+}
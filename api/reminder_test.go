@@ -0,0 +1,447 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateReminderAPI(t *testing.T) {
+	todo := randomTodo(t)
+	user := db.User{Email: todo.UserEmail, TimeZone: util.DefaultTimeZone}
+	row := db.GetTodoRow{
+		ID:        todo.ID,
+		UserEmail: todo.UserEmail,
+		Title:     todo.Title,
+		Date:      todo.Date,
+	}
+	reminder := randomReminder(todo)
+
+	testCases := []struct {
+		name          string
+		todoID        int32
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OffsetOK",
+			todoID: todo.ID,
+			body: gin.H{
+				"offset_minutes": 30,
+				"channel":        "log",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateReminderParams{
+					TodoID:        todo.ID,
+					UserEmail:     todo.UserEmail,
+					RemindAt:      todo.Date.Add(-30 * time.Minute),
+					OffsetMinutes: sql.NullInt32{Int32: 30, Valid: true},
+					Channel:       "log",
+				}
+
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(row, nil)
+
+				store.EXPECT().
+					CreateReminder(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(reminder, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "AbsoluteOK",
+			todoID: todo.ID,
+			body: gin.H{
+				"remind_at": "2019-12-31T20:00",
+				"channel":   "webhook",
+				"target":    "https://example.com/hook",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateReminderParams{
+					TodoID:    todo.ID,
+					UserEmail: todo.UserEmail,
+					RemindAt:  time.Date(2019, 12, 31, 20, 0, 0, 0, time.UTC),
+					Channel:   "webhook",
+					Target:    "https://example.com/hook",
+				}
+
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(row, nil)
+
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					CreateReminder(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(reminder, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "BothTimes",
+			todoID: todo.ID,
+			body: gin.H{
+				"remind_at":      "2019-12-31T20:00",
+				"offset_minutes": 30,
+				"channel":        "log",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateReminder(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidChannel",
+			todoID: todo.ID,
+			body: gin.H{
+				"offset_minutes": 30,
+				"channel":        "pigeon",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateReminder(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidWebhookTarget",
+			todoID: todo.ID,
+			body: gin.H{
+				"offset_minutes": 30,
+				"channel":        "webhook",
+				"target":         "not a url",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateReminder(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "LoopbackWebhookTarget",
+			todoID: todo.ID,
+			body: gin.H{
+				"offset_minutes": 30,
+				"channel":        "webhook",
+				"target":         "http://127.0.0.1:8080/hook",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateReminder(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "MetadataWebhookTarget",
+			todoID: todo.ID,
+			body: gin.H{
+				"offset_minutes": 30,
+				"channel":        "webhook",
+				"target":         "http://169.254.169.254/latest/meta-data",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateReminder(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "PrivateWebhookTarget",
+			todoID: todo.ID,
+			body: gin.H{
+				"offset_minutes": 30,
+				"channel":        "webhook",
+				"target":         "https://10.0.0.5/hook",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateReminder(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "LocalhostWebhookTarget",
+			todoID: todo.ID,
+			body: gin.H{
+				"offset_minutes": 30,
+				"channel":        "webhook",
+				"target":         "http://localhost/hook",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateReminder(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "EmailOwnAddress",
+			todoID: todo.ID,
+			body: gin.H{
+				"offset_minutes": 30,
+				"channel":        "email",
+				"target":         strings.ToUpper(todo.UserEmail),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(row, nil)
+
+				store.EXPECT().
+					CreateReminder(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(reminder, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "EmailOtherAddress",
+			todoID: todo.ID,
+			body: gin.H{
+				"offset_minutes": 30,
+				"channel":        "email",
+				"target":         util.RandomEmail(),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateReminder(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "TodoNotFound",
+			todoID: todo.ID,
+			body: gin.H{
+				"offset_minutes": 30,
+				"channel":        "log",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(db.GetTodoRow{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					CreateReminder(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "WrongUser",
+			todoID: todo.ID,
+			body: gin.H{
+				"offset_minutes": 30,
+				"channel":        "log",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				other := row
+				other.UserEmail = util.RandomEmail()
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(other, nil)
+
+				store.EXPECT().
+					CreateReminder(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/todo/%d/reminders", tc.todoID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestListRemindersAPI(t *testing.T) {
+	todo := randomTodo(t)
+	reminders := []db.Reminder{randomReminder(todo), randomReminder(todo)}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	arg := db.ListRemindersByTodoParams{
+		TodoID:    todo.ID,
+		UserEmail: todo.UserEmail,
+	}
+	store.EXPECT().
+		ListRemindersByTodo(gomock.Any(), gomock.Eq(arg)).
+		Times(1).
+		Return(reminders, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/todo/%d/reminders", todo.ID)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got []db.Reminder
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Len(t, got, len(reminders))
+	require.Equal(t, reminders[0].Status, got[0].Status)
+}
+
+func TestDeleteReminderAPI(t *testing.T) {
+	todo := randomTodo(t)
+	reminder := randomReminder(todo)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.DeleteReminderParams{
+					ID:        reminder.ID,
+					TodoID:    todo.ID,
+					UserEmail: todo.UserEmail,
+				}
+
+				store.EXPECT().
+					DeleteReminder(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteReminder(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/todo/%d/reminders/%d", todo.ID, reminder.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func randomReminder(todo db.Todo) db.Reminder {
+	return db.Reminder{
+		ID:            int32(util.RandomInt(1, 1000)),
+		TodoID:        todo.ID,
+		UserEmail:     todo.UserEmail,
+		RemindAt:      todo.Date.Add(-time.Hour),
+		Channel:       "log",
+		Status:        db.ReminderStatusPending,
+		NextAttemptAt: todo.Date.Add(-time.Hour),
+	}
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/maslow123/todoapp-services/apierror"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	"github.com/maslow123/todoapp-services/token"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorResponses(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		body          string
+		setupRequest  func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		checkResponse func(recorder *httptest.ResponseRecorder, problem apierror.Problem)
+	}{
+		{
+			name:   "ValidationFailed",
+			method: http.MethodPost,
+			url:    "/categories",
+			body:   `{}`,
+			setupRequest: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, problem apierror.Problem) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, apierror.CodeValidationFailed, problem.Code)
+				require.Equal(t, []apierror.FieldError{{Field: "name", Code: "required"}}, problem.Errors)
+				require.Equal(t, "/categories", problem.Instance)
+			},
+		},
+		{
+			name:   "InvalidBody",
+			method: http.MethodPost,
+			url:    "/categories",
+			body:   `{"name":`,
+			setupRequest: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, problem apierror.Problem) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, apierror.CodeInvalidRequestBody, problem.Code)
+			},
+		},
+		{
+			name:   "Unauthorized",
+			method: http.MethodGet,
+			url:    "/categories",
+			setupRequest: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				request.Header.Set(requestIDHeader, "client-request-1")
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, problem apierror.Problem) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Equal(t, apierror.Code("authorization-header-is-not-provided"), problem.Code)
+				require.Equal(t, "client-request-1", problem.RequestID)
+			},
+		},
+		{
+			name:   "RouteNotFound",
+			method: http.MethodGet,
+			url:    "/nope",
+			setupRequest: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, problem apierror.Problem) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, apierror.Code("route-not-found"), problem.Code)
+			},
+		},
+		{
+			name:   "MethodNotAllowed",
+			method: http.MethodDelete,
+			url:    "/users/login",
+			setupRequest: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, problem apierror.Problem) {
+				require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+				require.Equal(t, apierror.Code("method-not-allowed"), problem.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.url, bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+			tc.setupRequest(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			require.True(t, strings.HasPrefix(recorder.Header().Get("Content-Type"), apierror.ContentType))
+
+			var problem apierror.Problem
+			err = json.Unmarshal(recorder.Body.Bytes(), &problem)
+			require.NoError(t, err)
+			require.Equal(t, recorder.Code, problem.Status)
+			require.Equal(t, string(problem.Code), problem.Error)
+			require.NotEmpty(t, problem.RequestID)
+			require.Equal(t, recorder.Header().Get(requestIDHeader), problem.RequestID)
+
+			tc.checkResponse(recorder, problem)
+		})
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+
+	send := func(id string) string {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/nope", nil)
+		require.NoError(t, err)
+		request.Header.Set(requestIDHeader, id)
+
+		server.router.ServeHTTP(recorder, request)
+		return recorder.Header().Get(requestIDHeader)
+	}
+
+	require.Equal(t, "abc-123", send("abc-123"))
+
+	generated := send("")
+	require.Len(t, generated, 36)
+	require.NotEqual(t, generated, send(""))
+
+	// unusable IDs are replaced
+	require.NotEqual(t, "has space", send("has space"))
+	require.Len(t, send(strings.Repeat("a", maxRequestIDLength+1)), 36)
+}
@@ -1,20 +1,67 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/graphql-go/graphql"
+	"github.com/maslow123/todoapp-services/apierror"
+	"github.com/maslow123/todoapp-services/db/migration"
 	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/events"
+	"github.com/maslow123/todoapp-services/ratelimit"
 	"github.com/maslow123/todoapp-services/token"
 	"github.com/maslow123/todoapp-services/util"
 )
 
+var (
+	errRouteNotFound    = errors.New("route-not-found")
+	errMethodNotAllowed = errors.New("method-not-allowed")
+)
+
 type Server struct {
 	config     util.Config
 	store      db.Store
 	router     *gin.Engine
 	tokenMaker token.Maker
+	broker     events.Broker
+
+	openAPISpec   map[string]interface{}
+	legacySunset  time.Time
+	graphqlSchema graphql.Schema
+
+	// registerLimiter, loginLimiter and userLimiter are shared with the gRPC
+	// API, so a client can't double its quota by using both.
+	registerLimiter *ratelimit.Limiter
+	loginLimiter    *ratelimit.Limiter
+	userLimiter     *ratelimit.Limiter
+
+	// migrationVersion is the schema version readyz waits for, and
+	// uploadCheck the upload backend check it runs, if any.
+	migrationVersion int64
+	uploadCheck      func(ctx context.Context) error
+
+	httpServer *http.Server
+	// draining is closed when Shutdown begins, so readyz fails while the load
+	// balancer catches up and requests still get served.
+	draining  chan struct{}
+	drainOnce sync.Once
+	// closing is closed after the drain delay to end the long-lived event
+	// streams, which http.Server.Shutdown would otherwise wait on until its
+	// deadline.
+	closing   chan struct{}
+	closeOnce sync.Once
 }
 
 func NewServer(config util.Config, store db.Store) (*Server, error) {
@@ -28,49 +75,350 @@ func NewServer(config util.Config, store db.Store) (*Server, error) {
 		config:     config,
 		store:      store,
 		tokenMaker: tokenMaker,
+		broker:     events.NewHub(eventBufferSize),
+		draining:   make(chan struct{}),
+		closing:    make(chan struct{}),
+
+		registerLimiter: ratelimit.New(config.RateLimitPublicRequests, config.RateLimitPublicWindow),
+		loginLimiter:    ratelimit.New(config.RateLimitPublicRequests, config.RateLimitPublicWindow),
+		userLimiter:     ratelimit.New(config.RateLimitUserRequests, config.RateLimitUserWindow),
+	}
+
+	server.legacySunset, err = parseLegacySunset(config.LegacyRoutesSunset)
+	if err != nil {
+		log.Println(err)
+		return nil, fmt.Errorf("invalid-legacy-routes-sunset")
+	}
+
+	server.migrationVersion, err = migration.LatestVersion()
+	if err != nil {
+		log.Println(err)
+		return nil, fmt.Errorf("cannot-read-migrations")
+	}
+	if config.ReadinessCheckUploads {
+		server.uploadCheck = func(ctx context.Context) error {
+			return util.PingUploads(ctx, config)
+		}
+	}
+
+	server.graphqlSchema, err = newGraphQLSchema(server)
+	if err != nil {
+		log.Println(err)
+		return nil, fmt.Errorf("cannot-create-graphql-schema")
+	}
+
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonFieldName)
 	}
 
-	server.setupRouter()
+	if err := server.setupRouter(); err != nil {
+		log.Println(err)
+		return nil, fmt.Errorf("invalid-trusted-proxies")
+	}
+	server.httpServer = &http.Server{
+		Handler:           server.router,
+		ReadHeaderTimeout: config.HTTPReadHeaderTimeout,
+		ReadTimeout:       config.HTTPReadTimeout,
+		WriteTimeout:      config.HTTPWriteTimeout,
+		IdleTimeout:       config.HTTPIdleTimeout,
+		ConnContext:       withConn,
+	}
 
 	return server, nil
 }
 
-func (server *Server) setupRouter() {
+func (server *Server) setupRouter() error {
 	router := gin.Default()
+	// ClientIP only reads X-Forwarded-For from these, so clients can't pick
+	// the IP they are rate limited by
+	if err := router.SetTrustedProxies(server.config.TrustedProxies); err != nil {
+		return err
+	}
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(func(ctx *gin.Context) {
+		abortWithError(ctx, http.StatusNotFound, errRouteNotFound)
+	})
+	router.NoMethod(func(ctx *gin.Context) {
+		abortWithError(ctx, http.StatusMethodNotAllowed, errMethodNotAllowed)
+	})
+
+	router.Use(requestIDMiddleware())
+	router.Use(CORSMiddleware(server.config.CORSAllowedOrigins, server.config.CORSMaxAge))
+
+	// login and register share their quota with the deprecated routes, but
+	// not with each other
+	registerLimit := rateLimitMiddleware(server.registerLimiter, clientIPKey)
+	loginLimit := rateLimitMiddleware(server.loginLimiter, clientIPKey)
+	authenticated := []gin.HandlerFunc{
+		authMiddleware(server.tokenMaker),
+		rateLimitMiddleware(server.userLimiter, userKey),
+	}
+	// retried creates replay the first response instead of adding duplicates
+	idempotent := idempotencyMiddleware(server.store, server.config.IdempotencyKeyTTL)
+
+	// probes are left out of rate limiting and versioning
+	router.GET("/healthz", server.healthz)
+	router.GET("/readyz", server.readyz)
+
+	v1 := router.Group("/v1")
 
 	// Users
-	router.Use(CORSMiddleware())
-	router.POST("/users/register", server.createUser)
-	router.POST("/users/login", server.loginUser)
+	v1.POST("/users", registerLimit, server.createUser)
+	v1.POST("/users/login", loginLimit, server.loginUser)
+	v1.GET("/calendar/:token", server.calendarFeed)
+
+	// Docs
+	server.openAPISpec = newOpenAPISpec(apiOperations)
+	v1.GET("/openapi.json", server.openAPI)
+	v1.GET("/docs", server.swaggerUI)
+	v1.GET("/docs/assets/:file", server.swaggerUIAsset)
+
+	authRoutes := v1.Group("/", authenticated...)
 
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker), CORSMiddleware())
 	authRoutes.GET("/users/me", server.me)
+	authRoutes.PUT("/users/me/time_zone", server.updateTimeZone)
+	authRoutes.POST("/users/me/calendar_token", server.createCalendarToken)
+	authRoutes.DELETE("/users/me/calendar_token", server.deleteCalendarToken)
+	authRoutes.GET("/users/me/export", server.exportData)
+	authRoutes.POST("/users/me/import", server.importData)
+	authRoutes.POST("/users/me/import/:source", server.importFromSource)
+	authRoutes.POST("/users/me/photo", server.UpdateUserPhoto)
+
 	// Category
-	authRoutes.POST("/categories", server.createCategory)
+	authRoutes.POST("/categories", idempotent, server.createCategory)
 	authRoutes.GET("/categories", server.listCategories)
-	authRoutes.PATCH("/categories", server.updateCategory)
+	authRoutes.PATCH("/categories/:category_id", server.updateCategory)
 	authRoutes.DELETE("/categories/:category_id", server.deleteCategory)
+	authRoutes.PUT("/categories/:category_id/restore", server.restoreCategory)
 
 	// Todo
-	authRoutes.POST("/todo", server.createTodo)
-	authRoutes.GET("/todo", server.listTodo)
-	authRoutes.GET("/todo/:todo_id", server.getTodo)
-	authRoutes.DELETE("/todo/:todo_id", server.deleteTodo)
-	authRoutes.PUT("/todo", server.updateTodo)
-	authRoutes.PUT("/todo/:todo_id", server.markCompleteTodo)
+	authRoutes.POST("/todos", idempotent, server.createTodo)
+	authRoutes.GET("/todos", server.listTodo)
+	authRoutes.GET("/todos/:todo_id", server.getTodo)
+	authRoutes.DELETE("/todos/:todo_id", server.deleteTodo)
+	authRoutes.PUT("/todos/:todo_id", server.updateTodo)
+	authRoutes.PATCH("/todos/:todo_id", server.patchTodo)
+	authRoutes.POST("/todos/:todo_id/complete", server.markCompleteTodo)
+	authRoutes.PUT("/todos/:todo_id/restore", server.restoreTodo)
+	authRoutes.POST("/todos/import/ics", server.importCalendar)
+	authRoutes.POST("/todos/batch", server.batchTodo)
+
+	// Sync
+	authRoutes.GET("/sync", server.pullChanges)
+	authRoutes.POST("/sync", idempotent, server.pushChanges)
+
+	// Reminder
+	authRoutes.POST("/todos/:todo_id/reminders", idempotent, server.createReminder)
+	authRoutes.GET("/todos/:todo_id/reminders", server.listReminders)
+	authRoutes.DELETE("/todos/:todo_id/reminders/:reminder_id", server.deleteReminder)
+
+	// Tag
+	authRoutes.POST("/tags", idempotent, server.createTag)
+	authRoutes.GET("/tags", server.listTags)
+	authRoutes.PATCH("/tags/:tag_id", server.updateTag)
+	authRoutes.DELETE("/tags/:tag_id", server.deleteTag)
+
+	// Webhook
+	authRoutes.POST("/webhooks", idempotent, server.createWebhook)
+	authRoutes.GET("/webhooks", server.listWebhooks)
+	authRoutes.DELETE("/webhooks/:webhook_id", server.deleteWebhook)
+	authRoutes.GET("/webhooks/:webhook_id/deliveries", server.listWebhookDeliveries)
+
+	// Events
+	authRoutes.GET("/events", server.streamEvents)
+
+	// Trash
+	authRoutes.GET("/trash", server.listTrash)
 
 	// Upload
-	authRoutes.POST("/file", server.UpdateUserPhoto)
-	authRoutes.POST("/remote", RemoteUpload())
+	authRoutes.POST("/uploads", RemoteUpload())
+
+	// GraphQL
+	authRoutes.POST("/graphql", server.graphql)
+
+	server.setupLegacyRoutes(router, registerLimit, loginLimit, idempotent, authenticated)
 	server.router = router
+	return nil
 }
 
+// setupLegacyRoutes keeps the routes from before /v1 working until the sunset
+// date in the config.
+func (server *Server) setupLegacyRoutes(router *gin.Engine, registerLimit, loginLimit, idempotent gin.HandlerFunc, authenticated []gin.HandlerFunc) {
+	legacy := newLegacyRoutes(server.legacySunset)
+	public := router.Group("/", legacy.middleware())
+	auth := public.Group("/", authenticated...)
+
+	legacy.alias(public, http.MethodPost, "/users/register", "/v1/users", registerLimit, server.createUser)
+	legacy.alias(public, http.MethodPost, "/users/login", "/v1/users/login", loginLimit, server.loginUser)
+	legacy.alias(public, http.MethodGet, "/calendar/:token", "/v1/calendar/:token", server.calendarFeed)
+	legacy.alias(public, http.MethodGet, "/openapi.json", "/v1/openapi.json", server.openAPI)
+	legacy.alias(public, http.MethodGet, "/docs", "/v1/docs", server.swaggerUI)
+	legacy.alias(public, http.MethodGet, "/docs/assets/:file", "/v1/docs/assets/:file", server.swaggerUIAsset)
+
+	legacy.alias(auth, http.MethodGet, "/users/me", "/v1/users/me", server.me)
+	legacy.alias(auth, http.MethodPut, "/users/me/time_zone", "/v1/users/me/time_zone", server.updateTimeZone)
+	legacy.alias(auth, http.MethodPost, "/users/me/calendar_token", "/v1/users/me/calendar_token", server.createCalendarToken)
+	legacy.alias(auth, http.MethodDelete, "/users/me/calendar_token", "/v1/users/me/calendar_token", server.deleteCalendarToken)
+	legacy.alias(auth, http.MethodGet, "/users/me/export", "/v1/users/me/export", server.exportData)
+	legacy.alias(auth, http.MethodPost, "/users/me/import", "/v1/users/me/import", server.importData)
+	legacy.alias(auth, http.MethodPost, "/users/me/import/:source", "/v1/users/me/import/:source", server.importFromSource)
+
+	// Category
+	legacy.alias(auth, http.MethodPost, "/categories", "/v1/categories", idempotent, server.createCategory)
+	legacy.alias(auth, http.MethodGet, "/categories", "/v1/categories", server.listCategories)
+	legacy.alias(auth, http.MethodPatch, "/categories", "/v1/categories/:category_id", server.updateCategory)
+	legacy.alias(auth, http.MethodDelete, "/categories/:category_id", "/v1/categories/:category_id", server.deleteCategory)
+	legacy.alias(auth, http.MethodPut, "/categories/:category_id/restore", "/v1/categories/:category_id/restore", server.restoreCategory)
+
+	// Todo
+	legacy.alias(auth, http.MethodPost, "/todo", "/v1/todos", idempotent, server.createTodo)
+	legacy.alias(auth, http.MethodGet, "/todo", "/v1/todos", server.listTodo)
+	legacy.alias(auth, http.MethodGet, "/todo/:todo_id", "/v1/todos/:todo_id", server.getTodo)
+	legacy.alias(auth, http.MethodDelete, "/todo/:todo_id", "/v1/todos/:todo_id", server.deleteTodo)
+	legacy.alias(auth, http.MethodPut, "/todo", "/v1/todos/:todo_id", server.updateTodo)
+	legacy.alias(auth, http.MethodPut, "/todo/:todo_id", "/v1/todos/:todo_id/complete", server.markCompleteTodo)
+	legacy.alias(auth, http.MethodPatch, "/todo/:todo_id", "/v1/todos/:todo_id", server.patchTodo)
+	legacy.alias(auth, http.MethodPut, "/todo/:todo_id/restore", "/v1/todos/:todo_id/restore", server.restoreTodo)
+	legacy.alias(auth, http.MethodPost, "/todo/import/ics", "/v1/todos/import/ics", server.importCalendar)
+	legacy.alias(auth, http.MethodPost, "/todo/batch", "/v1/todos/batch", server.batchTodo)
+
+	// Sync
+	legacy.alias(auth, http.MethodGet, "/sync", "/v1/sync", server.pullChanges)
+	legacy.alias(auth, http.MethodPost, "/sync", "/v1/sync", idempotent, server.pushChanges)
+
+	// Reminder
+	legacy.alias(auth, http.MethodPost, "/todo/:todo_id/reminders", "/v1/todos/:todo_id/reminders", idempotent, server.createReminder)
+	legacy.alias(auth, http.MethodGet, "/todo/:todo_id/reminders", "/v1/todos/:todo_id/reminders", server.listReminders)
+	legacy.alias(auth, http.MethodDelete, "/todo/:todo_id/reminders/:reminder_id", "/v1/todos/:todo_id/reminders/:reminder_id", server.deleteReminder)
+
+	// Tag
+	legacy.alias(auth, http.MethodPost, "/tags", "/v1/tags", idempotent, server.createTag)
+	legacy.alias(auth, http.MethodGet, "/tags", "/v1/tags", server.listTags)
+	legacy.alias(auth, http.MethodPatch, "/tags/:tag_id", "/v1/tags/:tag_id", server.updateTag)
+	legacy.alias(auth, http.MethodDelete, "/tags/:tag_id", "/v1/tags/:tag_id", server.deleteTag)
+
+	// Webhook
+	legacy.alias(auth, http.MethodPost, "/webhooks", "/v1/webhooks", idempotent, server.createWebhook)
+	legacy.alias(auth, http.MethodGet, "/webhooks", "/v1/webhooks", server.listWebhooks)
+	legacy.alias(auth, http.MethodDelete, "/webhooks/:webhook_id", "/v1/webhooks/:webhook_id", server.deleteWebhook)
+	legacy.alias(auth, http.MethodGet, "/webhooks/:webhook_id/deliveries", "/v1/webhooks/:webhook_id/deliveries", server.listWebhookDeliveries)
+
+	// Events
+	legacy.alias(auth, http.MethodGet, "/events", "/v1/events", server.streamEvents)
+
+	// Trash
+	legacy.alias(auth, http.MethodGet, "/trash", "/v1/trash", server.listTrash)
+
+	// Upload
+	legacy.alias(auth, http.MethodPost, "/file", "/v1/users/me/photo", server.UpdateUserPhoto)
+	legacy.alias(auth, http.MethodPost, "/remote", "/v1/uploads", RemoteUpload())
+}
+
+// TokenMaker is the maker the server signs and checks access tokens with.
+func (server *Server) TokenMaker() token.Maker {
+	return server.tokenMaker
+}
+
+// Broker is the broker the event stream is fed from.
+func (server *Server) Broker() events.Broker {
+	return server.broker
+}
+
+// RegisterLimiter and LoginLimiter limit sign ups and logins per client IP.
+// Either is nil when public rate limiting is off.
+func (server *Server) RegisterLimiter() *ratelimit.Limiter {
+	return server.registerLimiter
+}
+
+func (server *Server) LoginLimiter() *ratelimit.Limiter {
+	return server.loginLimiter
+}
+
+// UserLimiter limits authenticated requests per user. It is nil when user
+// rate limiting is off.
+func (server *Server) UserLimiter() *ratelimit.Limiter {
+	return server.userLimiter
+}
+
+// Start serves HTTP on address until Shutdown is called.
 func (server *Server) Start(address string) error {
-	return server.router.Run(address)
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	log.Println("http", listener.Addr())
+	return server.serve(listener)
+}
+
+func (server *Server) serve(listener net.Listener) error {
+	err := server.httpServer.Serve(listener)
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// Shutdown fails readyz and keeps serving for the drain delay, then stops
+// accepting connections, ends open event streams and waits for in-flight
+// requests to finish or ctx to be done.
+func (server *Server) Shutdown(ctx context.Context) error {
+	server.drainOnce.Do(func() {
+		close(server.draining)
+	})
+	if delay := server.config.ShutdownDrainDelay; delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
+	server.closeOnce.Do(func() {
+		close(server.closing)
+	})
+	return server.httpServer.Shutdown(ctx)
+}
+
+type connContextKey struct{}
+
+// withConn keeps the connection in the request context, so a handler that
+// streams can lift the server's write timeout for itself.
+func withConn(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connContextKey{}, conn)
+}
+
+// abortWithError ends the request with err as an application/problem+json
+// response. Postgres errors a client can act on override status.
+func abortWithError(ctx *gin.Context, status int, err error) {
+	apiErr := dbError(err)
+	if apiErr == nil {
+		apiErr = apierror.From(status, err)
+	}
+	if apiErr.Status >= http.StatusInternalServerError || apiErr.Detail == apierror.DetailUnknown {
+		log.Println(ctx.Request.Method, ctx.Request.URL.Path, requestID(ctx), err)
+	}
+	// serialization failures and deadlocks succeed when retried
+	if apiErr.Status == http.StatusServiceUnavailable {
+		ctx.Header(retryAfterHeader, "1")
+	}
+
+	ctx.Header("Content-Type", apierror.ContentType)
+	ctx.AbortWithStatusJSON(apiErr.Status, apiErr.Problem(ctx.Request.URL.Path, requestID(ctx)))
 }
 
-func errorResponse(err error) gin.H {
-	return gin.H{
-		"error": err.Error(),
+// jsonFieldName makes validation errors name fields the way clients send
+// them.
+func jsonFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
 	}
+	return field.Name
 }
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/events"
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerShutdown(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	config := util.Config{
+		TokenSymmetricKey:   util.RandomString(32),
+		AccessTokenDuration: time.Minute,
+		HTTPWriteTimeout:    100 * time.Millisecond,
+	}
+	server, err := NewServer(config, mockdb.NewMockStore(ctrl))
+	require.NoError(t, err)
+	hub := server.broker.(*events.Hub)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	served := make(chan error, 1)
+	go func() {
+		served <- server.serve(listener)
+	}()
+	url := "http://" + listener.Addr().String()
+
+	request, err := http.NewRequest(http.MethodGet, url+"/v1/events", nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	require.Eventually(t, func() bool {
+		return hub.Subscribers(user.Email) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// the event stream is not cut off by the write timeout
+	time.Sleep(3 * config.HTTPWriteTimeout)
+	server.publish(user.Email, db.EventTodoCreated, "still open")
+	line, err := bufio.NewReader(response.Body).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "id:1\n", line)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, server.Shutdown(ctx))
+
+	// shutting down ended the stream instead of waiting out the deadline
+	_, err = io.Copy(ioutil.Discard, response.Body)
+	require.NoError(t, err)
+	require.NoError(t, <-served)
+	require.Zero(t, hub.Subscribers(user.Email))
+
+	_, err = http.Get(url + "/v1/openapi.json")
+	require.Error(t, err)
+}
+
+func TestServerShutdownDrain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	config := util.Config{
+		TokenSymmetricKey:   util.RandomString(32),
+		AccessTokenDuration: time.Minute,
+		ShutdownDrainDelay:  300 * time.Millisecond,
+	}
+	server, err := NewServer(config, store)
+	require.NoError(t, err)
+	store.EXPECT().Ping(gomock.Any()).AnyTimes().Return(nil)
+	store.EXPECT().MigrationVersion(gomock.Any()).AnyTimes().Return(server.migrationVersion, false, nil)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	served := make(chan error, 1)
+	go func() {
+		served <- server.serve(listener)
+	}()
+	url := "http://" + listener.Addr().String()
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- server.Shutdown(context.Background())
+	}()
+
+	// during the drain delay readyz fails but requests are still served
+	require.Eventually(t, func() bool {
+		response, err := http.Get(url + "/readyz")
+		if err != nil {
+			return false
+		}
+		response.Body.Close()
+		return response.StatusCode == http.StatusServiceUnavailable
+	}, config.ShutdownDrainDelay, 10*time.Millisecond)
+
+	response, err := http.Get(url + "/v1/openapi.json")
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	require.NoError(t, <-shutdown)
+	require.NoError(t, <-served)
+
+	_, err = http.Get(url + "/v1/openapi.json")
+	require.Error(t, err)
+}
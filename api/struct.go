@@ -1,6 +1,10 @@
 package api
 
-import db "github.com/maslow123/todoapp-services/db/sqlc"
+import (
+	"time"
+
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+)
 
 // Category
 type CreateCategoryRequest struct {
@@ -12,15 +16,29 @@ type ListCategoryRequest struct {
 	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
 }
 
+// UpdateCategoryRequest takes category_id only on the deprecated
+// PATCH /categories; /v1 has it in the path.
 type UpdateCategoryRequest struct {
-	CategoryID int32  `json:"category_id" binding:"required"`
+	CategoryID int32  `json:"category_id" binding:"omitempty,min=1"`
 	Name       string `json:"name" binding:"required"`
 }
 
+type CategoryURIRequest struct {
+	CategoryID int32 `uri:"category_id" binding:"required,min=1"`
+}
+
 type DeleteCategoryRequest struct {
 	CategoryID int32 `uri:"category_id" binding:"required,min=1"`
 }
 
+type DeleteCategoryQuery struct {
+	Todos string `form:"todos" binding:"omitempty,oneof=restrict move"`
+}
+
+type RestoreCategoryRequest struct {
+	CategoryID int32 `uri:"category_id" binding:"required,min=1"`
+}
+
 // User
 type CreateUserRequest struct {
 	Name     string `json:"name" binding:"required"`
@@ -28,6 +46,7 @@ type CreateUserRequest struct {
 	Pic      string `json:"pic" binding:"required"`
 	Password string `json:"password" binding:"required"`
 	Email    string `json:"email" binding:"required"`
+	TimeZone string `json:"time_zone"`
 }
 
 type LoginUserRequest struct {
@@ -36,25 +55,31 @@ type LoginUserRequest struct {
 }
 
 type LoginUserResponse struct {
-	AccessToken string  `json:"access_token"`
-	User        db.User `json:"user"`
+	AccessToken string              `json:"access_token"`
+	User        GenericUserResponse `json:"user"`
 }
 
 type GenericUserResponse struct {
-	Name    string `json:"name"`
-	Address string `json:"address"`
-	Pic     string `json:"pic"`
-	Email   string `json:"email"`
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Pic      string `json:"pic"`
+	Email    string `json:"email"`
+	TimeZone string `json:"time_zone"`
+}
+
+type UpdateTimeZoneRequest struct {
+	TimeZone string `json:"time_zone" binding:"required"`
 }
 
 // Todo
 type CreateTodoRequest struct {
-	CategoryID int32  `json:"category_id" binding:"required,min=1"`
-	Title      string `json:"title" binding:"required"`
-	Content    string `json:"content" binding:"required"`
-	Date       string `json:"date" binding:"required"`
-	Color      string `json:"color" binding:"required"`
-	IsPriority *bool  `json:"is_priority" binding:"required"`
+	CategoryID int32   `json:"category_id" binding:"required,min=1"`
+	Title      string  `json:"title" binding:"required"`
+	Content    string  `json:"content" binding:"required"`
+	Date       string  `json:"date" binding:"required"`
+	Color      string  `json:"color" binding:"required"`
+	IsPriority *bool   `json:"is_priority" binding:"required"`
+	TagIDs     []int32 `json:"tag_ids" binding:"omitempty,dive,min=1"`
 }
 
 type GetTodoRequest struct {
@@ -64,6 +89,12 @@ type GetTodoRequest struct {
 type ListTodoRequest struct {
 	PageID   int32 `form:"page_id" binding:"required,min=1"`
 	PageSize int32 `form:"page_size" binding:"required,min=5,max=100"`
+	TagID    int32 `form:"tag_id" binding:"omitempty,min=1"`
+}
+
+type TodoResponse struct {
+	db.Todo
+	Tags []db.Tag `json:"tags"`
 }
 
 type ListTodoResponse struct {
@@ -71,15 +102,259 @@ type ListTodoResponse struct {
 	Upcoming []db.ListUpcomingTodoRow `json:"upcoming"`
 	Done     []db.ListDoneTodoRow     `json:"done"`
 }
+
+// UpdateTodoRequest takes todo_id only on the deprecated PUT /todo; /v1 has it
+// in the path.
 type UpdateTodoRequest struct {
-	TodoID     int32  `json:"todo_id" binding:"required,min=1"`
-	CategoryID int32  `json:"category_id" binding:"required,min=1"`
-	Title      string `json:"title" binding:"required"`
-	Content    string `json:"content" binding:"required"`
-	Date       string `json:"date" binding:"required"`
-	Color      string `json:"color" binding:"required"`
-	IsPriority *bool  `json:"is_priority" binding:"required"`
+	TodoID     int32   `json:"todo_id" binding:"omitempty,min=1"`
+	CategoryID int32   `json:"category_id" binding:"required,min=1"`
+	Title      string  `json:"title" binding:"required"`
+	Content    string  `json:"content" binding:"required"`
+	Date       string  `json:"date" binding:"required"`
+	Color      string  `json:"color" binding:"required"`
+	IsPriority *bool   `json:"is_priority" binding:"required"`
+	TagIDs     []int32 `json:"tag_ids" binding:"omitempty,dive,min=1"`
+}
+
+// PatchTodoRequest follows JSON merge patch: fields that are left out keep
+// their value. Only tag_ids may be null, which removes all tags.
+type PatchTodoRequest struct {
+	CategoryID *int32  `json:"category_id" binding:"omitempty,min=1"`
+	Title      *string `json:"title" binding:"omitempty,min=1"`
+	Content    *string `json:"content" binding:"omitempty,min=1"`
+	Date       *string `json:"date"`
+	Color      *string `json:"color" binding:"omitempty,min=1"`
+	IsPriority *bool   `json:"is_priority"`
+	TagIDs     []int32 `json:"tag_ids" binding:"omitempty,dive,min=1"`
 }
+
 type MarkCompleteTodoRequest struct {
 	TodoID int32 `uri:"todo_id" binding:"required,min=1"`
 }
+
+// Tag
+type CreateTagRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+type ListTagRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=100"`
+}
+
+type TagURIRequest struct {
+	TagID int32 `uri:"tag_id" binding:"required,min=1"`
+}
+
+type UpdateTagRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+// Reminder
+type ReminderTodoURIRequest struct {
+	TodoID int32 `uri:"todo_id" binding:"required,min=1"`
+}
+
+type ReminderURIRequest struct {
+	TodoID     int32 `uri:"todo_id" binding:"required,min=1"`
+	ReminderID int32 `uri:"reminder_id" binding:"required,min=1"`
+}
+
+type CreateReminderRequest struct {
+	RemindAt      string `json:"remind_at"`
+	OffsetMinutes *int32 `json:"offset_minutes" binding:"omitempty,min=0"`
+	Channel       string `json:"channel" binding:"required,oneof=log webhook email"`
+	Target        string `json:"target"`
+}
+
+// Webhook
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,url"`
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=todo.* todo.created todo.updated todo.completed todo.deleted category.* category.created category.updated category.deleted"`
+}
+
+type WebhookURIRequest struct {
+	WebhookID int32 `uri:"webhook_id" binding:"required,min=1"`
+}
+
+type ListWebhookDeliveryRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=100"`
+}
+
+type WebhookResponse struct {
+	ID        int32     `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Trash
+type ListTrashRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=100"`
+}
+
+type ListTrashResponse struct {
+	Todos      []db.Todo     `json:"todos"`
+	Categories []db.Category `json:"categories"`
+}
+
+type CalendarFeedRequest struct {
+	Token string `uri:"token" binding:"required"`
+}
+
+type CalendarFeedQuery struct {
+	Component string `form:"component" binding:"omitempty,oneof=vtodo vevent"`
+}
+
+type CalendarTokenResponse struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+type ImportRowError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+type ImportCalendarResponse struct {
+	Created []db.Todo        `json:"created"`
+	Errors  []ImportRowError `json:"errors"`
+}
+
+// Export / import
+type ExportRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=json csv"`
+}
+
+type ImportRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=json csv"`
+	DryRun bool   `form:"dry_run"`
+}
+
+type ExportCategory struct {
+	Name string `json:"name"`
+}
+
+type ExportTodo struct {
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	Category string `json:"category"`
+	// Date is a date for all-day todos and RFC 3339 otherwise, like the
+	// dates accepted when creating a todo.
+	Date       string    `json:"date"`
+	Color      string    `json:"color"`
+	IsPriority bool      `json:"is_priority"`
+	Status     bool      `json:"status"`
+	Tags       []string  `json:"tags"`
+	CreatedAt  time.Time `json:"created_at,omitempty"`
+}
+
+type ExportArchive struct {
+	Version    int                 `json:"version"`
+	ExportedAt time.Time           `json:"exported_at"`
+	User       GenericUserResponse `json:"user"`
+	Categories []ExportCategory    `json:"categories"`
+	Todos      []ExportTodo        `json:"todos"`
+}
+
+type ImportResponse struct {
+	DryRun            bool             `json:"dry_run"`
+	CreatedCategories int              `json:"created_categories"`
+	CreatedTags       int              `json:"created_tags"`
+	CreatedTodos      int              `json:"created_todos"`
+	Errors            []ImportRowError `json:"errors"`
+}
+
+type ImportSourceRequest struct {
+	Source string `uri:"source" binding:"required"`
+}
+
+type ImportSourceQuery struct {
+	DryRun bool `form:"dry_run"`
+}
+
+type BatchTodoOperationRequest struct {
+	Op         string  `json:"op" binding:"required,oneof=complete reopen delete move set_priority reschedule"`
+	TodoIDs    []int32 `json:"todo_ids" binding:"required,min=1,dive,min=1"`
+	CategoryID int32   `json:"category_id" binding:"omitempty,min=1"`
+	IsPriority *bool   `json:"is_priority"`
+	Date       string  `json:"date"`
+}
+
+type BatchTodoRequest struct {
+	Operations []BatchTodoOperationRequest `json:"operations" binding:"required,min=1,dive"`
+}
+
+type SyncPullRequest struct {
+	Since string `form:"since"`
+	Limit int32  `form:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// SyncPullResponse lists what changed after the request's token. Clients pass
+// NextToken to the next pull and keep pulling while HasMore is set.
+type SyncPullResponse struct {
+	Todos             []TodoResponse `json:"todos"`
+	Categories        []db.Category  `json:"categories"`
+	DeletedTodos      []int32        `json:"deleted_todos"`
+	DeletedCategories []int32        `json:"deleted_categories"`
+	NextToken         string         `json:"next_token"`
+	HasMore           bool           `json:"has_more"`
+}
+
+type SyncChangeRequest struct {
+	Entity      string  `json:"entity" binding:"required,oneof=todo category"`
+	Op          string  `json:"op" binding:"required,oneof=upsert delete"`
+	ID          int32   `json:"id" binding:"omitempty,min=1"`
+	ClientID    string  `json:"client_id" binding:"max=100"`
+	BaseVersion int32   `json:"base_version" binding:"omitempty,min=1"`
+	CategoryID  *int32  `json:"category_id" binding:"omitempty,min=1"`
+	Title       *string `json:"title" binding:"omitempty,min=1"`
+	Content     *string `json:"content"`
+	Date        *string `json:"date"`
+	Color       *string `json:"color" binding:"omitempty,min=1"`
+	IsPriority  *bool   `json:"is_priority"`
+	Status      *bool   `json:"status"`
+	TagIDs      []int32 `json:"tag_ids" binding:"omitempty,dive,min=1"`
+	Name        *string `json:"name" binding:"omitempty,min=1"`
+}
+
+type SyncPushRequest struct {
+	Changes []SyncChangeRequest `json:"changes" binding:"required,min=1,max=500,dive"`
+}
+
+// GraphQL
+type GraphQLRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+type GraphQLResponse struct {
+	Data   map[string]interface{} `json:"data,omitempty"`
+	Errors []GraphQLError         `json:"errors,omitempty"`
+}
+
+// Docs
+type SwaggerUIAssetRequest struct {
+	File string `uri:"file" binding:"required"`
+}
+
+// Health
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+type ReadinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
@@ -0,0 +1,381 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/token"
+	"github.com/maslow123/todoapp-services/util"
+)
+
+const defaultSyncLimit = 500
+
+var errInvalidSyncToken = errors.New("invalid-sync-token")
+
+// pullChanges returns the todos and categories that changed after the since
+// token, oldest first. Trashed entities and purged ones are listed by id.
+func (server *Server) pullChanges(ctx *gin.Context) {
+	var req SyncPullRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	since, err := parseSyncToken(req.Since)
+	if err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultSyncLimit
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	// changes written by transactions that may still be running are left
+	// for a later pull, so one committing late can't land behind the token
+	horizon, err := server.store.GetSyncHorizon(ctx)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	todos, err := server.store.ListTodoChangesSince(ctx, db.ListTodoChangesSinceParams{
+		UserEmail: authPayload.Username,
+		SinceTxid: since.txid,
+		SinceSeq:  since.seq,
+		Horizon:   horizon,
+		MaxRows:   limit,
+	})
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	categories, err := server.store.ListCategoryChangesSince(ctx, db.ListCategoryChangesSinceParams{
+		SinceTxid: since.txid,
+		SinceSeq:  since.seq,
+		Horizon:   horizon,
+		MaxRows:   limit,
+	})
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	tombstones, err := server.store.ListTombstonesSince(ctx, db.ListTombstonesSinceParams{
+		SinceTxid: since.txid,
+		SinceSeq:  since.seq,
+		Horizon:   horizon,
+		UserEmail: authPayload.Username,
+		MaxRows:   limit,
+	})
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	pages := []syncPage{{count: len(todos)}, {count: len(categories)}, {count: len(tombstones)}}
+	if n := len(todos); n > 0 {
+		pages[0].last = syncPosition{todos[n-1].ChangeTxid, todos[n-1].ChangeSeq}
+	}
+	if n := len(categories); n > 0 {
+		pages[1].last = syncPosition{categories[n-1].ChangeTxid, categories[n-1].ChangeSeq}
+	}
+	if n := len(tombstones); n > 0 {
+		pages[2].last = syncPosition{tombstones[n-1].ChangeTxid, tombstones[n-1].ChangeSeq}
+	}
+	upTo, hasMore := syncPageEnd(since, horizon, int(limit), pages)
+
+	resp := SyncPullResponse{
+		Todos:             []TodoResponse{},
+		Categories:        []db.Category{},
+		DeletedTodos:      []int32{},
+		DeletedCategories: []int32{},
+		NextToken:         formatSyncToken(upTo),
+		HasMore:           hasMore,
+	}
+	for _, todo := range todos {
+		if upTo.before(syncPosition{todo.ChangeTxid, todo.ChangeSeq}) {
+			break
+		}
+		if todo.DeletedAt.Valid {
+			resp.DeletedTodos = append(resp.DeletedTodos, todo.ID)
+			continue
+		}
+
+		item, err := newSyncTodoResponse(todo)
+		if err != nil {
+			abortWithError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		resp.Todos = append(resp.Todos, item)
+	}
+	for _, category := range categories {
+		if upTo.before(syncPosition{category.ChangeTxid, category.ChangeSeq}) {
+			break
+		}
+		if category.DeletedAt.Valid {
+			resp.DeletedCategories = append(resp.DeletedCategories, category.ID)
+			continue
+		}
+		resp.Categories = append(resp.Categories, category)
+	}
+	for _, tombstone := range tombstones {
+		if upTo.before(syncPosition{tombstone.ChangeTxid, tombstone.ChangeSeq}) {
+			break
+		}
+		switch tombstone.Entity {
+		case db.SyncEntityTodo:
+			resp.DeletedTodos = append(resp.DeletedTodos, tombstone.EntityID)
+		case db.SyncEntityCategory:
+			resp.DeletedCategories = append(resp.DeletedCategories, tombstone.EntityID)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// syncPosition orders changes by the transaction that wrote them and then by
+// change sequence. Transactions below the horizon have all finished, so
+// nothing new can appear behind a position taken from them.
+type syncPosition struct {
+	txid int64
+	seq  int64
+}
+
+func (p syncPosition) before(other syncPosition) bool {
+	return p.txid < other.txid || (p.txid == other.txid && p.seq < other.seq)
+}
+
+type syncPage struct {
+	count int
+	last  syncPosition
+}
+
+// syncPageEnd picks the position a pull covers. A full page may have cut off
+// changes that the other lists did return, so the pull then stops at the
+// lowest point any full page reached. Otherwise every change below the
+// horizon has been sent and the next pull can start there.
+func syncPageEnd(since syncPosition, horizon int64, limit int, pages []syncPage) (upTo syncPosition, hasMore bool) {
+	upTo = since
+	for _, page := range pages {
+		if page.count == limit && (!hasMore || page.last.before(upTo)) {
+			upTo, hasMore = page.last, true
+		}
+	}
+	if hasMore {
+		return upTo, true
+	}
+
+	if end := (syncPosition{txid: horizon}); upTo.before(end) {
+		upTo = end
+	}
+	return upTo, false
+}
+
+func newSyncTodoResponse(todo db.ListTodoChangesSinceRow) (TodoResponse, error) {
+	var tags []db.Tag
+	if err := json.Unmarshal(todo.Tags, &tags); err != nil {
+		return TodoResponse{}, err
+	}
+
+	return TodoResponse{
+		Todo: db.Todo{
+			ID:         todo.ID,
+			CategoryID: todo.CategoryID,
+			Title:      todo.Title,
+			Content:    todo.Content,
+			CreatedAt:  todo.CreatedAt,
+			UpdatedAt:  todo.UpdatedAt,
+			UserEmail:  todo.UserEmail,
+			Color:      todo.Color,
+			Date:       todo.Date,
+			IsPriority: todo.IsPriority,
+			Status:     todo.Status,
+			AllDay:     todo.AllDay,
+			Version:    todo.Version,
+			ChangeSeq:  todo.ChangeSeq,
+		},
+		Tags: tags,
+	}, nil
+}
+
+// pushChanges applies changes a client made offline. A change whose
+// base_version is stale is a conflict: the server's copy wins and is sent
+// back instead, and the client should redo its edit on top of it.
+func (server *Server) pushChanges(ctx *gin.Context) {
+	var req SyncPushRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	var loc *time.Location
+	arg := db.SyncTxParams{
+		UserEmail: authPayload.Username,
+		Changes:   make([]db.SyncChange, 0, len(req.Changes)),
+	}
+	for i, change := range req.Changes {
+		if change.Date != nil && loc == nil {
+			var err error
+			loc, err = server.userLocation(ctx, authPayload.Username)
+			if err != nil {
+				abortWithError(ctx, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		syncChange, err := newSyncChange(change, loc)
+		if err != nil {
+			abortWithError(ctx, http.StatusBadRequest, fmt.Errorf("changes-%d-%s", i, err))
+			return
+		}
+		arg.Changes = append(arg.Changes, syncChange)
+	}
+
+	result, err := server.store.SyncTx(ctx, arg)
+	if err != nil {
+		log.Println(err)
+		if err == db.ErrInvalidTags {
+			abortWithError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	for _, item := range result.Results {
+		if item.Status != db.SyncStatusApplied {
+			continue
+		}
+		switch {
+		case item.Entity == db.SyncEntityCategory && item.Created:
+			server.publish("", db.EventCategoryCreated, item.Category)
+		case item.Entity == db.SyncEntityCategory:
+			server.publish("", db.EventCategoryUpdated, item.Category)
+		case item.Deleted:
+			server.publish(arg.UserEmail, db.EventTodoDeleted, db.DeleteTodoTxParams{
+				ID:        item.ID,
+				UserEmail: arg.UserEmail,
+			})
+		case item.Created:
+			server.publish(arg.UserEmail, db.EventTodoCreated, newTodoResponse(*item.Todo))
+		default:
+			server.publish(arg.UserEmail, db.EventTodoUpdated, newTodoResponse(*item.Todo))
+		}
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func newSyncChange(req SyncChangeRequest, loc *time.Location) (db.SyncChange, error) {
+	change := db.SyncChange{
+		Entity:      req.Entity,
+		Op:          req.Op,
+		ID:          req.ID,
+		ClientID:    req.ClientID,
+		BaseVersion: req.BaseVersion,
+		TagIDs:      req.TagIDs,
+	}
+
+	if req.Op == db.SyncOpDelete {
+		if req.ID == 0 {
+			return db.SyncChange{}, errors.New("missing-id")
+		}
+		return change, nil
+	}
+
+	if req.Entity == db.SyncEntityCategory {
+		if req.Name == nil {
+			return db.SyncChange{}, errors.New("missing-name")
+		}
+		change.Name = *req.Name
+		return change, nil
+	}
+
+	if req.ID == 0 {
+		switch {
+		case req.CategoryID == nil:
+			return db.SyncChange{}, errors.New("missing-category-id")
+		case req.Title == nil:
+			return db.SyncChange{}, errors.New("missing-title")
+		case req.Date == nil:
+			return db.SyncChange{}, errors.New("missing-date")
+		}
+	}
+
+	todo := &change.Todo
+	if req.CategoryID != nil {
+		todo.CategoryID = sql.NullInt32{Int32: *req.CategoryID, Valid: true}
+	}
+	if req.Title != nil {
+		todo.Title = sql.NullString{String: *req.Title, Valid: true}
+	}
+	if req.Content != nil {
+		todo.Content = sql.NullString{String: *req.Content, Valid: true}
+	}
+	if req.Date != nil {
+		date, allDay, err := util.ParseDueDate(*req.Date, loc)
+		if err != nil {
+			return db.SyncChange{}, errors.New("invalid-date")
+		}
+		todo.Date = sql.NullTime{Time: date, Valid: true}
+		todo.AllDay = sql.NullBool{Bool: allDay, Valid: true}
+	}
+	if req.Color != nil {
+		todo.Color = sql.NullString{String: *req.Color, Valid: true}
+	} else if req.ID == 0 {
+		todo.Color = sql.NullString{String: importedTodoColor, Valid: true}
+	}
+	if req.IsPriority != nil {
+		todo.IsPriority = sql.NullBool{Bool: *req.IsPriority, Valid: true}
+	}
+	if req.Status != nil {
+		change.Status = sql.NullBool{Bool: *req.Status, Valid: true}
+	}
+
+	return change, nil
+}
+
+// Sync tokens are opaque to clients; they carry the position of the last
+// change the client has seen as "txid.seq". Tokens from before transaction
+// ids were tracked are a bare change sequence.
+func parseSyncToken(value string) (syncPosition, error) {
+	if value == "" {
+		return syncPosition{}, nil
+	}
+
+	parts := strings.SplitN(value, ".", 2)
+	if len(parts) == 1 {
+		parts = []string{"0", value}
+	}
+
+	var pos syncPosition
+	var err error
+	pos.txid, err = strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || pos.txid < 0 {
+		return syncPosition{}, errInvalidSyncToken
+	}
+	pos.seq, err = strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || pos.seq < 0 {
+		return syncPosition{}, errInvalidSyncToken
+	}
+	return pos, nil
+}
+
+func formatSyncToken(pos syncPosition) string {
+	return strconv.FormatInt(pos.txid, 10) + "." + strconv.FormatInt(pos.seq, 10)
+}
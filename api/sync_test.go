@@ -0,0 +1,486 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullChangesAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	todo := randomTodo(t)
+	todo.UserEmail = user.Email
+
+	todoRow := func(id int32, txid, seq int64, deleted bool) db.ListTodoChangesSinceRow {
+		return db.ListTodoChangesSinceRow{
+			ID:         id,
+			UserEmail:  user.Email,
+			Title:      todo.Title,
+			Version:    2,
+			DeletedAt:  sql.NullTime{Time: time.Now(), Valid: deleted},
+			ChangeSeq:  seq,
+			ChangeTxid: txid,
+			Tags:       json.RawMessage(`[{"id": 1, "name": "home"}]`),
+		}
+	}
+
+	var horizon int64 = 50
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "?since=42.10",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSyncHorizon(gomock.Any()).
+					Times(1).
+					Return(horizon, nil)
+				store.EXPECT().
+					ListTodoChangesSince(gomock.Any(), gomock.Eq(db.ListTodoChangesSinceParams{
+						UserEmail: user.Email,
+						SinceTxid: 42,
+						SinceSeq:  10,
+						Horizon:   horizon,
+						MaxRows:   defaultSyncLimit,
+					})).
+					Times(1).
+					Return([]db.ListTodoChangesSinceRow{todoRow(todo.ID, 42, 11, false), todoRow(5, 44, 14, true)}, nil)
+				store.EXPECT().
+					ListCategoryChangesSince(gomock.Any(), gomock.Eq(db.ListCategoryChangesSinceParams{
+						SinceTxid: 42,
+						SinceSeq:  10,
+						Horizon:   horizon,
+						MaxRows:   defaultSyncLimit,
+					})).
+					Times(1).
+					Return([]db.Category{{ID: 3, Name: "work", ChangeTxid: 43, ChangeSeq: 12}}, nil)
+				store.EXPECT().
+					ListTombstonesSince(gomock.Any(), gomock.Eq(db.ListTombstonesSinceParams{
+						SinceTxid: 42,
+						SinceSeq:  10,
+						Horizon:   horizon,
+						UserEmail: user.Email,
+						MaxRows:   defaultSyncLimit,
+					})).
+					Times(1).
+					Return([]db.Tombstone{
+						{Entity: db.SyncEntityTodo, EntityID: 6, ChangeTxid: 43, ChangeSeq: 13},
+						{Entity: db.SyncEntityCategory, EntityID: 4, ChangeTxid: 45, ChangeSeq: 15},
+					}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp SyncPullResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+
+				require.Len(t, resp.Todos, 1)
+				require.Equal(t, todo.ID, resp.Todos[0].ID)
+				require.Equal(t, int32(2), resp.Todos[0].Version)
+				require.Len(t, resp.Todos[0].Tags, 1)
+				require.Len(t, resp.Categories, 1)
+				require.Equal(t, []int32{5, 6}, resp.DeletedTodos)
+				require.Equal(t, []int32{4}, resp.DeletedCategories)
+				// everything below the horizon was sent
+				require.Equal(t, "50.0", resp.NextToken)
+				require.False(t, resp.HasMore)
+			},
+		},
+		{
+			name:  "LegacyToken",
+			query: "?since=10",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSyncHorizon(gomock.Any()).
+					Times(1).
+					Return(horizon, nil)
+				store.EXPECT().
+					ListTodoChangesSince(gomock.Any(), gomock.Eq(db.ListTodoChangesSinceParams{
+						UserEmail: user.Email,
+						SinceSeq:  10,
+						Horizon:   horizon,
+						MaxRows:   defaultSyncLimit,
+					})).
+					Times(1).
+					Return([]db.ListTodoChangesSinceRow{}, nil)
+				store.EXPECT().
+					ListCategoryChangesSince(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Category{}, nil)
+				store.EXPECT().
+					ListTombstonesSince(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Tombstone{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp SyncPullResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Equal(t, "50.0", resp.NextToken)
+			},
+		},
+		{
+			name:  "FullPage",
+			query: "?limit=2",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSyncHorizon(gomock.Any()).
+					Times(1).
+					Return(horizon, nil)
+				// seq 4 was drawn before seq 3 but its transaction is newer
+				store.EXPECT().
+					ListTodoChangesSince(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListTodoChangesSinceRow{todoRow(1, 40, 1, false), todoRow(2, 41, 4, false)}, nil)
+				store.EXPECT().
+					ListCategoryChangesSince(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Category{{ID: 3, ChangeTxid: 40, ChangeSeq: 2}, {ID: 4, ChangeTxid: 42, ChangeSeq: 6}}, nil)
+				store.EXPECT().
+					ListTombstonesSince(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Tombstone{{Entity: db.SyncEntityTodo, EntityID: 9, ChangeTxid: 41, ChangeSeq: 3}}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp SyncPullResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+
+				// the todo page ends at 41.4, so the category at 42.6 waits for the next pull
+				require.Equal(t, "41.4", resp.NextToken)
+				require.True(t, resp.HasMore)
+				require.Len(t, resp.Todos, 2)
+				require.Len(t, resp.Categories, 1)
+				require.Equal(t, []int32{9}, resp.DeletedTodos)
+			},
+		},
+		{
+			name:  "NoChanges",
+			query: "?since=60.20",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSyncHorizon(gomock.Any()).
+					Times(1).
+					Return(horizon, nil)
+				store.EXPECT().
+					ListTodoChangesSince(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListTodoChangesSinceRow{}, nil)
+				store.EXPECT().
+					ListCategoryChangesSince(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Category{}, nil)
+				store.EXPECT().
+					ListTombstonesSince(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Tombstone{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp SyncPullResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Equal(t, "60.20", resp.NextToken)
+				require.Empty(t, resp.Todos)
+			},
+		},
+		{
+			name:  "InvalidToken",
+			query: "?since=1.abc",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSyncHorizon(gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					ListTodoChangesSince(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "HorizonError",
+			query: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSyncHorizon(gomock.Any()).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+				store.EXPECT().
+					ListTodoChangesSince(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSyncHorizon(gomock.Any()).
+					Times(1).
+					Return(horizon, nil)
+				store.EXPECT().
+					ListTodoChangesSince(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/sync"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestPushChangesAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	user.TimeZone = "Asia/Jakarta"
+	todo := randomTodo(t)
+	todo.UserEmail = user.Email
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"changes": []gin.H{
+					{"entity": "todo", "op": "upsert", "client_id": "c1", "category_id": 7, "title": "new", "date": "2020-01-02"},
+					{"entity": "todo", "op": "upsert", "id": todo.ID, "base_version": 3, "title": "edited", "status": true},
+					{"entity": "todo", "op": "delete", "id": 9, "base_version": 1},
+					{"entity": "category", "op": "upsert", "name": "errands"},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				jakarta, err := time.LoadLocation("Asia/Jakarta")
+				require.NoError(t, err)
+
+				arg := db.SyncTxParams{
+					UserEmail: user.Email,
+					Changes: []db.SyncChange{
+						{
+							Entity:   db.SyncEntityTodo,
+							Op:       db.SyncOpUpsert,
+							ClientID: "c1",
+							Todo: db.PatchTodoParams{
+								CategoryID: sql.NullInt32{Int32: 7, Valid: true},
+								Title:      sql.NullString{String: "new", Valid: true},
+								Date:       sql.NullTime{Time: time.Date(2020, 1, 2, 0, 0, 0, 0, jakarta), Valid: true},
+								AllDay:     sql.NullBool{Bool: true, Valid: true},
+								Color:      sql.NullString{String: importedTodoColor, Valid: true},
+							},
+						},
+						{
+							Entity:      db.SyncEntityTodo,
+							Op:          db.SyncOpUpsert,
+							ID:          todo.ID,
+							BaseVersion: 3,
+							Todo: db.PatchTodoParams{
+								Title: sql.NullString{String: "edited", Valid: true},
+							},
+							Status: sql.NullBool{Bool: true, Valid: true},
+						},
+						{Entity: db.SyncEntityTodo, Op: db.SyncOpDelete, ID: 9, BaseVersion: 1},
+						{Entity: db.SyncEntityCategory, Op: db.SyncOpUpsert, Name: "errands"},
+					},
+				}
+
+				created := todo
+				created.ID = 100
+
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					SyncTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.SyncTxResult{Results: []db.SyncChangeResult{
+						{Index: 0, ClientID: "c1", Entity: db.SyncEntityTodo, ID: 100, Status: db.SyncStatusApplied, Created: true,
+							Todo: &db.TodoTxResult{Todo: created, Tags: []db.Tag{}}},
+						{Index: 1, Entity: db.SyncEntityTodo, ID: todo.ID, Status: db.SyncStatusConflict,
+							Todo: &db.TodoTxResult{Todo: todo, Tags: []db.Tag{}}},
+						{Index: 2, Entity: db.SyncEntityTodo, ID: 9, Status: db.SyncStatusApplied, Deleted: true},
+						{Index: 3, Entity: db.SyncEntityCategory, ID: 4, Status: db.SyncStatusApplied, Created: true,
+							Category: &db.Category{ID: 4, Name: "errands"}},
+					}}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp db.SyncTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Len(t, resp.Results, 4)
+				require.Equal(t, int32(100), resp.Results[0].ID)
+				require.Equal(t, db.SyncStatusConflict, resp.Results[1].Status)
+				require.Equal(t, todo.Title, resp.Results[1].Todo.Todo.Title)
+			},
+		},
+		{
+			name: "CreateWithoutTitle",
+			body: gin.H{
+				"changes": []gin.H{
+					{"entity": "todo", "op": "upsert", "category_id": 7, "date": "2020-01-02"},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Return(user, nil)
+				store.EXPECT().
+					SyncTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "changes-0-missing-title")
+			},
+		},
+		{
+			name: "DeleteWithoutID",
+			body: gin.H{
+				"changes": []gin.H{
+					{"entity": "todo", "op": "delete"},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SyncTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "changes-0-missing-id")
+			},
+		},
+		{
+			name: "InvalidDate",
+			body: gin.H{
+				"changes": []gin.H{
+					{"entity": "todo", "op": "upsert", "id": todo.ID, "date": "someday"},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					SyncTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "changes-0-invalid-date")
+			},
+		},
+		{
+			name: "InvalidEntity",
+			body: gin.H{
+				"changes": []gin.H{
+					{"entity": "tag", "op": "upsert", "name": "x"},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SyncTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidTags",
+			body: gin.H{
+				"changes": []gin.H{
+					{"entity": "todo", "op": "upsert", "id": todo.ID, "tag_ids": []int32{3}},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SyncTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.SyncTxResult{}, db.ErrInvalidTags)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/sync", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/token"
+)
+
+func (server *Server) createTag(ctx *gin.Context) {
+	var req CreateTagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.CreateTagParams{
+		UserEmail: authPayload.Username,
+		Name:      req.Name,
+	}
+
+	tag, err := server.store.CreateTag(ctx, arg)
+	if err != nil {
+		if db.ErrorCode(err) == db.UniqueViolation {
+			abortWithError(ctx, http.StatusConflict, errors.New("tag-already-exists"))
+			return
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tag)
+}
+
+func (server *Server) listTags(ctx *gin.Context) {
+	var req ListTagRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.ListTagsParams{
+		UserEmail: authPayload.Username,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	}
+
+	tags, err := server.store.ListTags(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tags)
+}
+
+func (server *Server) updateTag(ctx *gin.Context) {
+	var uri TagURIRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req UpdateTagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.UpdateTagParams{
+		ID:        uri.TagID,
+		UserEmail: authPayload.Username,
+		Name:      req.Name,
+	}
+
+	tag, err := server.store.UpdateTag(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, http.StatusNotFound, errors.New("tag-not-found"))
+			return
+		}
+		if db.ErrorCode(err) == db.UniqueViolation {
+			abortWithError(ctx, http.StatusConflict, errors.New("tag-already-exists"))
+			return
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tag)
+}
+
+func (server *Server) deleteTag(ctx *gin.Context) {
+	var uri TagURIRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.DeleteTagParams{
+		ID:        uri.TagID,
+		UserEmail: authPayload.Username,
+	}
+
+	deleted, err := server.store.DeleteTag(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if deleted == 0 {
+		abortWithError(ctx, http.StatusNotFound, errors.New("tag-not-found"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, "OK")
+}
@@ -3,29 +3,46 @@ package api
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
-	"time"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	db "github.com/maslow123/todoapp-services/db/sqlc"
 	"github.com/maslow123/todoapp-services/token"
+	"github.com/maslow123/todoapp-services/util"
 )
 
+func newTodoResponse(result db.TodoTxResult) TodoResponse {
+	return TodoResponse{
+		Todo: result.Todo,
+		Tags: result.Tags,
+	}
+}
+
 func (server *Server) createTodo(ctx *gin.Context) {
 	var req CreateTodoRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		log.Println(err)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
-	date, err := time.Parse("2006-01-02", req.Date)
+	loc, err := server.userLocation(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	date, allDay, err := util.ParseDueDate(req.Date, loc)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("invalid-date")))
+		abortWithError(ctx, http.StatusBadRequest, errors.New("invalid-date"))
 		return
 	}
 
@@ -33,37 +50,47 @@ func (server *Server) createTodo(ctx *gin.Context) {
 	_, err = server.store.GetCategory(context.Background(), req.CategoryID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("invalid-category")))
+			abortWithError(ctx, http.StatusNotFound, errors.New("invalid-category"))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	arg := db.CreateTodoParams{
-		UserEmail:  authPayload.Username,
-		CategoryID: req.CategoryID,
-		Title:      req.Title,
-		Content:    req.Content,
-		Date:       date,
-		Color:      req.Color,
-		IsPriority: *req.IsPriority,
+	arg := db.CreateTodoTxParams{
+		CreateTodoParams: db.CreateTodoParams{
+			UserEmail:  authPayload.Username,
+			CategoryID: req.CategoryID,
+			Title:      req.Title,
+			Content:    req.Content,
+			Date:       date,
+			AllDay:     allDay,
+			Color:      req.Color,
+			IsPriority: *req.IsPriority,
+		},
+		TagIDs: req.TagIDs,
 	}
 
-	todo, err := server.store.CreateTodo(context.Background(), arg)
+	result, err := server.store.CreateTodoTx(context.Background(), arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		if err == db.ErrInvalidTags {
+			abortWithError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, todo)
+	resp := newTodoResponse(result)
+	server.publish(resp.UserEmail, db.EventTodoCreated, resp)
+	ctx.JSON(http.StatusOK, resp)
 }
 
 func (server *Server) getTodo(ctx *gin.Context) {
 	var req GetTodoRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
 		log.Println(err)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
@@ -71,17 +98,24 @@ func (server *Server) getTodo(ctx *gin.Context) {
 	if err != nil {
 		log.Println(err)
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("not-found")))
+			abortWithError(ctx, http.StatusNotFound, errors.New("not-found"))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	if todo.UserEmail != authPayload.Username {
 		err := errors.New("wrong-user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		abortWithError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	etag := versionETag(todo.Version)
+	ctx.Header("ETag", etag)
+	if notModified(ctx, etag) {
+		ctx.Status(http.StatusNotModified)
 		return
 	}
 
@@ -92,7 +126,7 @@ func (server *Server) listTodo(ctx *gin.Context) {
 	var req ListTodoRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
 		log.Println(err)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
@@ -104,11 +138,12 @@ func (server *Server) listTodo(ctx *gin.Context) {
 		UserEmail: authPayload.Username,
 		Limit:     req.PageSize,
 		Offset:    (req.PageID - 1) * req.PageSize,
+		TagID:     req.TagID,
 	}
 	todayTodo, err := server.store.ListTodayTodo(ctx, argTodayList)
 	if err != nil {
 		if err != sql.ErrNoRows {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			abortWithError(ctx, http.StatusInternalServerError, err)
 			return
 		}
 	}
@@ -119,11 +154,12 @@ func (server *Server) listTodo(ctx *gin.Context) {
 		UserEmail: authPayload.Username,
 		Limit:     req.PageSize,
 		Offset:    (req.PageID - 1) * req.PageSize,
+		TagID:     req.TagID,
 	}
 	upcomingTodo, err := server.store.ListUpcomingTodo(ctx, argUpcomingList)
 	if err != nil {
 		if err != sql.ErrNoRows {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			abortWithError(ctx, http.StatusInternalServerError, err)
 			return
 		}
 	}
@@ -134,11 +170,12 @@ func (server *Server) listTodo(ctx *gin.Context) {
 		UserEmail: authPayload.Username,
 		Limit:     req.PageSize,
 		Offset:    (req.PageID - 1) * req.PageSize,
+		TagID:     req.TagID,
 	}
 	doneTodo, err := server.store.ListDoneTodo(ctx, argDoneList)
 	if err != nil {
 		if err != sql.ErrNoRows {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			abortWithError(ctx, http.StatusInternalServerError, err)
 			return
 		}
 	}
@@ -151,27 +188,57 @@ func (server *Server) deleteTodo(ctx *gin.Context) {
 	var req GetTodoRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
 		log.Println(err)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, http.StatusBadRequest, err)
 		return
 	}
-	_, err := server.store.GetTodo(ctx, req.TodoID)
+
+	ifMatch, ok := ifMatchVersions(ctx)
+	if !ok {
+		abortPreconditionFailed(ctx)
+		return
+	}
+
+	todo, err := server.store.GetTodo(ctx, req.TodoID)
 	if err != nil {
 		log.Println(err)
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("not-found")))
+			abortWithError(ctx, http.StatusNotFound, errors.New("not-found"))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	err = server.store.DeleteTodo(ctx, req.TodoID)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if todo.UserEmail != authPayload.Username {
+		abortWithError(ctx, http.StatusUnauthorized, errors.New("wrong-user"))
+		return
+	}
+
+	version, ok := ifMatch.version(todo.Version)
+	if !ok {
+		abortPreconditionFailed(ctx)
+		return
+	}
+
+	arg := db.DeleteTodoTxParams{
+		ID:        todo.ID,
+		UserEmail: todo.UserEmail,
+		Version:   version,
+	}
+	err = server.store.DeleteTodoTx(ctx, arg)
 	if err != nil {
 		log.Println(err)
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		if err == db.ErrVersionMismatch {
+			abortPreconditionFailed(ctx)
+			return
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
+	server.publish(arg.UserEmail, db.EventTodoDeleted, arg)
+
 	ctx.JSON(http.StatusOK, "OK")
 }
 
@@ -179,76 +246,303 @@ func (server *Server) updateTodo(ctx *gin.Context) {
 	var req UpdateTodoRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		log.Println(err)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	date, err := time.Parse("2006-01-02", req.Date)
+	todoID, err := resourceID(ctx, "todo_id", req.TodoID)
+	if err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	req.TodoID = todoID
+
+	ifMatch, ok := ifMatchVersions(ctx)
+	if !ok {
+		abortPreconditionFailed(ctx)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	loc, err := server.userLocation(ctx, authPayload.Username)
 	if err != nil {
 		log.Println(err)
-		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("invalid-date")))
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	date, allDay, err := util.ParseDueDate(req.Date, loc)
+	if err != nil {
+		log.Println(err)
+		abortWithError(ctx, http.StatusBadRequest, errors.New("invalid-date"))
 		return
 	}
 
 	// check todo is exists or no
-	_, err = server.store.GetTodo(context.Background(), req.TodoID)
+	todo, err := server.store.GetTodo(context.Background(), req.TodoID)
 	if err != nil {
 		log.Println(err)
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("todo-not-found")))
+			abortWithError(ctx, http.StatusNotFound, errors.New("todo-not-found"))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if todo.UserEmail != authPayload.Username {
+		abortWithError(ctx, http.StatusUnauthorized, errors.New("wrong-user"))
+		return
+	}
+	version, ok := ifMatch.version(todo.Version)
+	if !ok {
+		abortPreconditionFailed(ctx)
 		return
 	}
 
 	// update todo
-	arg := db.UpdateTodoByUserParams{
-		ID:         req.TodoID,
-		CategoryID: req.CategoryID,
-		Title:      req.Title,
-		Content:    req.Content,
-		Date:       date,
-		Color:      req.Color,
-		IsPriority: *req.IsPriority,
+	arg := db.UpdateTodoTxParams{
+		UpdateTodoByUserParams: db.UpdateTodoByUserParams{
+			ID:         req.TodoID,
+			CategoryID: req.CategoryID,
+			Title:      req.Title,
+			Content:    req.Content,
+			Date:       date,
+			AllDay:     allDay,
+			Color:      req.Color,
+			IsPriority: *req.IsPriority,
+		},
+		TagIDs:  req.TagIDs,
+		Version: version,
 	}
 
-	todo, err := server.store.UpdateTodoByUser(context.Background(), arg)
+	result, err := server.store.UpdateTodoTx(context.Background(), arg)
 	if err != nil {
 		log.Println(err)
+		switch err {
+		case db.ErrInvalidTags:
+			abortWithError(ctx, http.StatusBadRequest, err)
+			return
+		case db.ErrVersionMismatch:
+			abortPreconditionFailed(ctx)
+			return
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	resp := newTodoResponse(result)
+	server.publish(resp.UserEmail, db.EventTodoUpdated, resp)
+	ctx.Header("ETag", versionETag(resp.Version))
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (server *Server) patchTodo(ctx *gin.Context) {
+	var uri GetTodoRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, todo)
+	var req PatchTodoRequest
+	if err := ctx.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	ifMatch, ok := ifMatchVersions(ctx)
+	if !ok {
+		abortPreconditionFailed(ctx)
+		return
+	}
+
+	// a null can't be told apart from a missing field once it is bound
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(ctx.MustGet(gin.BodyBytesKey).([]byte), &fields); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	for name, value := range fields {
+		if string(value) != "null" {
+			continue
+		}
+		if name != "tag_ids" {
+			abortWithError(ctx, http.StatusBadRequest, fmt.Errorf("%s-cannot-be-null", strings.ReplaceAll(name, "_", "-")))
+			return
+		}
+		req.TagIDs = []int32{}
+	}
+
+	todo, err := server.store.GetTodo(ctx, uri.TodoID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, http.StatusNotFound, errors.New("todo-not-found"))
+			return
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if todo.UserEmail != authPayload.Username {
+		abortWithError(ctx, http.StatusUnauthorized, errors.New("wrong-user"))
+		return
+	}
+	version, ok := ifMatch.version(todo.Version)
+	if !ok {
+		abortPreconditionFailed(ctx)
+		return
+	}
+
+	arg := db.PatchTodoTxParams{
+		PatchTodoParams: db.PatchTodoParams{ID: todo.ID},
+		TagIDs:          req.TagIDs,
+		Version:         version,
+	}
+
+	if req.CategoryID != nil {
+		_, err = server.store.GetCategory(ctx, *req.CategoryID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				abortWithError(ctx, http.StatusNotFound, errors.New("invalid-category"))
+				return
+			}
+			abortWithError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		arg.CategoryID = sql.NullInt32{Int32: *req.CategoryID, Valid: true}
+	}
+
+	if req.Date != nil {
+		loc, err := server.userLocation(ctx, authPayload.Username)
+		if err != nil {
+			abortWithError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		date, allDay, err := util.ParseDueDate(*req.Date, loc)
+		if err != nil {
+			abortWithError(ctx, http.StatusBadRequest, errors.New("invalid-date"))
+			return
+		}
+		arg.Date = sql.NullTime{Time: date, Valid: true}
+		arg.AllDay = sql.NullBool{Bool: allDay, Valid: true}
+	}
+
+	if req.Title != nil {
+		arg.Title = sql.NullString{String: *req.Title, Valid: true}
+	}
+	if req.Content != nil {
+		arg.Content = sql.NullString{String: *req.Content, Valid: true}
+	}
+	if req.Color != nil {
+		arg.Color = sql.NullString{String: *req.Color, Valid: true}
+	}
+	if req.IsPriority != nil {
+		arg.IsPriority = sql.NullBool{Bool: *req.IsPriority, Valid: true}
+	}
+
+	result, err := server.store.PatchTodoTx(ctx, arg)
+	if err != nil {
+		log.Println(err)
+		switch err {
+		case db.ErrInvalidTags:
+			abortWithError(ctx, http.StatusBadRequest, err)
+			return
+		case db.ErrVersionMismatch:
+			abortPreconditionFailed(ctx)
+			return
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := newTodoResponse(result)
+	server.publish(resp.UserEmail, db.EventTodoUpdated, resp)
+	ctx.Header("ETag", versionETag(resp.Version))
+	ctx.JSON(http.StatusOK, resp)
 }
 
 func (server *Server) markCompleteTodo(ctx *gin.Context) {
 	var req MarkCompleteTodoRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
 		log.Println(err)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	ifMatch, ok := ifMatchVersions(ctx)
+	if !ok {
+		abortPreconditionFailed(ctx)
 		return
 	}
 
 	// check todo is exists or no
-	_, err := server.store.GetTodo(context.Background(), req.TodoID)
+	current, err := server.store.GetTodo(context.Background(), req.TodoID)
 	if err != nil {
 		log.Println(err)
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("todo-not-found")))
+			abortWithError(ctx, http.StatusNotFound, errors.New("todo-not-found"))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if current.UserEmail != authPayload.Username {
+		abortWithError(ctx, http.StatusUnauthorized, errors.New("wrong-user"))
+		return
+	}
+	version, ok := ifMatch.version(current.Version)
+	if !ok {
+		abortPreconditionFailed(ctx)
 		return
 	}
 
-	todo, err := server.store.MarkAsCompleteTodo(context.Background(), req.TodoID)
+	arg := db.CompleteTodoTxParams{
+		ID:      req.TodoID,
+		Version: version,
+	}
+	todo, err := server.store.CompleteTodoTx(context.Background(), arg)
 	if err != nil {
 		log.Println(err)
+		if err == db.ErrVersionMismatch {
+			abortPreconditionFailed(ctx)
+			return
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.publish(todo.UserEmail, db.EventTodoCompleted, todo)
+	ctx.Header("ETag", versionETag(todo.Version))
+
+	ctx.JSON(http.StatusOK, todo)
+}
+
+func (server *Server) restoreTodo(ctx *gin.Context) {
+	var req GetTodoRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println(err)
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.RestoreTodoParams{
+		ID:        req.TodoID,
+		UserEmail: authPayload.Username,
+	}
+
+	// only todos in the user's own trash can be restored
+	todo, err := server.store.RestoreTodo(ctx, arg)
+	if err != nil {
+		log.Println(err)
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, http.StatusNotFound, errors.New("todo-not-found"))
+			return
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
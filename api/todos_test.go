@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -22,6 +23,8 @@ import (
 
 func TestCreateTodoAPI(t *testing.T) {
 	todo := randomTodo(t)
+	tag := randomTag(todo.UserEmail)
+	user := db.User{Email: todo.UserEmail, TimeZone: util.DefaultTimeZone}
 	testCases := []struct {
 		name          string
 		body          gin.H
@@ -43,11 +46,17 @@ func TestCreateTodoAPI(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+
 				arg := db.CreateTodoParams{
 					CategoryID: todo.CategoryID,
 					Title:      todo.Title,
 					Content:    todo.Content,
 					Date:       todo.Date,
+					AllDay:     true,
 					Color:      todo.Color,
 					IsPriority: todo.IsPriority,
 					UserEmail:  todo.UserEmail,
@@ -58,15 +67,201 @@ func TestCreateTodoAPI(t *testing.T) {
 					Return(db.Category{}, nil)
 
 				store.EXPECT().
-					CreateTodo(gomock.Any(), gomock.Eq(arg)).
+					CreateTodoTx(gomock.Any(), gomock.Eq(db.CreateTodoTxParams{CreateTodoParams: arg})).
 					Times(1).
-					Return(todo, nil)
+					Return(db.TodoTxResult{Todo: todo, Tags: []db.Tag{}}, nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 				requireBodyMatchTodo(t, recorder.Body, todo)
 			},
 		},
+		{
+			name: "DueTimeInUserTimeZone",
+			body: gin.H{
+				"category_id": todo.CategoryID,
+				"title":       todo.Title,
+				"content":     todo.Content,
+				"date":        "2020-01-01T09:30",
+				"color":       todo.Color,
+				"is_priority": todo.IsPriority,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				jakarta := user
+				jakarta.TimeZone = "Asia/Jakarta"
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(jakarta, nil)
+
+				store.EXPECT().
+					GetCategory(gomock.Any(), gomock.Eq(todo.CategoryID)).
+					Times(1).
+					Return(db.Category{}, nil)
+
+				store.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateTodoTxParams) (db.TodoTxResult, error) {
+						require.False(t, arg.AllDay)
+						require.Equal(t, "2020-01-01T02:30:00Z", arg.Date.UTC().Format(time.RFC3339))
+						return db.TodoTxResult{Todo: todo, Tags: []db.Tag{}}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "RFC3339Date",
+			body: gin.H{
+				"category_id": todo.CategoryID,
+				"title":       todo.Title,
+				"content":     todo.Content,
+				"date":        "2020-01-01T09:30:00-05:00",
+				"color":       todo.Color,
+				"is_priority": todo.IsPriority,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					GetCategory(gomock.Any(), gomock.Eq(todo.CategoryID)).
+					Times(1).
+					Return(db.Category{}, nil)
+
+				store.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateTodoTxParams) (db.TodoTxResult, error) {
+						require.False(t, arg.AllDay)
+						require.Equal(t, "2020-01-01T14:30:00Z", arg.Date.UTC().Format(time.RFC3339))
+						return db.TodoTxResult{Todo: todo, Tags: []db.Tag{}}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WithTags",
+			body: gin.H{
+				"category_id": todo.CategoryID,
+				"title":       todo.Title,
+				"content":     todo.Content,
+				"date":        "2020-01-01",
+				"color":       todo.Color,
+				"is_priority": todo.IsPriority,
+				"tag_ids":     []int32{tag.ID},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+
+				arg := db.CreateTodoTxParams{
+					CreateTodoParams: db.CreateTodoParams{
+						CategoryID: todo.CategoryID,
+						Title:      todo.Title,
+						Content:    todo.Content,
+						Date:       todo.Date,
+						AllDay:     true,
+						Color:      todo.Color,
+						IsPriority: todo.IsPriority,
+						UserEmail:  todo.UserEmail,
+					},
+					TagIDs: []int32{tag.ID},
+				}
+				store.EXPECT().
+					GetCategory(gomock.Any(), gomock.Eq(todo.CategoryID)).
+					Times(1).
+					Return(db.Category{}, nil)
+
+				store.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TodoTxResult{Todo: todo, Tags: []db.Tag{tag}}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotTodo TodoResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotTodo)
+				require.NoError(t, err)
+				require.Equal(t, todo.Title, gotTodo.Title)
+				require.Equal(t, []db.Tag{tag}, gotTodo.Tags)
+			},
+		},
+		{
+			name: "InvalidTags",
+			body: gin.H{
+				"category_id": todo.CategoryID,
+				"title":       todo.Title,
+				"content":     todo.Content,
+				"date":        "2020-01-01",
+				"color":       todo.Color,
+				"is_priority": todo.IsPriority,
+				"tag_ids":     []int32{tag.ID},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					GetCategory(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Category{}, nil)
+
+				store.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TodoTxResult{}, db.ErrInvalidTags)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidTagID",
+			body: gin.H{
+				"category_id": todo.CategoryID,
+				"title":       todo.Title,
+				"content":     todo.Content,
+				"date":        "2020-01-01",
+				"color":       todo.Color,
+				"is_priority": todo.IsPriority,
+				"tag_ids":     []int32{0},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "NoAuthorization",
 			body: gin.H{
@@ -86,7 +281,7 @@ func TestCreateTodoAPI(t *testing.T) {
 					Times(0)
 
 				store.EXPECT().
-					CreateTodo(gomock.Any(), gomock.Any()).
+					CreateTodoTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -112,7 +307,7 @@ func TestCreateTodoAPI(t *testing.T) {
 					Times(0)
 
 				store.EXPECT().
-					CreateTodo(gomock.Any(), gomock.Any()).
+					CreateTodoTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -138,7 +333,7 @@ func TestCreateTodoAPI(t *testing.T) {
 					Times(0)
 
 				store.EXPECT().
-					CreateTodo(gomock.Any(), gomock.Any()).
+					CreateTodoTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -164,7 +359,7 @@ func TestCreateTodoAPI(t *testing.T) {
 					Times(0)
 
 				store.EXPECT().
-					CreateTodo(gomock.Any(), gomock.Any()).
+					CreateTodoTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -185,12 +380,17 @@ func TestCreateTodoAPI(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+
 				store.EXPECT().
 					GetCategory(gomock.Any(), gomock.Any()).
 					Times(0)
 
 				store.EXPECT().
-					CreateTodo(gomock.Any(), gomock.Any()).
+					CreateTodoTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -216,7 +416,7 @@ func TestCreateTodoAPI(t *testing.T) {
 					Times(0)
 
 				store.EXPECT().
-					CreateTodo(gomock.Any(), gomock.Any()).
+					CreateTodoTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -242,7 +442,7 @@ func TestCreateTodoAPI(t *testing.T) {
 					Times(0)
 
 				store.EXPECT().
-					CreateTodo(gomock.Any(), gomock.Any()).
+					CreateTodoTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -263,12 +463,17 @@ func TestCreateTodoAPI(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+
 				store.EXPECT().
 					GetCategory(gomock.Any(), gomock.Any()).
 					Return(db.Category{}, sql.ErrNoRows)
 
 				store.EXPECT().
-					CreateTodo(gomock.Any(), gomock.Any()).
+					CreateTodoTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -325,6 +530,7 @@ func TestListTodosAPI(t *testing.T) {
 			IsPriority:   todo.IsPriority,
 			Color:        todo.Color,
 			CategoryName: todo.CategoryName,
+			Tags:         todo.Tags,
 		}
 		todayList = append(todayList, today)
 
@@ -337,6 +543,7 @@ func TestListTodosAPI(t *testing.T) {
 			IsPriority:   todo.IsPriority,
 			Color:        todo.Color,
 			CategoryName: todo.CategoryName,
+			Tags:         todo.Tags,
 		}
 		upcomingList = append(upcomingList, upcoming)
 
@@ -349,6 +556,7 @@ func TestListTodosAPI(t *testing.T) {
 			IsPriority:   todo.IsPriority,
 			Color:        todo.Color,
 			CategoryName: todo.CategoryName,
+			Tags:         todo.Tags,
 		}
 		doneList = append(doneList, done)
 	}
@@ -524,6 +732,7 @@ func TestGetTodo(t *testing.T) {
 		Date:       todo.Date,
 		Color:      todo.Color,
 		IsPriority: todo.IsPriority,
+		Tags:       json.RawMessage(`[]`),
 	}
 
 	testCases := []struct {
@@ -583,6 +792,47 @@ func TestGetTodo(t *testing.T) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
+		{
+			name:   "ETag",
+			todoID: todo.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+				request.Header.Set("If-None-Match", `"2"`)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				row := resp
+				row.Version = 3
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(row, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `"3"`, recorder.Header().Get("ETag"))
+			},
+		},
+		{
+			name:   "NotModified",
+			todoID: todo.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+				request.Header.Set("If-None-Match", `"2", W/"3"`)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				row := resp
+				row.Version = 3
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(row, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotModified, recorder.Code)
+				require.Equal(t, `"3"`, recorder.Header().Get("ETag"))
+				require.Empty(t, recorder.Body.String())
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -628,10 +878,15 @@ func TestDeleteTodo(t *testing.T) {
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
-					Times(1)
+					Times(1).
+					Return(db.GetTodoRow{ID: todo.ID, UserEmail: todo.UserEmail}, nil)
 
+				arg := db.DeleteTodoTxParams{
+					ID:        todo.ID,
+					UserEmail: todo.UserEmail,
+				}
 				store.EXPECT().
-					DeleteTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					DeleteTodoTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return(nil)
 			},
@@ -650,7 +905,7 @@ func TestDeleteTodo(t *testing.T) {
 					Times(0)
 
 				store.EXPECT().
-					DeleteTodo(gomock.Any(), gomock.Any()).
+					DeleteTodoTx(gomock.Any(), gomock.Any()).
 					Times(0).
 					Return(nil)
 			},
@@ -658,6 +913,27 @@ func TestDeleteTodo(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name:   "WrongUser",
+			todoID: todo.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomEmail(), time.Minute)
+				request.Header.Set("If-Match", `"3"`)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(db.GetTodoRow{ID: todo.ID, UserEmail: todo.UserEmail, Version: 4}, nil)
+
+				store.EXPECT().
+					DeleteTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 		{
 			name:   "NotFound",
 			todoID: 999,
@@ -670,7 +946,7 @@ func TestDeleteTodo(t *testing.T) {
 					Return(db.GetTodoRow{}, sql.ErrNoRows)
 
 				store.EXPECT().
-					DeleteTodo(gomock.Any(), gomock.Any()).
+					DeleteTodoTx(gomock.Any(), gomock.Any()).
 					Times(0)
 
 			},
@@ -678,86 +954,232 @@ func TestDeleteTodo(t *testing.T) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
-	}
-
-	for i := range testCases {
-		tc := testCases[i]
-
-		t.Run(tc.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-
-			store := mockdb.NewMockStore(ctrl)
-			tc.buildStubs(store)
-
-			server := newTestServer(t, store)
-			recorder := httptest.NewRecorder()
-
-			url := fmt.Sprintf("/todo/%d", tc.todoID)
-			request, err := http.NewRequest(http.MethodDelete, url, nil)
-			require.NoError(t, err)
-
-			tc.setupAuth(t, request, server.tokenMaker)
-			server.router.ServeHTTP(recorder, request)
-			tc.checkResponse(t, recorder)
-		})
-	}
-}
-
-func TestUpdateTodo(t *testing.T) {
-	todo := randomTodo(t)
-	todo2 := randomTodo(t)
-
-	resp := db.Todo{
-		ID:         todo.ID,
-		CategoryID: todo2.CategoryID,
-		Title:      todo2.Content,
-		Content:    todo2.Content,
-		Date:       todo2.Date,
-		Color:      todo2.Color,
-		IsPriority: todo2.IsPriority,
-	}
-
-	testCases := []struct {
-		name          string
-		body          gin.H
-		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
-		buildStubs    func(store *mockdb.MockStore)
-		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
-	}{
 		{
-			name: "OK",
-			body: gin.H{
-				"todo_id":     todo.ID,
-				"category_id": todo2.CategoryID,
-				"title":       todo2.Title,
-				"content":     todo2.Content,
-				"date":        "2020-01-01",
-				"color":       todo2.Color,
-				"is_priority": todo2.IsPriority,
-			},
+			name:   "IfMatch",
+			todoID: todo.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+				request.Header.Set("If-Match", `"4"`)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.UpdateTodoByUserParams{
-					ID:         todo.ID,
-					CategoryID: todo2.CategoryID,
-					Title:      todo2.Title,
-					Content:    todo2.Content,
-					Date:       todo2.Date,
-					Color:      todo2.Color,
-					IsPriority: todo2.IsPriority,
-				}
-
 				store.EXPECT().
 					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
-					Times(1)
+					Times(1).
+					Return(db.GetTodoRow{ID: todo.ID, UserEmail: todo.UserEmail, Version: 4}, nil)
 
+				arg := db.DeleteTodoTxParams{
+					ID:        todo.ID,
+					UserEmail: todo.UserEmail,
+					Version:   4,
+				}
 				store.EXPECT().
-					UpdateTodoByUser(gomock.Any(), gomock.Eq(arg)).
+					DeleteTodoTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(resp, nil)
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "StaleIfMatch",
+			todoID: todo.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+				request.Header.Set("If-Match", `"3"`)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(db.GetTodoRow{ID: todo.ID, UserEmail: todo.UserEmail, Version: 4}, nil)
+
+				store.EXPECT().
+					DeleteTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:   "ChangedConcurrently",
+			todoID: todo.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+				request.Header.Set("If-Match", `"4"`)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(db.GetTodoRow{ID: todo.ID, UserEmail: todo.UserEmail, Version: 4}, nil)
+
+				store.EXPECT().
+					DeleteTodoTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ErrVersionMismatch)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:   "IfMatchList",
+			todoID: todo.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+				request.Header.Set("If-Match", `"3", "4"`)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(db.GetTodoRow{ID: todo.ID, UserEmail: todo.UserEmail, Version: 4}, nil)
+
+				arg := db.DeleteTodoTxParams{
+					ID:        todo.ID,
+					UserEmail: todo.UserEmail,
+					Version:   4,
+				}
+				store.EXPECT().
+					DeleteTodoTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "WeakInIfMatchList",
+			todoID: todo.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+				request.Header.Set("If-Match", `W/"4", "3"`)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(db.GetTodoRow{ID: todo.ID, UserEmail: todo.UserEmail, Version: 4}, nil)
+
+				store.EXPECT().
+					DeleteTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:   "WeakIfMatch",
+			todoID: todo.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+				request.Header.Set("If-Match", `W/"4"`)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					DeleteTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/todo/%d", tc.todoID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateTodo(t *testing.T) {
+	todo := randomTodo(t)
+	todo2 := randomTodo(t)
+	user := db.User{Email: todo.UserEmail, TimeZone: util.DefaultTimeZone}
+
+	resp := db.Todo{
+		ID:         todo.ID,
+		CategoryID: todo2.CategoryID,
+		Title:      todo2.Content,
+		Content:    todo2.Content,
+		Date:       todo2.Date,
+		Color:      todo2.Color,
+		IsPriority: todo2.IsPriority,
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"todo_id":     todo.ID,
+				"category_id": todo2.CategoryID,
+				"title":       todo2.Title,
+				"content":     todo2.Content,
+				"date":        "2020-01-01",
+				"color":       todo2.Color,
+				"is_priority": todo2.IsPriority,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+
+				arg := db.UpdateTodoByUserParams{
+					ID:         todo.ID,
+					CategoryID: todo2.CategoryID,
+					Title:      todo2.Title,
+					Content:    todo2.Content,
+					Date:       todo2.Date,
+					AllDay:     true,
+					Color:      todo2.Color,
+					IsPriority: todo2.IsPriority,
+				}
+
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(db.GetTodoRow{ID: todo.ID, UserEmail: todo.UserEmail}, nil)
+
+				store.EXPECT().
+					UpdateTodoTx(gomock.Any(), gomock.Eq(db.UpdateTodoTxParams{UpdateTodoByUserParams: arg})).
+					Times(1).
+					Return(db.TodoTxResult{Todo: resp, Tags: []db.Tag{}}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -783,9 +1205,8 @@ func TestUpdateTodo(t *testing.T) {
 					Times(0)
 
 				store.EXPECT().
-					UpdateTodoByUser(gomock.Any(), gomock.Any()).
-					Times(0).
-					Return(resp, nil)
+					UpdateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -811,9 +1232,8 @@ func TestUpdateTodo(t *testing.T) {
 					Times(0)
 
 				store.EXPECT().
-					UpdateTodoByUser(gomock.Any(), gomock.Any()).
-					Times(0).
-					Return(resp, nil)
+					UpdateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -839,9 +1259,8 @@ func TestUpdateTodo(t *testing.T) {
 					Times(0)
 
 				store.EXPECT().
-					UpdateTodoByUser(gomock.Any(), gomock.Any()).
-					Times(0).
-					Return(resp, nil)
+					UpdateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -867,9 +1286,8 @@ func TestUpdateTodo(t *testing.T) {
 					Times(0)
 
 				store.EXPECT().
-					UpdateTodoByUser(gomock.Any(), gomock.Any()).
-					Times(0).
-					Return(resp, nil)
+					UpdateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -895,152 +1313,542 @@ func TestUpdateTodo(t *testing.T) {
 					Times(0)
 
 				store.EXPECT().
-					UpdateTodoByUser(gomock.Any(), gomock.Any()).
-					Times(0).
-					Return(resp, nil)
+					UpdateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Date Format",
+			body: gin.H{
+				"todo_id":     todo.ID,
+				"category_id": todo2.CategoryID,
+				"title":       todo2.Title,
+				"content":     todo2.Content,
+				"date":        "invalid-date",
+				"color":       todo2.Color,
+				"is_priority": todo2.IsPriority,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(0)
+
+				store.EXPECT().
+					UpdateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Date",
+			body: gin.H{
+				"todo_id":     todo.ID,
+				"category_id": todo2.CategoryID,
+				"title":       todo2.Title,
+				"content":     todo2.Content,
+				"date":        "",
+				"color":       todo2.Color,
+				"is_priority": todo2.IsPriority,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(0)
+
+				store.EXPECT().
+					UpdateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Color",
+			body: gin.H{
+				"todo_id":     todo.ID,
+				"category_id": todo2.CategoryID,
+				"title":       todo2.Title,
+				"content":     todo2.Content,
+				"date":        "2020-01-01",
+				"color":       "",
+				"is_priority": todo2.IsPriority,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(0)
+
+				store.EXPECT().
+					UpdateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid IsPriority",
+			body: gin.H{
+				"todo_id":     todo.ID,
+				"category_id": todo2.CategoryID,
+				"title":       todo2.Title,
+				"content":     todo2.Content,
+				"date":        "2020-01-01",
+				"color":       todo2.Color,
+				"is_priority": "",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(0)
+
+				store.EXPECT().
+					UpdateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "WrongUser",
+			body: gin.H{
+				"todo_id":     todo.ID,
+				"category_id": todo2.CategoryID,
+				"title":       todo2.Title,
+				"content":     todo2.Content,
+				"date":        "2020-01-01",
+				"color":       todo2.Color,
+				"is_priority": todo2.IsPriority,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo2.UserEmail, time.Minute)
+				request.Header.Set("If-Match", `"1"`)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(todo2.UserEmail)).
+					Times(1).
+					Return(db.User{Email: todo2.UserEmail, TimeZone: util.DefaultTimeZone}, nil)
+
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(db.GetTodoRow{ID: todo.ID, UserEmail: todo.UserEmail, Version: 2}, nil)
+
+				store.EXPECT().
+					UpdateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Todo NotFound",
+			body: gin.H{
+				"todo_id":     999,
+				"category_id": todo2.CategoryID,
+				"title":       todo2.Title,
+				"content":     todo2.Content,
+				"date":        "2020-01-01",
+				"color":       todo2.Color,
+				"is_priority": todo2.IsPriority,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Return(db.GetTodoRow{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					UpdateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "ConcurrentUpdate",
+			body: gin.H{
+				"todo_id":     todo.ID,
+				"category_id": todo2.CategoryID,
+				"title":       todo2.Title,
+				"content":     todo2.Content,
+				"date":        "2020-01-01",
+				"color":       todo2.Color,
+				"is_priority": todo2.IsPriority,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+				request.Header.Set("If-Match", `"2"`)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(db.GetTodoRow{ID: todo.ID, UserEmail: todo.UserEmail, Version: 2}, nil)
+
+				// another client wrote between the check and the update
+				store.EXPECT().
+					UpdateTodoTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Do(func(_ context.Context, arg db.UpdateTodoTxParams) {
+						require.Equal(t, int32(2), arg.Version)
+					}).
+					Return(db.TodoTxResult{}, db.ErrVersionMismatch)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name: "StaleIfMatch",
+			body: gin.H{
+				"todo_id":     todo.ID,
+				"category_id": todo2.CategoryID,
+				"title":       todo2.Title,
+				"content":     todo2.Content,
+				"date":        "2020-01-01",
+				"color":       todo2.Color,
+				"is_priority": todo2.IsPriority,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+				request.Header.Set("If-Match", `"1"`)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(db.GetTodoRow{ID: todo.ID, UserEmail: todo.UserEmail, Version: 2}, nil)
+
+				store.EXPECT().
+					UpdateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/todo")
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestPatchTodo(t *testing.T) {
+	todo := randomTodo(t)
+	user := db.User{Email: todo.UserEmail, TimeZone: "Asia/Jakarta"}
+	row := db.GetTodoRow{ID: todo.ID, UserEmail: todo.UserEmail, CategoryID: todo.CategoryID}
+
+	testCases := []struct {
+		name          string
+		body          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OnlyTitle",
+			body: `{"title": "new title"}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.PatchTodoTxParams{
+					PatchTodoParams: db.PatchTodoParams{
+						ID:    todo.ID,
+						Title: sql.NullString{String: "new title", Valid: true},
+					},
+				}
+				patched := todo
+				patched.Title = "new title"
+
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(row, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					PatchTodoTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TodoTxResult{Todo: patched, Tags: []db.Tag{}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got TodoResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, "new title", got.Title)
+				require.Equal(t, todo.Content, got.Content)
+			},
+		},
+		{
+			name: "DateCategoryAndTags",
+			body: `{"date": "2020-01-01T09:30", "category_id": 7, "is_priority": false, "tag_ids": [3]}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.PatchTodoTxParams{
+					PatchTodoParams: db.PatchTodoParams{
+						ID:         todo.ID,
+						CategoryID: sql.NullInt32{Int32: 7, Valid: true},
+						// 09:30 in Jakarta
+						Date:       sql.NullTime{Time: time.Date(2020, 1, 1, 2, 30, 0, 0, time.UTC), Valid: true},
+						AllDay:     sql.NullBool{Bool: false, Valid: true},
+						IsPriority: sql.NullBool{Bool: false, Valid: true},
+					},
+					TagIDs: []int32{3},
+				}
+
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(row, nil)
+				store.EXPECT().
+					GetCategory(gomock.Any(), gomock.Eq(int32(7))).
+					Times(1).
+					Return(db.Category{ID: 7}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(todo.UserEmail)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					PatchTodoTx(gomock.Any(), eqPatchTodoTxParams{arg}).
+					Times(1).
+					Return(db.TodoTxResult{Todo: todo}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NullTagIDsClearsTags",
+			body: `{"tag_ids": null}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.PatchTodoTxParams{
+					PatchTodoParams: db.PatchTodoParams{ID: todo.ID},
+					TagIDs:          []int32{},
+				}
+
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(row, nil)
+				store.EXPECT().
+					PatchTodoTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TodoTxResult{Todo: todo, Tags: []db.Tag{}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NullTitle",
+			body: `{"title": null}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PatchTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "title-cannot-be-null")
+			},
+		},
+		{
+			name: "EmptyTitle",
+			body: `{"title": ""}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PatchTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
-			name: "Invalid Date Format",
-			body: gin.H{
-				"todo_id":     todo.ID,
-				"category_id": todo2.CategoryID,
-				"title":       todo2.Title,
-				"content":     todo2.Content,
-				"date":        "invalid-date",
-				"color":       todo2.Color,
-				"is_priority": todo2.IsPriority,
-			},
+			name: "InvalidDate",
+			body: `{"date": "tomorrow"}`,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
-					Times(0)
-
+					Times(1).
+					Return(row, nil)
 				store.EXPECT().
-					UpdateTodoByUser(gomock.Any(), gomock.Any()).
-					Times(0).
-					Return(resp, nil)
+					GetUser(gomock.Any(), gomock.Eq(todo.UserEmail)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					PatchTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
-			name: "Invalid Date",
-			body: gin.H{
-				"todo_id":     todo.ID,
-				"category_id": todo2.CategoryID,
-				"title":       todo2.Title,
-				"content":     todo2.Content,
-				"date":        "",
-				"color":       todo2.Color,
-				"is_priority": todo2.IsPriority,
-			},
+			name: "InvalidCategory",
+			body: `{"category_id": 7}`,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
-					Times(0)
-
+					Times(1).
+					Return(row, nil)
 				store.EXPECT().
-					UpdateTodoByUser(gomock.Any(), gomock.Any()).
-					Times(0).
-					Return(resp, nil)
+					GetCategory(gomock.Any(), gomock.Eq(int32(7))).
+					Times(1).
+					Return(db.Category{}, sql.ErrNoRows)
+				store.EXPECT().
+					PatchTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
 		{
-			name: "Invalid Color",
-			body: gin.H{
-				"todo_id":     todo.ID,
-				"category_id": todo2.CategoryID,
-				"title":       todo2.Title,
-				"content":     todo2.Content,
-				"date":        "2020-01-01",
-				"color":       "",
-				"is_priority": todo2.IsPriority,
-			},
+			name: "InvalidTags",
+			body: `{"tag_ids": [3]}`,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
-					Times(0)
-
+					Times(1).
+					Return(row, nil)
 				store.EXPECT().
-					UpdateTodoByUser(gomock.Any(), gomock.Any()).
-					Times(0).
-					Return(resp, nil)
+					PatchTodoTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TodoTxResult{}, db.ErrInvalidTags)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
-			name: "Invalid IsPriority",
-			body: gin.H{
-				"todo_id":     todo.ID,
-				"category_id": todo2.CategoryID,
-				"title":       todo2.Title,
-				"content":     todo2.Content,
-				"date":        "2020-01-01",
-				"color":       todo2.Color,
-				"is_priority": "",
-			},
+			name: "NotFound",
+			body: `{"title": "new title"}`,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
-					Times(0)
-
+					Times(1).
+					Return(db.GetTodoRow{}, sql.ErrNoRows)
 				store.EXPECT().
-					UpdateTodoByUser(gomock.Any(), gomock.Any()).
-					Times(0).
-					Return(resp, nil)
+					PatchTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
 		{
-			name: "Todo NotFound",
-			body: gin.H{
-				"todo_id":     999,
-				"category_id": todo2.CategoryID,
-				"title":       todo2.Title,
-				"content":     todo2.Content,
-				"date":        "2020-01-01",
-				"color":       todo2.Color,
-				"is_priority": todo2.IsPriority,
-			},
+			name: "WrongUser",
+			body: `{"title": "new title"}`,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "someone@else.com", time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetTodo(gomock.Any(), gomock.Any()).
-					Return(db.GetTodoRow{}, sql.ErrNoRows)
-
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(row, nil)
 				store.EXPECT().
-					UpdateTodoByUser(gomock.Any(), gomock.Any()).
-					Times(0).
-					Return(resp, nil)
+					PatchTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
 	}
@@ -1058,11 +1866,8 @@ func TestUpdateTodo(t *testing.T) {
 			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
 
-			data, err := json.Marshal(tc.body)
-			require.NoError(t, err)
-
-			url := fmt.Sprintf("/todo")
-			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			url := fmt.Sprintf("/todo/%d", todo.ID)
+			request, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader([]byte(tc.body)))
 			require.NoError(t, err)
 
 			tc.setupAuth(t, request, server.tokenMaker)
@@ -1072,6 +1877,26 @@ func TestUpdateTodo(t *testing.T) {
 	}
 }
 
+// eqPatchTodoTxParams compares dates by instant, since they are parsed in
+// the user's zone.
+type eqPatchTodoTxParams struct {
+	arg db.PatchTodoTxParams
+}
+
+func (e eqPatchTodoTxParams) Matches(x interface{}) bool {
+	arg, ok := x.(db.PatchTodoTxParams)
+	if !ok || !arg.Date.Time.Equal(e.arg.Date.Time) {
+		return false
+	}
+
+	arg.Date.Time = e.arg.Date.Time
+	return fmt.Sprint(e.arg) == fmt.Sprint(arg)
+}
+
+func (e eqPatchTodoTxParams) String() string {
+	return fmt.Sprintf("matches arg %v", e.arg)
+}
+
 func TestMarkAsCompleteTodo(t *testing.T) {
 	todo := randomTodo(t)
 	todo2 := randomTodo(t)
@@ -1103,10 +1928,11 @@ func TestMarkAsCompleteTodo(t *testing.T) {
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
-					Times(1)
+					Times(1).
+					Return(db.GetTodoRow{ID: todo.ID, UserEmail: todo.UserEmail}, nil)
 
 				store.EXPECT().
-					MarkAsCompleteTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					CompleteTodoTx(gomock.Any(), gomock.Eq(db.CompleteTodoTxParams{ID: todo.ID})).
 					Times(1).
 					Return(resp, nil)
 			},
@@ -1133,7 +1959,7 @@ func TestMarkAsCompleteTodo(t *testing.T) {
 					Times(0)
 
 				store.EXPECT().
-					MarkAsCompleteTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					CompleteTodoTx(gomock.Any(), gomock.Eq(db.CompleteTodoTxParams{ID: todo.ID})).
 					Times(0).
 					Return(resp, nil)
 			},
@@ -1141,6 +1967,27 @@ func TestMarkAsCompleteTodo(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name:    "WrongUser",
+			todo_id: todo.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo2.UserEmail, time.Minute)
+				request.Header.Set("If-Match", `"1"`)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(db.GetTodoRow{ID: todo.ID, UserEmail: todo.UserEmail, Version: 2}, nil)
+
+				store.EXPECT().
+					CompleteTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 		{
 			name:    "Todo NotFound",
 			todo_id: 999,
@@ -1153,14 +2000,59 @@ func TestMarkAsCompleteTodo(t *testing.T) {
 					Return(db.GetTodoRow{}, sql.ErrNoRows)
 
 				store.EXPECT().
-					UpdateTodoByUser(gomock.Any(), gomock.Any()).
-					Times(0).
-					Return(resp, nil)
+					UpdateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
+		{
+			name:    "IfMatch",
+			todo_id: todo.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+				request.Header.Set("If-Match", `"2"`)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(db.GetTodoRow{ID: todo.ID, UserEmail: todo.UserEmail, Version: 2}, nil)
+
+				completed := resp
+				completed.Version = 3
+				store.EXPECT().
+					CompleteTodoTx(gomock.Any(), gomock.Eq(db.CompleteTodoTxParams{ID: todo.ID, Version: 2})).
+					Times(1).
+					Return(completed, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `"3"`, recorder.Header().Get("ETag"))
+			},
+		},
+		{
+			name:    "StaleIfMatch",
+			todo_id: todo.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+				request.Header.Set("If-Match", `"1"`)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					Times(1).
+					Return(db.GetTodoRow{ID: todo.ID, UserEmail: todo.UserEmail, Version: 2}, nil)
+
+				store.EXPECT().
+					CompleteTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -1258,7 +2150,112 @@ func randomTodoWithExistingUser(t *testing.T, userEmail string) db.ListTodoByUse
 		IsPriority:   false,
 		UserEmail:    userEmail,
 		CategoryName: category.Name,
+		Tags:         json.RawMessage(`[]`),
 	}
 
 	return todo
 }
+
+func TestRestoreTodo(t *testing.T) {
+	todo := randomTodo(t)
+
+	testCases := []struct {
+		name          string
+		todoID        int32
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			todoID: todo.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.RestoreTodoParams{
+					ID:        todo.ID,
+					UserEmail: todo.UserEmail,
+				}
+
+				store.EXPECT().
+					RestoreTodo(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(todo, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchTodo(t, recorder.Body, todo)
+			},
+		},
+		{
+			name:   "Unauthorized",
+			todoID: todo.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RestoreTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			todoID: 999,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RestoreTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Todo{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError",
+			todoID: todo.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, todo.UserEmail, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RestoreTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Todo{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/todo/%d/restore", tc.todoID)
+			request, err := http.NewRequest(http.MethodPut, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/token"
+)
+
+func (server *Server) listTrash(ctx *gin.Context) {
+	var req ListTrashRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	var resp ListTrashResponse
+
+	argTodo := db.ListTrashedTodoParams{
+		UserEmail: authPayload.Username,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	}
+	todos, err := server.store.ListTrashedTodo(ctx, argTodo)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	resp.Todos = todos
+
+	argCategory := db.ListTrashedCategoriesParams{
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+	categories, err := server.store.ListTrashedCategories(ctx, argCategory)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	resp.Categories = categories
+
+	ctx.JSON(http.StatusOK, resp)
+}
@@ -6,7 +6,7 @@ import (
 	"errors"
 	"log"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/maslow123/todoapp-services/db/sqlc"
@@ -16,23 +16,32 @@ import (
 
 func newUserResponse(user db.User) GenericUserResponse {
 	return GenericUserResponse{
-		Name:    user.Name,
-		Address: user.Address,
-		Pic:     user.Pic,
-		Email:   user.Email,
+		Name:     user.Name,
+		Address:  user.Address,
+		Pic:      user.Pic,
+		Email:    user.Email,
+		TimeZone: user.TimeZone,
 	}
 }
 
 func (server *Server) createUser(ctx *gin.Context) {
 	var req CreateUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.TimeZone == "" {
+		req.TimeZone = util.DefaultTimeZone
+	}
+	if _, err := util.LoadTimeZone(req.TimeZone); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	hashedPassword, err := util.HashPassword(req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -42,16 +51,17 @@ func (server *Server) createUser(ctx *gin.Context) {
 		Pic:            req.Pic,
 		HashedPassword: hashedPassword,
 		Email:          req.Email,
+		TimeZone:       req.TimeZone,
 	}
 
 	user, err := server.store.CreateUser(ctx, arg)
 	if err != nil {
 		log.Println(err)
-		if strings.Contains(err.Error(), "pq: duplicate key") {
-			ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("email-already-exists")))
+		if db.ErrorCode(err) == db.UniqueViolation {
+			abortWithError(ctx, http.StatusConflict, errors.New("email-already-exists"))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -63,23 +73,23 @@ func (server *Server) loginUser(ctx *gin.Context) {
 	var req LoginUserRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	user, err := server.store.GetUser(ctx, req.Email)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("invalid-password")))
+			abortWithError(ctx, http.StatusNotFound, errors.New("invalid-password"))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
 	err = util.CheckPassword(req.Password, user.HashedPassword)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(errors.New("invalid-password")))
+		abortWithError(ctx, http.StatusUnauthorized, errors.New("invalid-password"))
 		return
 	}
 
@@ -88,12 +98,13 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
 	}
 
 	response := LoginUserResponse{
 		AccessToken: accessToken,
-		User:        user,
+		User:        newUserResponse(user),
 	}
 
 	ctx.JSON(http.StatusOK, response)
@@ -102,7 +113,7 @@ func (server *Server) loginUser(ctx *gin.Context) {
 func (server *Server) me(ctx *gin.Context) {
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	if authPayload == nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(errors.New("Unauthorized")))
+		abortWithError(ctx, http.StatusUnauthorized, errors.New("unauthorized"))
 		return
 	}
 
@@ -110,12 +121,60 @@ func (server *Server) me(ctx *gin.Context) {
 	if err != nil {
 		log.Println(err)
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusUnauthorized, errors.New("invalid-user"))
+			abortWithError(ctx, http.StatusUnauthorized, errors.New("invalid-user"))
 			return
 		}
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		abortWithError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, authPayload)
 }
+
+func (server *Server) updateTimeZone(ctx *gin.Context) {
+	var req UpdateTimeZoneRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := util.LoadTimeZone(req.TimeZone); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.UpdateUserTimeZoneParams{
+		Email:    authPayload.Username,
+		TimeZone: req.TimeZone,
+	}
+
+	user, err := server.store.UpdateUserTimeZone(ctx, arg)
+	if err != nil {
+		log.Println(err)
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, http.StatusNotFound, errors.New("invalid-user"))
+			return
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := newUserResponse(user)
+	ctx.JSON(http.StatusOK, &resp)
+}
+
+// userLocation returns the time zone the user's dates are read and bucketed in.
+func (server *Server) userLocation(ctx context.Context, email string) (*time.Location, error) {
+	user, err := server.store.GetUser(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := util.LoadTimeZone(user.TimeZone)
+	if err != nil {
+		// a zone that is no longer known should not block the user
+		return time.UTC, nil
+	}
+	return loc, nil
+}
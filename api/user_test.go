@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -14,7 +15,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	mockdb "github.com/maslow123/todoapp-services/db/mock"
 	db "github.com/maslow123/todoapp-services/db/sqlc"
 	"github.com/maslow123/todoapp-services/token"
@@ -70,10 +71,11 @@ func TestCreateUserAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				arg := db.CreateUserParams{
-					Name:    user.Name,
-					Address: user.Address,
-					Pic:     user.Pic,
-					Email:   user.Email,
+					Name:     user.Name,
+					Address:  user.Address,
+					Pic:      user.Pic,
+					Email:    user.Email,
+					TimeZone: util.DefaultTimeZone,
 				}
 
 				store.EXPECT().
@@ -87,6 +89,26 @@ func TestCreateUserAPI(t *testing.T) {
 				requireBodyMatchUser(t, recorder.Body, user)
 			},
 		},
+		{
+			name: "DuplicateEmail",
+			body: gin.H{
+				"name":     user.Name,
+				"address":  user.Address,
+				"pic":      user.Pic,
+				"password": password,
+				"email":    user.Email,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, &pq.Error{Code: db.UniqueViolation})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder.Body, "email-already-exists")
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -118,6 +140,7 @@ func TestCreateUserAPI(t *testing.T) {
 
 func TestLoginUserAPI(t *testing.T) {
 	user, password := randomUser(t)
+	user.CalendarToken = sql.NullString{String: util.RandomString(48), Valid: true}
 
 	testCases := []struct {
 		name          string
@@ -139,6 +162,15 @@ func TestLoginUserAPI(t *testing.T) {
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got struct {
+					User map[string]interface{} `json:"user"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, user.Email, got.User["email"])
+				require.NotContains(t, got.User, "hashed_password")
+				require.NotContains(t, got.User, "calendar_token")
 			},
 		},
 		{
@@ -298,6 +330,154 @@ func TestMe(t *testing.T) {
 	}
 
 }
+func TestUpdateTimeZoneAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"time_zone": "Asia/Jakarta",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateUserTimeZoneParams{
+					Email:    user.Email,
+					TimeZone: "Asia/Jakarta",
+				}
+
+				updated := user
+				updated.TimeZone = arg.TimeZone
+				store.EXPECT().
+					UpdateUserTimeZone(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotUser GenericUserResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotUser)
+				require.NoError(t, err)
+				require.Equal(t, "Asia/Jakarta", gotUser.TimeZone)
+			},
+		},
+		{
+			name: "InvalidTimeZone",
+			body: gin.H{
+				"time_zone": "Mars/Olympus_Mons",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserTimeZone(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "LocalTimeZone",
+			body: gin.H{
+				"time_zone": "Local",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserTimeZone(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{
+				"time_zone": "UTC",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserTimeZone(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/users/me/time_zone"
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Email, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+// failingMaker can't sign tokens.
+type failingMaker struct {
+	token.Maker
+}
+
+func (failingMaker) CreateToken(username string, duration time.Duration) (string, error) {
+	return "", errors.New("cannot-sign")
+}
+
+func TestLoginUserTokenError(t *testing.T) {
+	user, password := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Email)).
+		Times(1).
+		Return(user, nil)
+
+	server := newTestServer(t, store)
+	server.tokenMaker = failingMaker{server.tokenMaker}
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{"email": user.Email, "password": password})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/v1/users/login", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	// only the problem is written, not a login response after it
+	var problem map[string]interface{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	require.NotContains(t, problem, "access_token")
+}
+
 func randomUser(t *testing.T) (user db.User, password string) {
 	password = util.RandomString(6)
 	hashedPassword, err := util.HashPassword(password)
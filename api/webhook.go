@@ -0,0 +1,168 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/token"
+	"github.com/maslow123/todoapp-services/util"
+)
+
+func newWebhookResponse(webhook db.Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.Url,
+		Events:    webhook.Events,
+		Active:    webhook.Active,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func (server *Server) createWebhook(ctx *gin.Context) {
+	var req CreateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if !server.isWebhookURL(req.URL) {
+		abortWithError(ctx, http.StatusBadRequest, errors.New("invalid-url"))
+		return
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.CreateWebhookParams{
+		UserEmail: authPayload.Username,
+		Url:       req.URL,
+		Secret:    secret,
+		Events:    req.Events,
+	}
+
+	webhook, err := server.store.CreateWebhook(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	// the secret is only shown once, when the webhook is created
+	resp := newWebhookResponse(webhook)
+	resp.Secret = webhook.Secret
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (server *Server) listWebhooks(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	webhooks, err := server.store.ListWebhooks(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := make([]WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		resp = append(resp, newWebhookResponse(webhook))
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (server *Server) deleteWebhook(ctx *gin.Context) {
+	var uri WebhookURIRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.DeleteWebhookParams{
+		ID:        uri.WebhookID,
+		UserEmail: authPayload.Username,
+	}
+
+	rows, err := server.store.DeleteWebhook(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if rows == 0 {
+		abortWithError(ctx, http.StatusNotFound, errors.New("webhook-not-found"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, "OK")
+}
+
+func (server *Server) listWebhookDeliveries(ctx *gin.Context) {
+	var uri WebhookURIRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req ListWebhookDeliveryRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.ListWebhookDeliveriesParams{
+		WebhookID: uri.WebhookID,
+		UserEmail: authPayload.Username,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	}
+
+	deliveries, err := server.store.ListWebhookDeliveries(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deliveries)
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// isWebhookURL rejects targets that are plainly internal up front. Hostnames
+// can still resolve anywhere, so the worker checks the address it dials too.
+func (server *Server) isWebhookURL(target string) bool {
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return false
+	}
+	if server.config.WebhookAllowPrivateTargets {
+		return true
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return util.IsPublicIP(ip)
+	}
+	return true
+}
@@ -0,0 +1,223 @@
+// Package apierror is the error model of the HTTP API. Every error response
+// is an RFC 7807 problem with a stable, machine-readable code.
+package apierror
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const ContentType = "application/problem+json"
+
+// Code identifies an error for clients. Codes never change once published.
+type Code string
+
+const (
+	CodeInvalidRequest     Code = "invalid-request"
+	CodeInvalidRequestBody Code = "invalid-request-body"
+	CodeValidationFailed   Code = "validation-failed"
+	CodeUnauthorized       Code = "unauthorized"
+	CodeForbidden          Code = "forbidden"
+	CodeNotFound           Code = "not-found"
+	CodeMethodNotAllowed   Code = "method-not-allowed"
+	CodeConflict           Code = "conflict"
+	CodePreconditionFailed Code = "precondition-failed"
+	CodeUnprocessable      Code = "unprocessable-entity"
+	CodeTooManyRequests    Code = "too-many-requests"
+	CodeInternal           Code = "internal-error"
+	CodeUnavailable        Code = "service-unavailable"
+
+	CodeAlreadyExists       Code = "already-exists"
+	CodeInvalidReference    Code = "invalid-reference"
+	CodeConstraintViolation Code = "constraint-violation"
+	CodeValueTooLong        Code = "value-too-long"
+	CodeRetryRequest        Code = "retry-request"
+)
+
+var statusCodes = map[int]Code{
+	http.StatusBadRequest:            CodeInvalidRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusMethodNotAllowed:      CodeMethodNotAllowed,
+	http.StatusConflict:              CodeConflict,
+	http.StatusPreconditionFailed:    CodePreconditionFailed,
+	http.StatusUnprocessableEntity:   CodeUnprocessable,
+	http.StatusTooManyRequests:       CodeTooManyRequests,
+	http.StatusInternalServerError:   CodeInternal,
+	http.StatusServiceUnavailable:    CodeUnavailable,
+	http.StatusRequestEntityTooLarge: CodeInvalidRequest,
+}
+
+// StatusCode gives the generic code for an HTTP status.
+func StatusCode(status int) Code {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeInvalidRequest
+}
+
+// FieldError describes one invalid request field.
+type FieldError struct {
+	Field string `json:"field"`
+	Code  string `json:"code"`
+	Param string `json:"param,omitempty"`
+}
+
+// Error is an API error. Err is the underlying cause; it is logged but
+// never sent to clients.
+type Error struct {
+	Status int
+	Code   Code
+	Detail string
+	Fields []FieldError
+	// Extra is added to the problem as extension members.
+	Extra map[string]interface{}
+	Err   error
+}
+
+func New(status int, code Code, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+func (e *Error) Error() string {
+	if e.Detail != "" {
+		return string(e.Code) + ": " + e.Detail
+	}
+	return string(e.Code)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// With returns a copy of e with an extension member added.
+func (e *Error) With(key string, value interface{}) *Error {
+	copied := *e
+	copied.Extra = make(map[string]interface{}, len(e.Extra)+1)
+	for k, v := range e.Extra {
+		copied.Extra[k] = v
+	}
+	copied.Extra[key] = value
+	return &copied
+}
+
+// DetailUnknown replaces the message of a client error that has no code of
+// its own, since it may come from a parser or the database driver.
+const DetailUnknown = "request-could-not-be-processed"
+
+// errors.New("todo-not-found") style sentinels double as codes
+var codePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// From turns err, returned by a handler with status, into an API error.
+// Kebab-case errors are used as the code. Validation and JSON errors get
+// field details. Any other cause is never exposed; callers log it.
+func From(status int, err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	e := &Error{Status: status, Code: StatusCode(status), Err: err}
+	if err == nil || status >= http.StatusInternalServerError {
+		return e
+	}
+
+	var validationErrs validator.ValidationErrors
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &validationErrs):
+		e.Code = CodeValidationFailed
+		e.Detail = "request-has-invalid-fields"
+		for _, fieldErr := range validationErrs {
+			e.Fields = append(e.Fields, FieldError{
+				Field: fieldName(fieldErr),
+				Code:  fieldErr.Tag(),
+				Param: fieldErr.Param(),
+			})
+		}
+	case errors.As(err, &typeErr):
+		e.Code = CodeInvalidRequestBody
+		e.Fields = []FieldError{{Field: typeErr.Field, Code: "type", Param: typeErr.Type.String()}}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		e.Code = CodeInvalidRequestBody
+	case err == sql.ErrNoRows:
+		e.Code = CodeNotFound
+	case codePattern.MatchString(err.Error()):
+		e.Code = Code(err.Error())
+	default:
+		e.Detail = DetailUnknown
+	}
+	return e
+}
+
+// fieldName drops the request struct name from the namespace, so
+// "CreateTodoRequest.title" becomes "title".
+func fieldName(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fieldErr.Field()
+}
+
+// Problem is an application/problem+json body.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      Code         `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	// Error repeats the code for clients of the old {"error": ...} body.
+	Error string                 `json:"error"`
+	Extra map[string]interface{} `json:"-"`
+}
+
+// Problem renders e for the request at instance.
+func (e *Error) Problem(instance, requestID string) Problem {
+	return Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(e.Status),
+		Status:    e.Status,
+		Detail:    e.Detail,
+		Instance:  instance,
+		Code:      e.Code,
+		RequestID: requestID,
+		Errors:    e.Fields,
+		Error:     string(e.Code),
+		Extra:     e.Extra,
+	}
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	data, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extra) == 0 {
+		return data, err
+	}
+
+	var members map[string]interface{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	for key, value := range p.Extra {
+		if _, ok := members[key]; !ok {
+			members[key] = value
+		}
+	}
+	return json.Marshal(members)
+}
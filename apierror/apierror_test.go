@@ -0,0 +1,128 @@
+package apierror
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrom(t *testing.T) {
+	type request struct {
+		Title string `json:"title" validate:"required"`
+		Limit int    `json:"limit" validate:"min=1"`
+	}
+	validationErr := validator.New().Struct(request{})
+
+	var typeErr error = &json.UnmarshalTypeError{Field: "limit", Type: reflect.TypeOf(0)}
+
+	testCases := []struct {
+		name   string
+		status int
+		err    error
+		check  func(e *Error)
+	}{
+		{
+			name:   "Sentinel",
+			status: http.StatusNotFound,
+			err:    errors.New("todo-not-found"),
+			check: func(e *Error) {
+				require.Equal(t, http.StatusNotFound, e.Status)
+				require.Equal(t, Code("todo-not-found"), e.Code)
+				require.Empty(t, e.Detail)
+			},
+		},
+		{
+			name:   "PlainMessage",
+			status: http.StatusBadRequest,
+			err:    errors.New("strconv.Atoi: parsing \"x\": invalid syntax"),
+			check: func(e *Error) {
+				require.Equal(t, CodeInvalidRequest, e.Code)
+				require.Equal(t, DetailUnknown, e.Detail)
+				require.EqualError(t, e.Err, "strconv.Atoi: parsing \"x\": invalid syntax")
+			},
+		},
+		{
+			name:   "Validation",
+			status: http.StatusBadRequest,
+			err:    validationErr,
+			check: func(e *Error) {
+				require.Equal(t, CodeValidationFailed, e.Code)
+				require.Equal(t, []FieldError{
+					{Field: "Title", Code: "required"},
+					{Field: "Limit", Code: "min", Param: "1"},
+				}, e.Fields)
+			},
+		},
+		{
+			name:   "WrongType",
+			status: http.StatusBadRequest,
+			err:    typeErr,
+			check: func(e *Error) {
+				require.Equal(t, CodeInvalidRequestBody, e.Code)
+				require.Equal(t, []FieldError{{Field: "limit", Code: "type", Param: "int"}}, e.Fields)
+			},
+		},
+		{
+			name:   "NoRows",
+			status: http.StatusNotFound,
+			err:    sql.ErrNoRows,
+			check: func(e *Error) {
+				require.Equal(t, CodeNotFound, e.Code)
+				require.Empty(t, e.Detail)
+			},
+		},
+		{
+			name:   "InternalErrorHidden",
+			status: http.StatusInternalServerError,
+			err:    errors.New("pq: relation \"todos\" does not exist"),
+			check: func(e *Error) {
+				require.Equal(t, CodeInternal, e.Code)
+				require.Empty(t, e.Detail)
+				require.EqualError(t, e.Err, "pq: relation \"todos\" does not exist")
+			},
+		},
+		{
+			name:   "APIError",
+			status: http.StatusInternalServerError,
+			err:    New(http.StatusConflict, "category-has-todos", ""),
+			check: func(e *Error) {
+				require.Equal(t, http.StatusConflict, e.Status)
+				require.Equal(t, Code("category-has-todos"), e.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			tc.check(From(tc.status, tc.err))
+		})
+	}
+}
+
+func TestProblemJSON(t *testing.T) {
+	e := New(http.StatusConflict, "category-has-todos", "").With("todo_count", 4)
+	data, err := json.Marshal(e.Problem("/categories/1", "req-1"))
+	require.NoError(t, err)
+
+	var got map[string]interface{}
+	err = json.Unmarshal(data, &got)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"type":       "about:blank",
+		"title":      "Conflict",
+		"status":     float64(http.StatusConflict),
+		"instance":   "/categories/1",
+		"code":       "category-has-todos",
+		"request_id": "req-1",
+		"error":      "category-has-todos",
+		"todo_count": float64(4),
+	}, got)
+}
@@ -0,0 +1,326 @@
+// Package calendar reads and writes the subset of iCalendar (RFC 5545) used
+// to sync todos with calendar apps.
+package calendar
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ComponentTodo  = "VTODO"
+	ComponentEvent = "VEVENT"
+
+	StatusNeedsAction = "NEEDS-ACTION"
+	StatusCompleted   = "COMPLETED"
+
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+	utcLayout      = "20060102T150405Z"
+	maxLineOctets  = 75
+)
+
+var ErrInvalidCalendar = errors.New("invalid-calendar")
+
+// Item is a VTODO or VEVENT.
+type Item struct {
+	UID         string
+	Summary     string
+	Description string
+	Categories  []string
+	// Priority follows RFC 5545: 0 is undefined, 1 the highest and 9 the lowest.
+	Priority     int
+	Status       string
+	Due          time.Time
+	AllDay       bool
+	Created      time.Time
+	LastModified time.Time
+}
+
+type Calendar struct {
+	Name  string
+	Items []Item
+}
+
+// Encode writes cal with every item as the given component type.
+func Encode(w io.Writer, cal Calendar, component string) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//todoapp-services//todos//EN")
+	line("CALSCALE", "GREGORIAN")
+	if cal.Name != "" {
+		line("X-WR-CALNAME", escapeText(cal.Name))
+	}
+
+	stamp := time.Now().UTC().Format(utcLayout)
+	for _, item := range cal.Items {
+		line("BEGIN", component)
+		line("UID", escapeText(item.UID))
+		line("DTSTAMP", stamp)
+		line("SUMMARY", escapeText(item.Summary))
+		if item.Description != "" {
+			line("DESCRIPTION", escapeText(item.Description))
+		}
+		if len(item.Categories) > 0 {
+			escaped := make([]string, len(item.Categories))
+			for i, category := range item.Categories {
+				escaped[i] = escapeText(category)
+			}
+			line("CATEGORIES", strings.Join(escaped, ","))
+		}
+		if item.Priority > 0 {
+			line("PRIORITY", strconv.Itoa(item.Priority))
+		}
+
+		dueName := "DUE"
+		if component == ComponentEvent {
+			// events need a start; an all-day event lasts the whole date
+			dueName = "DTSTART"
+		}
+		if item.AllDay {
+			writeFolded(bw, dueName+";VALUE=DATE:"+item.Due.Format(dateLayout))
+		} else {
+			line(dueName, item.Due.UTC().Format(utcLayout))
+		}
+
+		if component == ComponentTodo && item.Status != "" {
+			line("STATUS", item.Status)
+		}
+		if !item.Created.IsZero() {
+			line("CREATED", item.Created.UTC().Format(utcLayout))
+		}
+		if !item.LastModified.IsZero() {
+			line("LAST-MODIFIED", item.LastModified.UTC().Format(utcLayout))
+		}
+		line("END", component)
+	}
+
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// writeFolded writes a content line, folding it at 75 octets without
+// splitting UTF-8 sequences.
+func writeFolded(w *bufio.Writer, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		// the leading space of a continuation line counts towards its length
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+func unescapeText(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				sb.WriteByte('\n')
+			default:
+				sb.WriteByte(s[i])
+			}
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// contentLine is a parsed "NAME;PARAM=VALUE:value" line.
+type contentLine struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+func parseLine(raw string) (contentLine, error) {
+	colon := -1
+	quoted := false
+	for i, r := range raw {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return contentLine{}, fmt.Errorf("%w: %q", ErrInvalidCalendar, raw)
+	}
+
+	parts := strings.Split(raw[:colon], ";")
+	line := contentLine{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string),
+		value:  raw[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		if eq := strings.IndexByte(param, '='); eq > 0 {
+			line.params[strings.ToUpper(param[:eq])] = strings.Trim(param[eq+1:], `"`)
+		}
+	}
+	return line, nil
+}
+
+// Decode returns the VTODO items of an iCalendar stream. Values without a zone
+// are read in loc.
+func Decode(r io.Reader, loc *time.Location) ([]Item, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	var current *Item
+	inCalendar := false
+
+	for _, raw := range lines {
+		line, err := parseLine(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case line.name == "BEGIN" && strings.EqualFold(line.value, "VCALENDAR"):
+			inCalendar = true
+		case line.name == "BEGIN" && strings.EqualFold(line.value, ComponentTodo):
+			current = &Item{}
+		case line.name == "END" && strings.EqualFold(line.value, ComponentTodo):
+			if current != nil {
+				items = append(items, *current)
+				current = nil
+			}
+		case current != nil:
+			if err := current.set(line, loc); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !inCalendar {
+		return nil, ErrInvalidCalendar
+	}
+	return items, nil
+}
+
+func (item *Item) set(line contentLine, loc *time.Location) error {
+	var err error
+
+	switch line.name {
+	case "UID":
+		item.UID = unescapeText(line.value)
+	case "SUMMARY":
+		item.Summary = unescapeText(line.value)
+	case "DESCRIPTION":
+		item.Description = unescapeText(line.value)
+	case "CATEGORIES":
+		for _, category := range splitList(line.value) {
+			item.Categories = append(item.Categories, unescapeText(category))
+		}
+	case "PRIORITY":
+		item.Priority, err = strconv.Atoi(line.value)
+	case "STATUS":
+		item.Status = strings.ToUpper(line.value)
+	case "DUE":
+		item.Due, item.AllDay, err = parseTime(line, loc)
+	case "DTSTART":
+		// only used when the todo has no DUE
+		if item.Due.IsZero() {
+			item.Due, item.AllDay, err = parseTime(line, loc)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidCalendar, line.name, err)
+	}
+	return nil
+}
+
+func parseTime(line contentLine, loc *time.Location) (time.Time, bool, error) {
+	if strings.EqualFold(line.params["VALUE"], "DATE") || len(line.value) == len(dateLayout) {
+		t, err := time.ParseInLocation(dateLayout, line.value, loc)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(line.value, "Z") {
+		t, err := time.Parse(utcLayout, line.value)
+		return t, false, err
+	}
+
+	if tzid := line.params["TZID"]; tzid != "" {
+		if tz, err := time.LoadLocation(tzid); err == nil {
+			loc = tz
+		}
+	}
+	t, err := time.ParseInLocation(dateTimeLayout, line.value, loc)
+	return t, false, err
+}
+
+// splitList splits on commas that are not escaped.
+func splitList(s string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == ',' {
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if len(text) > 0 && (text[0] == ' ' || text[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += text[1:]
+			continue
+		}
+		if text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,109 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	require.NoError(t, err)
+
+	items := []Item{
+		{
+			UID:         "todo-1@todoapp",
+			Summary:     "Pay rent; then, relax",
+			Description: "line one\nline two",
+			Categories:  []string{"Home", "Money, mostly"},
+			Priority:    1,
+			Status:      StatusNeedsAction,
+			Due:         time.Date(2020, 1, 1, 0, 0, 0, 0, jakarta),
+			AllDay:      true,
+		},
+		{
+			UID:     "todo-2@todoapp",
+			Summary: strings.Repeat("é", 60),
+			Due:     time.Date(2020, 1, 2, 9, 30, 0, 0, time.UTC),
+		},
+	}
+
+	var buf bytes.Buffer
+	err = Encode(&buf, Calendar{Name: "Todos", Items: items}, ComponentTodo)
+	require.NoError(t, err)
+
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		require.LessOrEqual(t, len(line), maxLineOctets)
+	}
+	require.Contains(t, buf.String(), "DUE;VALUE=DATE:20200101\r\n")
+	require.Contains(t, buf.String(), "DUE:20200102T093000Z\r\n")
+
+	got, err := Decode(&buf, jakarta)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+
+	require.Equal(t, items[0].Summary, got[0].Summary)
+	require.Equal(t, items[0].Description, got[0].Description)
+	require.Equal(t, items[0].Categories, got[0].Categories)
+	require.Equal(t, 1, got[0].Priority)
+	require.Equal(t, StatusNeedsAction, got[0].Status)
+	require.True(t, got[0].AllDay)
+	require.True(t, items[0].Due.Equal(got[0].Due))
+
+	require.Equal(t, items[1].Summary, got[1].Summary)
+	require.False(t, got[1].AllDay)
+	require.True(t, items[1].Due.Equal(got[1].Due))
+}
+
+func TestEncodeEvent(t *testing.T) {
+	var buf bytes.Buffer
+	err := Encode(&buf, Calendar{Items: []Item{{
+		UID:     "todo-1@todoapp",
+		Summary: "Standup",
+		Status:  StatusNeedsAction,
+		Due:     time.Date(2020, 1, 2, 9, 30, 0, 0, time.UTC),
+	}}}, ComponentEvent)
+	require.NoError(t, err)
+
+	require.Contains(t, buf.String(), "BEGIN:VEVENT\r\n")
+	require.Contains(t, buf.String(), "DTSTART:20200102T093000Z\r\n")
+	require.NotContains(t, buf.String(), "STATUS:")
+}
+
+func TestDecodeTimeZones(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VTODO",
+		"SUMMARY:with tzid",
+		"DUE;TZID=America/New_York:20200102T090000",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"SUMMARY:floating, started",
+		"DTSTART:20200103T090000",
+		"STATUS:completed",
+		"END:VTODO",
+		"BEGIN:VEVENT",
+		"SUMMARY:ignored",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\n")
+
+	got, err := Decode(strings.NewReader(ics), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+
+	require.Equal(t, "2020-01-02T14:00:00Z", got[0].Due.UTC().Format(time.RFC3339))
+	require.Equal(t, "2020-01-03T09:00:00Z", got[1].Due.UTC().Format(time.RFC3339))
+	require.Equal(t, StatusCompleted, got[1].Status)
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	_, err := Decode(strings.NewReader("hello"), time.UTC)
+	require.ErrorIs(t, err, ErrInvalidCalendar)
+
+	_, err = Decode(strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VTODO\nDUE:tomorrow\nEND:VTODO\nEND:VCALENDAR"), time.UTC)
+	require.ErrorIs(t, err, ErrInvalidCalendar)
+}
@@ -0,0 +1,2 @@
+ALTER TABLE todos DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE categories DROP COLUMN IF EXISTS deleted_at;
\ No newline at end of file
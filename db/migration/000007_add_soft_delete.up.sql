@@ -0,0 +1,5 @@
+ALTER TABLE todos ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE categories ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX ON todos (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX ON categories (deleted_at) WHERE deleted_at IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE todos
+    DROP CONSTRAINT IF EXISTS todos_user_email_fkey,
+    DROP CONSTRAINT IF EXISTS todos_category_id_fkey;
+
+DROP INDEX IF EXISTS todos_user_email_idx;
+DROP INDEX IF EXISTS todos_category_id_idx;
@@ -0,0 +1,28 @@
+INSERT INTO categories (name)
+SELECT 'Uncategorized'
+WHERE NOT EXISTS (
+    SELECT 1 FROM categories WHERE name = 'Uncategorized' AND deleted_at IS NULL
+);
+
+-- todos without an owner can't be reached by anyone
+DELETE FROM todos t
+WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.email = t.user_email);
+
+-- todos pointing at a missing category are kept in Uncategorized
+UPDATE todos t
+SET category_id = (
+    SELECT id FROM categories
+    WHERE name = 'Uncategorized' AND deleted_at IS NULL
+    ORDER BY id
+    LIMIT 1
+)
+WHERE NOT EXISTS (SELECT 1 FROM categories c WHERE c.id = t.category_id);
+
+ALTER TABLE todos
+    ADD CONSTRAINT todos_user_email_fkey FOREIGN KEY (user_email)
+        REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    ADD CONSTRAINT todos_category_id_fkey FOREIGN KEY (category_id)
+        REFERENCES categories (id) ON DELETE RESTRICT;
+
+CREATE INDEX ON todos (user_email);
+CREATE INDEX ON todos (category_id);
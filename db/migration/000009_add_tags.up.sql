@@ -0,0 +1,16 @@
+CREATE TABLE "tags" (
+  "id" SERIAL PRIMARY KEY,
+  "user_email" varchar(80) NOT NULL REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+  "name" varchar(50) NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT(now())
+);
+
+CREATE UNIQUE INDEX ON tags (user_email, name);
+
+CREATE TABLE "todo_tags" (
+  "todo_id" int NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+  "tag_id" int NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+  PRIMARY KEY ("todo_id", "tag_id")
+);
+
+CREATE INDEX ON todo_tags (tag_id);
@@ -0,0 +1,4 @@
+ALTER TABLE todos DROP COLUMN IF EXISTS all_day;
+ALTER TABLE todos ALTER COLUMN date TYPE timestamp USING date AT TIME ZONE 'UTC';
+
+ALTER TABLE users DROP COLUMN IF EXISTS time_zone;
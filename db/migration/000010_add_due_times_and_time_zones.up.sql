@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN time_zone varchar(64) NOT NULL DEFAULT 'UTC';
+
+-- existing dates were written as midnight without a zone, read them as UTC
+ALTER TABLE todos ALTER COLUMN date TYPE timestamptz USING date AT TIME ZONE 'UTC';
+ALTER TABLE todos ADD COLUMN all_day boolean NOT NULL DEFAULT true;
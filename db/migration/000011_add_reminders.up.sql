@@ -0,0 +1,18 @@
+CREATE TABLE "reminders" (
+  "id" SERIAL PRIMARY KEY,
+  "todo_id" int NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+  "user_email" varchar(80) NOT NULL REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+  "remind_at" timestamptz NOT NULL,
+  "offset_minutes" int,
+  "channel" varchar(20) NOT NULL DEFAULT 'log',
+  "target" text NOT NULL DEFAULT '',
+  "status" varchar(20) NOT NULL DEFAULT 'pending',
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" text NOT NULL DEFAULT '',
+  "next_attempt_at" timestamptz NOT NULL,
+  "sent_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT(now())
+);
+
+CREATE INDEX ON reminders (todo_id);
+CREATE INDEX ON reminders (next_attempt_at) WHERE status = 'pending';
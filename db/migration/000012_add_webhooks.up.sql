@@ -0,0 +1,29 @@
+CREATE TABLE "webhooks" (
+  "id" SERIAL PRIMARY KEY,
+  "user_email" varchar(80) NOT NULL REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+  "url" text NOT NULL,
+  "secret" varchar(64) NOT NULL,
+  "events" text[] NOT NULL,
+  "active" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT(now())
+);
+
+CREATE INDEX ON webhooks (user_email);
+
+-- outbox: rows are written in the same transaction as the change they describe
+CREATE TABLE "webhook_deliveries" (
+  "id" SERIAL PRIMARY KEY,
+  "webhook_id" int NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+  "event" varchar(50) NOT NULL,
+  "payload" jsonb NOT NULL,
+  "status" varchar(20) NOT NULL DEFAULT 'pending',
+  "attempts" int NOT NULL DEFAULT 0,
+  "response_status" int NOT NULL DEFAULT 0,
+  "last_error" text NOT NULL DEFAULT '',
+  "next_attempt_at" timestamptz NOT NULL DEFAULT(now()),
+  "delivered_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT(now())
+);
+
+CREATE INDEX ON webhook_deliveries (webhook_id);
+CREATE INDEX ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
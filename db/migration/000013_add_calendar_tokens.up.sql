@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN calendar_token varchar(64) UNIQUE;
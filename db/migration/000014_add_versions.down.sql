@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS categories_bump_version ON categories;
+DROP TRIGGER IF EXISTS todos_bump_version ON todos;
+DROP FUNCTION IF EXISTS bump_version();
+
+ALTER TABLE categories DROP COLUMN IF EXISTS version;
+ALTER TABLE todos DROP COLUMN IF EXISTS version;
@@ -0,0 +1,15 @@
+ALTER TABLE todos ADD COLUMN version int NOT NULL DEFAULT 1;
+ALTER TABLE categories ADD COLUMN version int NOT NULL DEFAULT 1;
+
+-- every write bumps the version, so no query can forget to
+CREATE FUNCTION bump_version() RETURNS trigger AS $$
+BEGIN
+  NEW.version := OLD.version + 1;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER todos_bump_version BEFORE UPDATE ON todos
+  FOR EACH ROW EXECUTE FUNCTION bump_version();
+CREATE TRIGGER categories_bump_version BEFORE UPDATE ON categories
+  FOR EACH ROW EXECUTE FUNCTION bump_version();
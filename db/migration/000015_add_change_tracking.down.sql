@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS categories_record_tombstone ON categories;
+DROP TRIGGER IF EXISTS todos_record_tombstone ON todos;
+DROP FUNCTION IF EXISTS record_tombstone();
+DROP TABLE IF EXISTS tombstones;
+
+DROP TRIGGER IF EXISTS categories_bump_change_seq ON categories;
+DROP TRIGGER IF EXISTS todos_bump_change_seq ON todos;
+DROP FUNCTION IF EXISTS bump_change_seq();
+
+ALTER TABLE categories DROP COLUMN IF EXISTS change_seq;
+ALTER TABLE todos DROP COLUMN IF EXISTS change_seq;
+
+DROP SEQUENCE IF EXISTS change_seq;
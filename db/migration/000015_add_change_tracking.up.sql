@@ -0,0 +1,45 @@
+-- one sequence across tables gives sync clients a single change token
+CREATE SEQUENCE change_seq;
+
+ALTER TABLE todos ADD COLUMN change_seq bigint NOT NULL DEFAULT nextval('change_seq');
+ALTER TABLE categories ADD COLUMN change_seq bigint NOT NULL DEFAULT nextval('change_seq');
+
+CREATE INDEX ON todos (user_email, change_seq);
+CREATE INDEX ON categories (change_seq);
+
+CREATE FUNCTION bump_change_seq() RETURNS trigger AS $$
+BEGIN
+  NEW.change_seq := nextval('change_seq');
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER todos_bump_change_seq BEFORE UPDATE ON todos
+  FOR EACH ROW EXECUTE FUNCTION bump_change_seq();
+CREATE TRIGGER categories_bump_change_seq BEFORE UPDATE ON categories
+  FOR EACH ROW EXECUTE FUNCTION bump_change_seq();
+
+-- rows purged from the trash leave a tombstone so clients can drop them too
+CREATE TABLE "tombstones" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "entity" varchar(20) NOT NULL,
+  "entity_id" int NOT NULL,
+  "user_email" varchar(80),
+  "change_seq" bigint NOT NULL DEFAULT nextval('change_seq'),
+  "deleted_at" timestamptz NOT NULL DEFAULT(now())
+);
+
+CREATE INDEX ON tombstones (change_seq);
+
+CREATE FUNCTION record_tombstone() RETURNS trigger AS $$
+BEGIN
+  INSERT INTO tombstones (entity, entity_id, user_email)
+  VALUES (TG_ARGV[0], OLD.id, to_jsonb(OLD) ->> 'user_email');
+  RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER todos_record_tombstone AFTER DELETE ON todos
+  FOR EACH ROW EXECUTE FUNCTION record_tombstone('todo');
+CREATE TRIGGER categories_record_tombstone AFTER DELETE ON categories
+  FOR EACH ROW EXECUTE FUNCTION record_tombstone('category');
@@ -0,0 +1,13 @@
+CREATE TABLE "idempotency_keys" (
+  "user_email" varchar(80) NOT NULL REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+  "key" varchar(255) NOT NULL,
+  "request_hash" varchar(64) NOT NULL,
+  -- 0 while the first request is still running
+  "response_status" int NOT NULL DEFAULT 0,
+  "response_body" bytea NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT(now()),
+  "expires_at" timestamptz NOT NULL,
+  PRIMARY KEY ("user_email", "key")
+);
+
+CREATE INDEX ON idempotency_keys (expires_at);
@@ -0,0 +1,18 @@
+CREATE OR REPLACE FUNCTION bump_change_seq() RETURNS trigger AS $$
+BEGIN
+  NEW.change_seq := nextval('change_seq');
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS todos_user_email_change_txid_change_seq_idx;
+DROP INDEX IF EXISTS categories_change_txid_change_seq_idx;
+DROP INDEX IF EXISTS tombstones_change_txid_change_seq_idx;
+
+ALTER TABLE tombstones DROP COLUMN IF EXISTS change_txid;
+ALTER TABLE categories DROP COLUMN IF EXISTS change_txid;
+ALTER TABLE todos DROP COLUMN IF EXISTS change_txid;
+
+CREATE INDEX ON todos (user_email, change_seq);
+CREATE INDEX ON categories (change_seq);
+CREATE INDEX ON tombstones (change_seq);
@@ -0,0 +1,26 @@
+-- change_seq is drawn when a row is written, not when it commits, so a pull
+-- can see seq 6 before seq 5 exists. Recording the writing transaction lets
+-- a pull stop below the oldest transaction still in flight.
+ALTER TABLE todos ADD COLUMN change_txid bigint NOT NULL DEFAULT 0;
+ALTER TABLE categories ADD COLUMN change_txid bigint NOT NULL DEFAULT 0;
+ALTER TABLE tombstones ADD COLUMN change_txid bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE todos ALTER COLUMN change_txid SET DEFAULT txid_current();
+ALTER TABLE categories ALTER COLUMN change_txid SET DEFAULT txid_current();
+ALTER TABLE tombstones ALTER COLUMN change_txid SET DEFAULT txid_current();
+
+DROP INDEX IF EXISTS todos_user_email_change_seq_idx;
+DROP INDEX IF EXISTS categories_change_seq_idx;
+DROP INDEX IF EXISTS tombstones_change_seq_idx;
+
+CREATE INDEX ON todos (user_email, change_txid, change_seq);
+CREATE INDEX ON categories (change_txid, change_seq);
+CREATE INDEX ON tombstones (change_txid, change_seq);
+
+CREATE OR REPLACE FUNCTION bump_change_seq() RETURNS trigger AS $$
+BEGIN
+  NEW.change_seq := nextval('change_seq');
+  NEW.change_txid := txid_current();
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,17 @@
+-- the default category is found by this flag rather than its name, so it can
+-- be renamed and a user category called Uncategorized is an ordinary one
+ALTER TABLE categories ADD COLUMN is_default boolean NOT NULL DEFAULT false;
+
+UPDATE categories SET is_default = true
+WHERE id = (
+    SELECT id FROM categories
+    WHERE name = 'Uncategorized' AND deleted_at IS NULL
+    ORDER BY id
+    LIMIT 1
+);
+
+INSERT INTO categories (name, is_default)
+SELECT 'Uncategorized', true
+WHERE NOT EXISTS (SELECT 1 FROM categories WHERE is_default);
+
+CREATE UNIQUE INDEX ON categories (is_default) WHERE is_default;
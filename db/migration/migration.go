@@ -0,0 +1,38 @@
+// Package migration holds the schema migrations applied by golang-migrate.
+package migration
+
+import (
+	"embed"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// LatestVersion is the version of the newest migration, the one a database
+// must be at for this build to run against it.
+func LatestVersion() (int64, error) {
+	return latestVersion(files)
+}
+
+func latestVersion(fsys fs.FS) (int64, error) {
+	names, err := fs.Glob(fsys, "*.up.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, name := range names {
+		prefix := strings.SplitN(name, "_", 2)[0]
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+	return latest, nil
+}
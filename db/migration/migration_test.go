@@ -0,0 +1,27 @@
+package migration
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestVersion(t *testing.T) {
+	version, err := latestVersion(fstest.MapFS{
+		"000001_init_schema.up.sql":    {},
+		"000001_init_schema.down.sql":  {},
+		"000010_add_tags.up.sql":       {},
+		"000002_add_categories.up.sql": {},
+		"000011_add_webhooks.down.sql": {},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(10), version)
+
+	_, err = latestVersion(fstest.MapFS{"first_init_schema.up.sql": {}})
+	require.Error(t, err)
+
+	version, err = LatestVersion()
+	require.NoError(t, err)
+	require.Positive(t, version)
+}
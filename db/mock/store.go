@@ -6,7 +6,9 @@ package mockdb
 
 import (
 	context "context"
+	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	db "github.com/maslow123/todoapp-services/db/sqlc"
@@ -35,241 +37,1324 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AddTodoTags mocks base method.
+func (m *MockStore) AddTodoTags(arg0 context.Context, arg1 db.AddTodoTagsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTodoTags", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTodoTags indicates an expected call of AddTodoTags.
+func (mr *MockStoreMockRecorder) AddTodoTags(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTodoTags", reflect.TypeOf((*MockStore)(nil).AddTodoTags), arg0, arg1)
+}
+
+// BatchTodoTx mocks base method.
+func (m *MockStore) BatchTodoTx(arg0 context.Context, arg1 db.BatchTodoTxParams) (db.BatchTodoTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchTodoTx", arg0, arg1)
+	ret0, _ := ret[0].(db.BatchTodoTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchTodoTx indicates an expected call of BatchTodoTx.
+func (mr *MockStoreMockRecorder) BatchTodoTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchTodoTx", reflect.TypeOf((*MockStore)(nil).BatchTodoTx), arg0, arg1)
+}
+
+// ClaimDueReminders mocks base method.
+func (m *MockStore) ClaimDueReminders(arg0 context.Context, arg1 db.ClaimDueRemindersParams) ([]db.ClaimDueRemindersRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueReminders", arg0, arg1)
+	ret0, _ := ret[0].([]db.ClaimDueRemindersRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueReminders indicates an expected call of ClaimDueReminders.
+func (mr *MockStoreMockRecorder) ClaimDueReminders(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueReminders", reflect.TypeOf((*MockStore)(nil).ClaimDueReminders), arg0, arg1)
+}
+
+// ClaimWebhookDeliveries mocks base method.
+func (m *MockStore) ClaimWebhookDeliveries(arg0 context.Context, arg1 db.ClaimWebhookDeliveriesParams) ([]db.ClaimWebhookDeliveriesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]db.ClaimWebhookDeliveriesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookDeliveries indicates an expected call of ClaimWebhookDeliveries.
+func (mr *MockStoreMockRecorder) ClaimWebhookDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).ClaimWebhookDeliveries), arg0, arg1)
+}
+
+// CompleteTodoTx mocks base method.
+func (m *MockStore) CompleteTodoTx(arg0 context.Context, arg1 db.CompleteTodoTxParams) (db.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTodoTx", arg0, arg1)
+	ret0, _ := ret[0].(db.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteTodoTx indicates an expected call of CompleteTodoTx.
+func (mr *MockStoreMockRecorder) CompleteTodoTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTodoTx", reflect.TypeOf((*MockStore)(nil).CompleteTodoTx), arg0, arg1)
+}
+
+// CountTagsByUser mocks base method.
+func (m *MockStore) CountTagsByUser(arg0 context.Context, arg1 db.CountTagsByUserParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountTagsByUser", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountTagsByUser indicates an expected call of CountTagsByUser.
+func (mr *MockStoreMockRecorder) CountTagsByUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTagsByUser", reflect.TypeOf((*MockStore)(nil).CountTagsByUser), arg0, arg1)
+}
+
+// CountTodosByCategory mocks base method.
+func (m *MockStore) CountTodosByCategory(arg0 context.Context, arg1 int32) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountTodosByCategory", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountTodosByCategory indicates an expected call of CountTodosByCategory.
+func (mr *MockStoreMockRecorder) CountTodosByCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTodosByCategory", reflect.TypeOf((*MockStore)(nil).CountTodosByCategory), arg0, arg1)
+}
+
 // CreateCategory mocks base method.
 func (m *MockStore) CreateCategory(arg0 context.Context, arg1 string) (db.Category, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateCategory", arg0, arg1)
-	ret0, _ := ret[0].(db.Category)
+	ret := m.ctrl.Call(m, "CreateCategory", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCategory indicates an expected call of CreateCategory.
+func (mr *MockStoreMockRecorder) CreateCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategory", reflect.TypeOf((*MockStore)(nil).CreateCategory), arg0, arg1)
+}
+
+// CreateCategoryTx mocks base method.
+func (m *MockStore) CreateCategoryTx(arg0 context.Context, arg1 db.CreateCategoryTxParams) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategoryTx", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCategoryTx indicates an expected call of CreateCategoryTx.
+func (mr *MockStoreMockRecorder) CreateCategoryTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategoryTx", reflect.TypeOf((*MockStore)(nil).CreateCategoryTx), arg0, arg1)
+}
+
+// CreateDefaultCategory mocks base method.
+func (m *MockStore) CreateDefaultCategory(arg0 context.Context, arg1 string) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDefaultCategory", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDefaultCategory indicates an expected call of CreateDefaultCategory.
+func (mr *MockStoreMockRecorder) CreateDefaultCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDefaultCategory", reflect.TypeOf((*MockStore)(nil).CreateDefaultCategory), arg0, arg1)
+}
+
+// CreateIdempotencyKey mocks base method.
+func (m *MockStore) CreateIdempotencyKey(arg0 context.Context, arg1 db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockStoreMockRecorder) CreateIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockStore)(nil).CreateIdempotencyKey), arg0, arg1)
+}
+
+// CreateReminder mocks base method.
+func (m *MockStore) CreateReminder(arg0 context.Context, arg1 db.CreateReminderParams) (db.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReminder", arg0, arg1)
+	ret0, _ := ret[0].(db.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReminder indicates an expected call of CreateReminder.
+func (mr *MockStoreMockRecorder) CreateReminder(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReminder", reflect.TypeOf((*MockStore)(nil).CreateReminder), arg0, arg1)
+}
+
+// CreateTag mocks base method.
+func (m *MockStore) CreateTag(arg0 context.Context, arg1 db.CreateTagParams) (db.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTag", arg0, arg1)
+	ret0, _ := ret[0].(db.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTag indicates an expected call of CreateTag.
+func (mr *MockStoreMockRecorder) CreateTag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTag", reflect.TypeOf((*MockStore)(nil).CreateTag), arg0, arg1)
+}
+
+// CreateTodo mocks base method.
+func (m *MockStore) CreateTodo(arg0 context.Context, arg1 db.CreateTodoParams) (db.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTodo", arg0, arg1)
+	ret0, _ := ret[0].(db.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTodo indicates an expected call of CreateTodo.
+func (mr *MockStoreMockRecorder) CreateTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTodo", reflect.TypeOf((*MockStore)(nil).CreateTodo), arg0, arg1)
+}
+
+// CreateTodoTx mocks base method.
+func (m *MockStore) CreateTodoTx(arg0 context.Context, arg1 db.CreateTodoTxParams) (db.TodoTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTodoTx", arg0, arg1)
+	ret0, _ := ret[0].(db.TodoTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTodoTx indicates an expected call of CreateTodoTx.
+func (mr *MockStoreMockRecorder) CreateTodoTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTodoTx", reflect.TypeOf((*MockStore)(nil).CreateTodoTx), arg0, arg1)
+}
+
+// CreateUser mocks base method.
+func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockStore) CreateWebhook(arg0 context.Context, arg1 db.CreateWebhookParams) (db.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", arg0, arg1)
+	ret0, _ := ret[0].(db.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockStoreMockRecorder) CreateWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockStore)(nil).CreateWebhook), arg0, arg1)
+}
+
+// DeleteCategory mocks base method.
+func (m *MockStore) DeleteCategory(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategory", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCategory indicates an expected call of DeleteCategory.
+func (mr *MockStoreMockRecorder) DeleteCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockStore)(nil).DeleteCategory), arg0, arg1)
+}
+
+// DeleteCategoryTx mocks base method.
+func (m *MockStore) DeleteCategoryTx(arg0 context.Context, arg1 db.DeleteCategoryTxParams) (db.DeleteCategoryTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategoryTx", arg0, arg1)
+	ret0, _ := ret[0].(db.DeleteCategoryTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCategoryTx indicates an expected call of DeleteCategoryTx.
+func (mr *MockStoreMockRecorder) DeleteCategoryTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategoryTx", reflect.TypeOf((*MockStore)(nil).DeleteCategoryTx), arg0, arg1)
+}
+
+// DeleteIdempotencyKey mocks base method.
+func (m *MockStore) DeleteIdempotencyKey(arg0 context.Context, arg1 db.DeleteIdempotencyKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotencyKey indicates an expected call of DeleteIdempotencyKey.
+func (mr *MockStoreMockRecorder) DeleteIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKey", reflect.TypeOf((*MockStore)(nil).DeleteIdempotencyKey), arg0, arg1)
+}
+
+// DeleteReminder mocks base method.
+func (m *MockStore) DeleteReminder(arg0 context.Context, arg1 db.DeleteReminderParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReminder", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteReminder indicates an expected call of DeleteReminder.
+func (mr *MockStoreMockRecorder) DeleteReminder(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReminder", reflect.TypeOf((*MockStore)(nil).DeleteReminder), arg0, arg1)
+}
+
+// DeleteTag mocks base method.
+func (m *MockStore) DeleteTag(arg0 context.Context, arg1 db.DeleteTagParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTag", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteTag indicates an expected call of DeleteTag.
+func (mr *MockStoreMockRecorder) DeleteTag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTag", reflect.TypeOf((*MockStore)(nil).DeleteTag), arg0, arg1)
+}
+
+// DeleteTodo mocks base method.
+func (m *MockStore) DeleteTodo(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTodo", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTodo indicates an expected call of DeleteTodo.
+func (mr *MockStoreMockRecorder) DeleteTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTodo", reflect.TypeOf((*MockStore)(nil).DeleteTodo), arg0, arg1)
+}
+
+// DeleteTodoTags mocks base method.
+func (m *MockStore) DeleteTodoTags(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTodoTags", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTodoTags indicates an expected call of DeleteTodoTags.
+func (mr *MockStoreMockRecorder) DeleteTodoTags(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTodoTags", reflect.TypeOf((*MockStore)(nil).DeleteTodoTags), arg0, arg1)
+}
+
+// DeleteTodoTx mocks base method.
+func (m *MockStore) DeleteTodoTx(arg0 context.Context, arg1 db.DeleteTodoTxParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTodoTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTodoTx indicates an expected call of DeleteTodoTx.
+func (mr *MockStoreMockRecorder) DeleteTodoTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTodoTx", reflect.TypeOf((*MockStore)(nil).DeleteTodoTx), arg0, arg1)
+}
+
+// DeleteUser mocks base method.
+func (m *MockStore) DeleteUser(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockStoreMockRecorder) DeleteUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), arg0, arg1)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockStore) DeleteWebhook(arg0 context.Context, arg1 db.DeleteWebhookParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockStoreMockRecorder) DeleteWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockStore)(nil).DeleteWebhook), arg0, arg1)
+}
+
+// DeliverReminders mocks base method.
+func (m *MockStore) DeliverReminders(arg0 context.Context, arg1 db.DeliverRemindersParams) (db.DeliverRemindersResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeliverReminders", arg0, arg1)
+	ret0, _ := ret[0].(db.DeliverRemindersResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeliverReminders indicates an expected call of DeliverReminders.
+func (mr *MockStoreMockRecorder) DeliverReminders(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliverReminders", reflect.TypeOf((*MockStore)(nil).DeliverReminders), arg0, arg1)
+}
+
+// DeliverWebhooks mocks base method.
+func (m *MockStore) DeliverWebhooks(arg0 context.Context, arg1 db.DeliverWebhooksParams) (db.DeliverWebhooksResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeliverWebhooks", arg0, arg1)
+	ret0, _ := ret[0].(db.DeliverWebhooksResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeliverWebhooks indicates an expected call of DeliverWebhooks.
+func (mr *MockStoreMockRecorder) DeliverWebhooks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliverWebhooks", reflect.TypeOf((*MockStore)(nil).DeliverWebhooks), arg0, arg1)
+}
+
+// EnqueueWebhookEvent mocks base method.
+func (m *MockStore) EnqueueWebhookEvent(arg0 context.Context, arg1 db.EnqueueWebhookEventParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueWebhookEvent", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueWebhookEvent indicates an expected call of EnqueueWebhookEvent.
+func (mr *MockStoreMockRecorder) EnqueueWebhookEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueWebhookEvent", reflect.TypeOf((*MockStore)(nil).EnqueueWebhookEvent), arg0, arg1)
+}
+
+// GetAnyCategoryForUpdate mocks base method.
+func (m *MockStore) GetAnyCategoryForUpdate(arg0 context.Context, arg1 int32) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAnyCategoryForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAnyCategoryForUpdate indicates an expected call of GetAnyCategoryForUpdate.
+func (mr *MockStoreMockRecorder) GetAnyCategoryForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAnyCategoryForUpdate", reflect.TypeOf((*MockStore)(nil).GetAnyCategoryForUpdate), arg0, arg1)
+}
+
+// GetAnyTodoForUpdate mocks base method.
+func (m *MockStore) GetAnyTodoForUpdate(arg0 context.Context, arg1 int32) (db.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAnyTodoForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAnyTodoForUpdate indicates an expected call of GetAnyTodoForUpdate.
+func (mr *MockStoreMockRecorder) GetAnyTodoForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAnyTodoForUpdate", reflect.TypeOf((*MockStore)(nil).GetAnyTodoForUpdate), arg0, arg1)
+}
+
+// GetCategory mocks base method.
+func (m *MockStore) GetCategory(arg0 context.Context, arg1 int32) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategory", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategory indicates an expected call of GetCategory.
+func (mr *MockStoreMockRecorder) GetCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategory", reflect.TypeOf((*MockStore)(nil).GetCategory), arg0, arg1)
+}
+
+// GetCategoryByName mocks base method.
+func (m *MockStore) GetCategoryByName(arg0 context.Context, arg1 string) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryByName", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryByName indicates an expected call of GetCategoryByName.
+func (mr *MockStoreMockRecorder) GetCategoryByName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryByName", reflect.TypeOf((*MockStore)(nil).GetCategoryByName), arg0, arg1)
+}
+
+// GetCategoryForUpdate mocks base method.
+func (m *MockStore) GetCategoryForUpdate(arg0 context.Context, arg1 int32) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryForUpdate indicates an expected call of GetCategoryForUpdate.
+func (mr *MockStoreMockRecorder) GetCategoryForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryForUpdate", reflect.TypeOf((*MockStore)(nil).GetCategoryForUpdate), arg0, arg1)
+}
+
+// GetDefaultCategory mocks base method.
+func (m *MockStore) GetDefaultCategory(arg0 context.Context) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDefaultCategory", arg0)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDefaultCategory indicates an expected call of GetDefaultCategory.
+func (mr *MockStoreMockRecorder) GetDefaultCategory(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDefaultCategory", reflect.TypeOf((*MockStore)(nil).GetDefaultCategory), arg0)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockStore) GetIdempotencyKey(arg0 context.Context, arg1 db.GetIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockStoreMockRecorder) GetIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockStore)(nil).GetIdempotencyKey), arg0, arg1)
+}
+
+// GetSyncHorizon mocks base method.
+func (m *MockStore) GetSyncHorizon(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSyncHorizon", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSyncHorizon indicates an expected call of GetSyncHorizon.
+func (mr *MockStoreMockRecorder) GetSyncHorizon(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSyncHorizon", reflect.TypeOf((*MockStore)(nil).GetSyncHorizon), arg0)
+}
+
+// GetTagByName mocks base method.
+func (m *MockStore) GetTagByName(arg0 context.Context, arg1 db.GetTagByNameParams) (db.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTagByName", arg0, arg1)
+	ret0, _ := ret[0].(db.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTagByName indicates an expected call of GetTagByName.
+func (mr *MockStoreMockRecorder) GetTagByName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTagByName", reflect.TypeOf((*MockStore)(nil).GetTagByName), arg0, arg1)
+}
+
+// GetTodo mocks base method.
+func (m *MockStore) GetTodo(arg0 context.Context, arg1 int32) (db.GetTodoRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTodo", arg0, arg1)
+	ret0, _ := ret[0].(db.GetTodoRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTodo indicates an expected call of GetTodo.
+func (mr *MockStoreMockRecorder) GetTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTodo", reflect.TypeOf((*MockStore)(nil).GetTodo), arg0, arg1)
+}
+
+// GetTodoForUpdate mocks base method.
+func (m *MockStore) GetTodoForUpdate(arg0 context.Context, arg1 int32) (db.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTodoForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTodoForUpdate indicates an expected call of GetTodoForUpdate.
+func (mr *MockStoreMockRecorder) GetTodoForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTodoForUpdate", reflect.TypeOf((*MockStore)(nil).GetTodoForUpdate), arg0, arg1)
+}
+
+// GetUser mocks base method.
+func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+}
+
+// GetUserByCalendarToken mocks base method.
+func (m *MockStore) GetUserByCalendarToken(arg0 context.Context, arg1 sql.NullString) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByCalendarToken", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByCalendarToken indicates an expected call of GetUserByCalendarToken.
+func (mr *MockStoreMockRecorder) GetUserByCalendarToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByCalendarToken", reflect.TypeOf((*MockStore)(nil).GetUserByCalendarToken), arg0, arg1)
+}
+
+// ImportTx mocks base method.
+func (m *MockStore) ImportTx(arg0 context.Context, arg1 db.ImportTxParams) (db.ImportTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ImportTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportTx indicates an expected call of ImportTx.
+func (mr *MockStoreMockRecorder) ImportTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportTx", reflect.TypeOf((*MockStore)(nil).ImportTx), arg0, arg1)
+}
+
+// ListCategories mocks base method.
+func (m *MockStore) ListCategories(arg0 context.Context, arg1 db.ListCategoriesParams) ([]db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCategories", arg0, arg1)
+	ret0, _ := ret[0].([]db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCategories indicates an expected call of ListCategories.
+func (mr *MockStoreMockRecorder) ListCategories(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategories", reflect.TypeOf((*MockStore)(nil).ListCategories), arg0, arg1)
+}
+
+// ListCategoriesByIDs mocks base method.
+func (m *MockStore) ListCategoriesByIDs(arg0 context.Context, arg1 []int32) ([]db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCategoriesByIDs", arg0, arg1)
+	ret0, _ := ret[0].([]db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCategoriesByIDs indicates an expected call of ListCategoriesByIDs.
+func (mr *MockStoreMockRecorder) ListCategoriesByIDs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategoriesByIDs", reflect.TypeOf((*MockStore)(nil).ListCategoriesByIDs), arg0, arg1)
+}
+
+// ListCategoryChangesSince mocks base method.
+func (m *MockStore) ListCategoryChangesSince(arg0 context.Context, arg1 db.ListCategoryChangesSinceParams) ([]db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCategoryChangesSince", arg0, arg1)
+	ret0, _ := ret[0].([]db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCategoryChangesSince indicates an expected call of ListCategoryChangesSince.
+func (mr *MockStoreMockRecorder) ListCategoryChangesSince(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategoryChangesSince", reflect.TypeOf((*MockStore)(nil).ListCategoryChangesSince), arg0, arg1)
+}
+
+// ListDoneTodo mocks base method.
+func (m *MockStore) ListDoneTodo(arg0 context.Context, arg1 db.ListDoneTodoParams) ([]db.ListDoneTodoRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDoneTodo", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListDoneTodoRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDoneTodo indicates an expected call of ListDoneTodo.
+func (mr *MockStoreMockRecorder) ListDoneTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDoneTodo", reflect.TypeOf((*MockStore)(nil).ListDoneTodo), arg0, arg1)
+}
+
+// ListOpenTodosForCalendar mocks base method.
+func (m *MockStore) ListOpenTodosForCalendar(arg0 context.Context, arg1 string) ([]db.ListOpenTodosForCalendarRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOpenTodosForCalendar", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListOpenTodosForCalendarRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOpenTodosForCalendar indicates an expected call of ListOpenTodosForCalendar.
+func (mr *MockStoreMockRecorder) ListOpenTodosForCalendar(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOpenTodosForCalendar", reflect.TypeOf((*MockStore)(nil).ListOpenTodosForCalendar), arg0, arg1)
+}
+
+// ListRemindersByTodo mocks base method.
+func (m *MockStore) ListRemindersByTodo(arg0 context.Context, arg1 db.ListRemindersByTodoParams) ([]db.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRemindersByTodo", arg0, arg1)
+	ret0, _ := ret[0].([]db.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRemindersByTodo indicates an expected call of ListRemindersByTodo.
+func (mr *MockStoreMockRecorder) ListRemindersByTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRemindersByTodo", reflect.TypeOf((*MockStore)(nil).ListRemindersByTodo), arg0, arg1)
+}
+
+// ListTags mocks base method.
+func (m *MockStore) ListTags(arg0 context.Context, arg1 db.ListTagsParams) ([]db.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTags", arg0, arg1)
+	ret0, _ := ret[0].([]db.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTags indicates an expected call of ListTags.
+func (mr *MockStoreMockRecorder) ListTags(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockStore)(nil).ListTags), arg0, arg1)
+}
+
+// ListTagsByTodo mocks base method.
+func (m *MockStore) ListTagsByTodo(arg0 context.Context, arg1 int32) ([]db.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTagsByTodo", arg0, arg1)
+	ret0, _ := ret[0].([]db.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTagsByTodo indicates an expected call of ListTagsByTodo.
+func (mr *MockStoreMockRecorder) ListTagsByTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagsByTodo", reflect.TypeOf((*MockStore)(nil).ListTagsByTodo), arg0, arg1)
+}
+
+// ListTodayTodo mocks base method.
+func (m *MockStore) ListTodayTodo(arg0 context.Context, arg1 db.ListTodayTodoParams) ([]db.ListTodayTodoRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTodayTodo", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListTodayTodoRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTodayTodo indicates an expected call of ListTodayTodo.
+func (mr *MockStoreMockRecorder) ListTodayTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTodayTodo", reflect.TypeOf((*MockStore)(nil).ListTodayTodo), arg0, arg1)
+}
+
+// ListTodoByUser mocks base method.
+func (m *MockStore) ListTodoByUser(arg0 context.Context, arg1 db.ListTodoByUserParams) ([]db.ListTodoByUserRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTodoByUser", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListTodoByUserRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTodoByUser indicates an expected call of ListTodoByUser.
+func (mr *MockStoreMockRecorder) ListTodoByUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTodoByUser", reflect.TypeOf((*MockStore)(nil).ListTodoByUser), arg0, arg1)
+}
+
+// ListTodoChangesSince mocks base method.
+func (m *MockStore) ListTodoChangesSince(arg0 context.Context, arg1 db.ListTodoChangesSinceParams) ([]db.ListTodoChangesSinceRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTodoChangesSince", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListTodoChangesSinceRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTodoChangesSince indicates an expected call of ListTodoChangesSince.
+func (mr *MockStoreMockRecorder) ListTodoChangesSince(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTodoChangesSince", reflect.TypeOf((*MockStore)(nil).ListTodoChangesSince), arg0, arg1)
+}
+
+// ListTodosForExport mocks base method.
+func (m *MockStore) ListTodosForExport(arg0 context.Context, arg1 string) ([]db.ListTodosForExportRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTodosForExport", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListTodosForExportRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTodosForExport indicates an expected call of ListTodosForExport.
+func (mr *MockStoreMockRecorder) ListTodosForExport(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTodosForExport", reflect.TypeOf((*MockStore)(nil).ListTodosForExport), arg0, arg1)
+}
+
+// ListTodosForUpdate mocks base method.
+func (m *MockStore) ListTodosForUpdate(arg0 context.Context, arg1 []int32) ([]db.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTodosForUpdate", arg0, arg1)
+	ret0, _ := ret[0].([]db.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTodosForUpdate indicates an expected call of ListTodosForUpdate.
+func (mr *MockStoreMockRecorder) ListTodosForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTodosForUpdate", reflect.TypeOf((*MockStore)(nil).ListTodosForUpdate), arg0, arg1)
+}
+
+// ListTombstonesSince mocks base method.
+func (m *MockStore) ListTombstonesSince(arg0 context.Context, arg1 db.ListTombstonesSinceParams) ([]db.Tombstone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTombstonesSince", arg0, arg1)
+	ret0, _ := ret[0].([]db.Tombstone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTombstonesSince indicates an expected call of ListTombstonesSince.
+func (mr *MockStoreMockRecorder) ListTombstonesSince(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTombstonesSince", reflect.TypeOf((*MockStore)(nil).ListTombstonesSince), arg0, arg1)
+}
+
+// ListTrashedCategories mocks base method.
+func (m *MockStore) ListTrashedCategories(arg0 context.Context, arg1 db.ListTrashedCategoriesParams) ([]db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrashedCategories", arg0, arg1)
+	ret0, _ := ret[0].([]db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrashedCategories indicates an expected call of ListTrashedCategories.
+func (mr *MockStoreMockRecorder) ListTrashedCategories(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrashedCategories", reflect.TypeOf((*MockStore)(nil).ListTrashedCategories), arg0, arg1)
+}
+
+// ListTrashedTodo mocks base method.
+func (m *MockStore) ListTrashedTodo(arg0 context.Context, arg1 db.ListTrashedTodoParams) ([]db.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrashedTodo", arg0, arg1)
+	ret0, _ := ret[0].([]db.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrashedTodo indicates an expected call of ListTrashedTodo.
+func (mr *MockStoreMockRecorder) ListTrashedTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrashedTodo", reflect.TypeOf((*MockStore)(nil).ListTrashedTodo), arg0, arg1)
+}
+
+// ListUpcomingTodo mocks base method.
+func (m *MockStore) ListUpcomingTodo(arg0 context.Context, arg1 db.ListUpcomingTodoParams) ([]db.ListUpcomingTodoRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUpcomingTodo", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListUpcomingTodoRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUpcomingTodo indicates an expected call of ListUpcomingTodo.
+func (mr *MockStoreMockRecorder) ListUpcomingTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUpcomingTodo", reflect.TypeOf((*MockStore)(nil).ListUpcomingTodo), arg0, arg1)
+}
+
+// ListUsers mocks base method.
+func (m *MockStore) ListUsers(arg0 context.Context, arg1 db.ListUsersParams) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateCategory indicates an expected call of CreateCategory.
-func (mr *MockStoreMockRecorder) CreateCategory(arg0, arg1 interface{}) *gomock.Call {
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockStoreMockRecorder) ListUsers(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategory", reflect.TypeOf((*MockStore)(nil).CreateCategory), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockStore)(nil).ListUsers), arg0, arg1)
 }
 
-// CreateTodo mocks base method.
-func (m *MockStore) CreateTodo(arg0 context.Context, arg1 db.CreateTodoParams) (db.Todo, error) {
+// ListWebhookDeliveries mocks base method.
+func (m *MockStore) ListWebhookDeliveries(arg0 context.Context, arg1 db.ListWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTodo", arg0, arg1)
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]db.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockStoreMockRecorder) ListWebhookDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).ListWebhookDeliveries), arg0, arg1)
+}
+
+// ListWebhooks mocks base method.
+func (m *MockStore) ListWebhooks(arg0 context.Context, arg1 string) ([]db.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", arg0, arg1)
+	ret0, _ := ret[0].([]db.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockStoreMockRecorder) ListWebhooks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockStore)(nil).ListWebhooks), arg0, arg1)
+}
+
+// MarkAsCompleteTodo mocks base method.
+func (m *MockStore) MarkAsCompleteTodo(arg0 context.Context, arg1 int32) (db.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAsCompleteTodo", arg0, arg1)
 	ret0, _ := ret[0].(db.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateTodo indicates an expected call of CreateTodo.
-func (mr *MockStoreMockRecorder) CreateTodo(arg0, arg1 interface{}) *gomock.Call {
+// MarkAsCompleteTodo indicates an expected call of MarkAsCompleteTodo.
+func (mr *MockStoreMockRecorder) MarkAsCompleteTodo(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTodo", reflect.TypeOf((*MockStore)(nil).CreateTodo), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsCompleteTodo", reflect.TypeOf((*MockStore)(nil).MarkAsCompleteTodo), arg0, arg1)
 }
 
-// CreateUser mocks base method.
-func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+// MarkAsIncompleteTodo mocks base method.
+func (m *MockStore) MarkAsIncompleteTodo(arg0 context.Context, arg1 int32) (db.Todo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "MarkAsIncompleteTodo", arg0, arg1)
+	ret0, _ := ret[0].(db.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+// MarkAsIncompleteTodo indicates an expected call of MarkAsIncompleteTodo.
+func (mr *MockStoreMockRecorder) MarkAsIncompleteTodo(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsIncompleteTodo", reflect.TypeOf((*MockStore)(nil).MarkAsIncompleteTodo), arg0, arg1)
 }
 
-// DeleteCategory mocks base method.
-func (m *MockStore) DeleteCategory(arg0 context.Context, arg1 int32) error {
+// MarkReminderFailed mocks base method.
+func (m *MockStore) MarkReminderFailed(arg0 context.Context, arg1 db.MarkReminderFailedParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteCategory", arg0, arg1)
+	ret := m.ctrl.Call(m, "MarkReminderFailed", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteCategory indicates an expected call of DeleteCategory.
-func (mr *MockStoreMockRecorder) DeleteCategory(arg0, arg1 interface{}) *gomock.Call {
+// MarkReminderFailed indicates an expected call of MarkReminderFailed.
+func (mr *MockStoreMockRecorder) MarkReminderFailed(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockStore)(nil).DeleteCategory), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkReminderFailed", reflect.TypeOf((*MockStore)(nil).MarkReminderFailed), arg0, arg1)
 }
 
-// DeleteTodo mocks base method.
-func (m *MockStore) DeleteTodo(arg0 context.Context, arg1 int32) error {
+// MarkReminderRetry mocks base method.
+func (m *MockStore) MarkReminderRetry(arg0 context.Context, arg1 db.MarkReminderRetryParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteTodo", arg0, arg1)
+	ret := m.ctrl.Call(m, "MarkReminderRetry", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteTodo indicates an expected call of DeleteTodo.
-func (mr *MockStoreMockRecorder) DeleteTodo(arg0, arg1 interface{}) *gomock.Call {
+// MarkReminderRetry indicates an expected call of MarkReminderRetry.
+func (mr *MockStoreMockRecorder) MarkReminderRetry(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTodo", reflect.TypeOf((*MockStore)(nil).DeleteTodo), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkReminderRetry", reflect.TypeOf((*MockStore)(nil).MarkReminderRetry), arg0, arg1)
 }
 
-// DeleteUser mocks base method.
-func (m *MockStore) DeleteUser(arg0 context.Context, arg1 int32) error {
+// MarkReminderSent mocks base method.
+func (m *MockStore) MarkReminderSent(arg0 context.Context, arg1 int32) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1)
+	ret := m.ctrl.Call(m, "MarkReminderSent", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteUser indicates an expected call of DeleteUser.
-func (mr *MockStoreMockRecorder) DeleteUser(arg0, arg1 interface{}) *gomock.Call {
+// MarkReminderSent indicates an expected call of MarkReminderSent.
+func (mr *MockStoreMockRecorder) MarkReminderSent(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkReminderSent", reflect.TypeOf((*MockStore)(nil).MarkReminderSent), arg0, arg1)
 }
 
-// GetCategory mocks base method.
-func (m *MockStore) GetCategory(arg0 context.Context, arg1 int32) (db.Category, error) {
+// MarkWebhookDelivered mocks base method.
+func (m *MockStore) MarkWebhookDelivered(arg0 context.Context, arg1 db.MarkWebhookDeliveredParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCategory", arg0, arg1)
-	ret0, _ := ret[0].(db.Category)
+	ret := m.ctrl.Call(m, "MarkWebhookDelivered", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkWebhookDelivered indicates an expected call of MarkWebhookDelivered.
+func (mr *MockStoreMockRecorder) MarkWebhookDelivered(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWebhookDelivered", reflect.TypeOf((*MockStore)(nil).MarkWebhookDelivered), arg0, arg1)
+}
+
+// MarkWebhookFailed mocks base method.
+func (m *MockStore) MarkWebhookFailed(arg0 context.Context, arg1 db.MarkWebhookFailedParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWebhookFailed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkWebhookFailed indicates an expected call of MarkWebhookFailed.
+func (mr *MockStoreMockRecorder) MarkWebhookFailed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWebhookFailed", reflect.TypeOf((*MockStore)(nil).MarkWebhookFailed), arg0, arg1)
+}
+
+// MarkWebhookRetry mocks base method.
+func (m *MockStore) MarkWebhookRetry(arg0 context.Context, arg1 db.MarkWebhookRetryParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWebhookRetry", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkWebhookRetry indicates an expected call of MarkWebhookRetry.
+func (mr *MockStoreMockRecorder) MarkWebhookRetry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWebhookRetry", reflect.TypeOf((*MockStore)(nil).MarkWebhookRetry), arg0, arg1)
+}
+
+// MigrationVersion mocks base method.
+func (m *MockStore) MigrationVersion(arg0 context.Context) (int64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrationVersion", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// MigrationVersion indicates an expected call of MigrationVersion.
+func (mr *MockStoreMockRecorder) MigrationVersion(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrationVersion", reflect.TypeOf((*MockStore)(nil).MigrationVersion), arg0)
+}
+
+// MoveTodo mocks base method.
+func (m *MockStore) MoveTodo(arg0 context.Context, arg1 db.MoveTodoParams) (db.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveTodo", arg0, arg1)
+	ret0, _ := ret[0].(db.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetCategory indicates an expected call of GetCategory.
-func (mr *MockStoreMockRecorder) GetCategory(arg0, arg1 interface{}) *gomock.Call {
+// MoveTodo indicates an expected call of MoveTodo.
+func (mr *MockStoreMockRecorder) MoveTodo(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategory", reflect.TypeOf((*MockStore)(nil).GetCategory), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveTodo", reflect.TypeOf((*MockStore)(nil).MoveTodo), arg0, arg1)
 }
 
-// GetTodo mocks base method.
-func (m *MockStore) GetTodo(arg0 context.Context, arg1 int32) (db.GetTodoRow, error) {
+// MoveTodosToCategory mocks base method.
+func (m *MockStore) MoveTodosToCategory(arg0 context.Context, arg1 db.MoveTodosToCategoryParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTodo", arg0, arg1)
-	ret0, _ := ret[0].(db.GetTodoRow)
+	ret := m.ctrl.Call(m, "MoveTodosToCategory", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTodo indicates an expected call of GetTodo.
-func (mr *MockStoreMockRecorder) GetTodo(arg0, arg1 interface{}) *gomock.Call {
+// MoveTodosToCategory indicates an expected call of MoveTodosToCategory.
+func (mr *MockStoreMockRecorder) MoveTodosToCategory(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTodo", reflect.TypeOf((*MockStore)(nil).GetTodo), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveTodosToCategory", reflect.TypeOf((*MockStore)(nil).MoveTodosToCategory), arg0, arg1)
 }
 
-// GetUser mocks base method.
-func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+// PatchTodo mocks base method.
+func (m *MockStore) PatchTodo(arg0 context.Context, arg1 db.PatchTodoParams) (db.Todo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "PatchTodo", arg0, arg1)
+	ret0, _ := ret[0].(db.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+// PatchTodo indicates an expected call of PatchTodo.
+func (mr *MockStoreMockRecorder) PatchTodo(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchTodo", reflect.TypeOf((*MockStore)(nil).PatchTodo), arg0, arg1)
 }
 
-// ListCategories mocks base method.
-func (m *MockStore) ListCategories(arg0 context.Context, arg1 db.ListCategoriesParams) ([]db.Category, error) {
+// PatchTodoTx mocks base method.
+func (m *MockStore) PatchTodoTx(arg0 context.Context, arg1 db.PatchTodoTxParams) (db.TodoTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListCategories", arg0, arg1)
-	ret0, _ := ret[0].([]db.Category)
+	ret := m.ctrl.Call(m, "PatchTodoTx", arg0, arg1)
+	ret0, _ := ret[0].(db.TodoTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListCategories indicates an expected call of ListCategories.
-func (mr *MockStoreMockRecorder) ListCategories(arg0, arg1 interface{}) *gomock.Call {
+// PatchTodoTx indicates an expected call of PatchTodoTx.
+func (mr *MockStoreMockRecorder) PatchTodoTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategories", reflect.TypeOf((*MockStore)(nil).ListCategories), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchTodoTx", reflect.TypeOf((*MockStore)(nil).PatchTodoTx), arg0, arg1)
 }
 
-// ListDoneTodo mocks base method.
-func (m *MockStore) ListDoneTodo(arg0 context.Context, arg1 db.ListDoneTodoParams) ([]db.ListDoneTodoRow, error) {
+// Ping mocks base method.
+func (m *MockStore) Ping(arg0 context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListDoneTodo", arg0, arg1)
-	ret0, _ := ret[0].([]db.ListDoneTodoRow)
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockStoreMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStore)(nil).Ping), arg0)
+}
+
+// PurgeExpiredIdempotencyKeys mocks base method.
+func (m *MockStore) PurgeExpiredIdempotencyKeys(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpiredIdempotencyKeys", arg0)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListDoneTodo indicates an expected call of ListDoneTodo.
-func (mr *MockStoreMockRecorder) ListDoneTodo(arg0, arg1 interface{}) *gomock.Call {
+// PurgeExpiredIdempotencyKeys indicates an expected call of PurgeExpiredIdempotencyKeys.
+func (mr *MockStoreMockRecorder) PurgeExpiredIdempotencyKeys(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDoneTodo", reflect.TypeOf((*MockStore)(nil).ListDoneTodo), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpiredIdempotencyKeys", reflect.TypeOf((*MockStore)(nil).PurgeExpiredIdempotencyKeys), arg0)
 }
 
-// ListTodayTodo mocks base method.
-func (m *MockStore) ListTodayTodo(arg0 context.Context, arg1 db.ListTodayTodoParams) ([]db.ListTodayTodoRow, error) {
+// PurgeTrashedCategories mocks base method.
+func (m *MockStore) PurgeTrashedCategories(arg0 context.Context, arg1 time.Time) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListTodayTodo", arg0, arg1)
-	ret0, _ := ret[0].([]db.ListTodayTodoRow)
+	ret := m.ctrl.Call(m, "PurgeTrashedCategories", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListTodayTodo indicates an expected call of ListTodayTodo.
-func (mr *MockStoreMockRecorder) ListTodayTodo(arg0, arg1 interface{}) *gomock.Call {
+// PurgeTrashedCategories indicates an expected call of PurgeTrashedCategories.
+func (mr *MockStoreMockRecorder) PurgeTrashedCategories(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTodayTodo", reflect.TypeOf((*MockStore)(nil).ListTodayTodo), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrashedCategories", reflect.TypeOf((*MockStore)(nil).PurgeTrashedCategories), arg0, arg1)
 }
 
-// ListTodoByUser mocks base method.
-func (m *MockStore) ListTodoByUser(arg0 context.Context, arg1 db.ListTodoByUserParams) ([]db.ListTodoByUserRow, error) {
+// PurgeTrashedTodos mocks base method.
+func (m *MockStore) PurgeTrashedTodos(arg0 context.Context, arg1 time.Time) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListTodoByUser", arg0, arg1)
-	ret0, _ := ret[0].([]db.ListTodoByUserRow)
+	ret := m.ctrl.Call(m, "PurgeTrashedTodos", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListTodoByUser indicates an expected call of ListTodoByUser.
-func (mr *MockStoreMockRecorder) ListTodoByUser(arg0, arg1 interface{}) *gomock.Call {
+// PurgeTrashedTodos indicates an expected call of PurgeTrashedTodos.
+func (mr *MockStoreMockRecorder) PurgeTrashedTodos(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTodoByUser", reflect.TypeOf((*MockStore)(nil).ListTodoByUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrashedTodos", reflect.TypeOf((*MockStore)(nil).PurgeTrashedTodos), arg0, arg1)
 }
 
-// ListUpcomingTodo mocks base method.
-func (m *MockStore) ListUpcomingTodo(arg0 context.Context, arg1 db.ListUpcomingTodoParams) ([]db.ListUpcomingTodoRow, error) {
+// RescheduleTodo mocks base method.
+func (m *MockStore) RescheduleTodo(arg0 context.Context, arg1 db.RescheduleTodoParams) (db.Todo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListUpcomingTodo", arg0, arg1)
-	ret0, _ := ret[0].([]db.ListUpcomingTodoRow)
+	ret := m.ctrl.Call(m, "RescheduleTodo", arg0, arg1)
+	ret0, _ := ret[0].(db.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListUpcomingTodo indicates an expected call of ListUpcomingTodo.
-func (mr *MockStoreMockRecorder) ListUpcomingTodo(arg0, arg1 interface{}) *gomock.Call {
+// RescheduleTodo indicates an expected call of RescheduleTodo.
+func (mr *MockStoreMockRecorder) RescheduleTodo(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUpcomingTodo", reflect.TypeOf((*MockStore)(nil).ListUpcomingTodo), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleTodo", reflect.TypeOf((*MockStore)(nil).RescheduleTodo), arg0, arg1)
 }
 
-// ListUsers mocks base method.
-func (m *MockStore) ListUsers(arg0 context.Context, arg1 db.ListUsersParams) ([]db.User, error) {
+// RescheduleTodoReminders mocks base method.
+func (m *MockStore) RescheduleTodoReminders(arg0 context.Context, arg1 db.RescheduleTodoRemindersParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
-	ret0, _ := ret[0].([]db.User)
+	ret := m.ctrl.Call(m, "RescheduleTodoReminders", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RescheduleTodoReminders indicates an expected call of RescheduleTodoReminders.
+func (mr *MockStoreMockRecorder) RescheduleTodoReminders(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleTodoReminders", reflect.TypeOf((*MockStore)(nil).RescheduleTodoReminders), arg0, arg1)
+}
+
+// RestoreCategory mocks base method.
+func (m *MockStore) RestoreCategory(arg0 context.Context, arg1 int32) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreCategory", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListUsers indicates an expected call of ListUsers.
-func (mr *MockStoreMockRecorder) ListUsers(arg0, arg1 interface{}) *gomock.Call {
+// RestoreCategory indicates an expected call of RestoreCategory.
+func (mr *MockStoreMockRecorder) RestoreCategory(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockStore)(nil).ListUsers), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCategory", reflect.TypeOf((*MockStore)(nil).RestoreCategory), arg0, arg1)
 }
 
-// MarkAsCompleteTodo mocks base method.
-func (m *MockStore) MarkAsCompleteTodo(arg0 context.Context, arg1 int32) (db.Todo, error) {
+// RestoreTodo mocks base method.
+func (m *MockStore) RestoreTodo(arg0 context.Context, arg1 db.RestoreTodoParams) (db.Todo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "MarkAsCompleteTodo", arg0, arg1)
+	ret := m.ctrl.Call(m, "RestoreTodo", arg0, arg1)
 	ret0, _ := ret[0].(db.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// MarkAsCompleteTodo indicates an expected call of MarkAsCompleteTodo.
-func (mr *MockStoreMockRecorder) MarkAsCompleteTodo(arg0, arg1 interface{}) *gomock.Call {
+// RestoreTodo indicates an expected call of RestoreTodo.
+func (mr *MockStoreMockRecorder) RestoreTodo(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsCompleteTodo", reflect.TypeOf((*MockStore)(nil).MarkAsCompleteTodo), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTodo", reflect.TypeOf((*MockStore)(nil).RestoreTodo), arg0, arg1)
+}
+
+// SaveIdempotencyResponse mocks base method.
+func (m *MockStore) SaveIdempotencyResponse(arg0 context.Context, arg1 db.SaveIdempotencyResponseParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveIdempotencyResponse", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveIdempotencyResponse indicates an expected call of SaveIdempotencyResponse.
+func (mr *MockStoreMockRecorder) SaveIdempotencyResponse(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveIdempotencyResponse", reflect.TypeOf((*MockStore)(nil).SaveIdempotencyResponse), arg0, arg1)
+}
+
+// SetCalendarToken mocks base method.
+func (m *MockStore) SetCalendarToken(arg0 context.Context, arg1 db.SetCalendarTokenParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCalendarToken", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCalendarToken indicates an expected call of SetCalendarToken.
+func (mr *MockStoreMockRecorder) SetCalendarToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCalendarToken", reflect.TypeOf((*MockStore)(nil).SetCalendarToken), arg0, arg1)
+}
+
+// SetTodoPriority mocks base method.
+func (m *MockStore) SetTodoPriority(arg0 context.Context, arg1 db.SetTodoPriorityParams) (db.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTodoPriority", arg0, arg1)
+	ret0, _ := ret[0].(db.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTodoPriority indicates an expected call of SetTodoPriority.
+func (mr *MockStoreMockRecorder) SetTodoPriority(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTodoPriority", reflect.TypeOf((*MockStore)(nil).SetTodoPriority), arg0, arg1)
+}
+
+// SyncTx mocks base method.
+func (m *MockStore) SyncTx(arg0 context.Context, arg1 db.SyncTxParams) (db.SyncTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncTx", arg0, arg1)
+	ret0, _ := ret[0].(db.SyncTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncTx indicates an expected call of SyncTx.
+func (mr *MockStoreMockRecorder) SyncTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncTx", reflect.TypeOf((*MockStore)(nil).SyncTx), arg0, arg1)
 }
 
 // UpdateCategory mocks base method.
@@ -287,6 +1372,36 @@ func (mr *MockStoreMockRecorder) UpdateCategory(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategory", reflect.TypeOf((*MockStore)(nil).UpdateCategory), arg0, arg1)
 }
 
+// UpdateCategoryTx mocks base method.
+func (m *MockStore) UpdateCategoryTx(arg0 context.Context, arg1 db.UpdateCategoryTxParams) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCategoryTx", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCategoryTx indicates an expected call of UpdateCategoryTx.
+func (mr *MockStoreMockRecorder) UpdateCategoryTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategoryTx", reflect.TypeOf((*MockStore)(nil).UpdateCategoryTx), arg0, arg1)
+}
+
+// UpdateTag mocks base method.
+func (m *MockStore) UpdateTag(arg0 context.Context, arg1 db.UpdateTagParams) (db.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTag", arg0, arg1)
+	ret0, _ := ret[0].(db.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTag indicates an expected call of UpdateTag.
+func (mr *MockStoreMockRecorder) UpdateTag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTag", reflect.TypeOf((*MockStore)(nil).UpdateTag), arg0, arg1)
+}
+
 // UpdateTodoByUser mocks base method.
 func (m *MockStore) UpdateTodoByUser(arg0 context.Context, arg1 db.UpdateTodoByUserParams) (db.Todo, error) {
 	m.ctrl.T.Helper()
@@ -302,6 +1417,21 @@ func (mr *MockStoreMockRecorder) UpdateTodoByUser(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTodoByUser", reflect.TypeOf((*MockStore)(nil).UpdateTodoByUser), arg0, arg1)
 }
 
+// UpdateTodoTx mocks base method.
+func (m *MockStore) UpdateTodoTx(arg0 context.Context, arg1 db.UpdateTodoTxParams) (db.TodoTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTodoTx", arg0, arg1)
+	ret0, _ := ret[0].(db.TodoTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTodoTx indicates an expected call of UpdateTodoTx.
+func (mr *MockStoreMockRecorder) UpdateTodoTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTodoTx", reflect.TypeOf((*MockStore)(nil).UpdateTodoTx), arg0, arg1)
+}
+
 // UpdateUser mocks base method.
 func (m *MockStore) UpdateUser(arg0 context.Context, arg1 db.UpdateUserParams) (db.User, error) {
 	m.ctrl.T.Helper()
@@ -331,3 +1461,18 @@ func (mr *MockStoreMockRecorder) UpdateUserPhoto(arg0, arg1 interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPhoto", reflect.TypeOf((*MockStore)(nil).UpdateUserPhoto), arg0, arg1)
 }
+
+// UpdateUserTimeZone mocks base method.
+func (m *MockStore) UpdateUserTimeZone(arg0 context.Context, arg1 db.UpdateUserTimeZoneParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTimeZone", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserTimeZone indicates an expected call of UpdateUserTimeZone.
+func (mr *MockStoreMockRecorder) UpdateUserTimeZone(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTimeZone", reflect.TypeOf((*MockStore)(nil).UpdateUserTimeZone), arg0, arg1)
+}
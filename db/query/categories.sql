@@ -7,6 +7,7 @@ INSERT INTO categories (
 
 -- name: ListCategories :many
 SELECT * FROM categories
+WHERE deleted_at IS NULL
 ORDER BY id
 LIMIT $1
 OFFSET $2;
@@ -14,13 +15,66 @@ OFFSET $2;
 -- name: UpdateCategory :one
 UPDATE categories
 SET name = $2
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 RETURNING *;
 
 -- name: DeleteCategory :exec
-DELETE FROM categories
-WHERE id = $1;
+UPDATE categories
+SET deleted_at = now()
+WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: GetCategory :one
 SELECT * FROM categories
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: ListTrashedCategories :many
+SELECT * FROM categories
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT $1
+OFFSET $2;
+
+-- name: RestoreCategory :one
+UPDATE categories
+SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: PurgeTrashedCategories :execrows
+DELETE FROM categories c
+WHERE c.deleted_at < sqlc.arg(deleted_before)::timestamp
+    AND NOT EXISTS (
+        SELECT 1 FROM todos t WHERE t.category_id = c.id
+    );
+
+-- name: GetCategoryForUpdate :one
+SELECT * FROM categories
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: GetCategoryByName :one
+SELECT * FROM categories
+WHERE name = $1 AND deleted_at IS NULL
+ORDER BY id
+LIMIT 1;
+
+-- name: GetDefaultCategory :one
+SELECT * FROM categories
+WHERE is_default;
+
+-- name: CreateDefaultCategory :one
+INSERT INTO categories (
+    name, is_default
+) VALUES (
+    $1, true
+) RETURNING *;
+
+-- name: GetAnyCategoryForUpdate :one
+SELECT * FROM categories
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListCategoriesByIDs :many
+SELECT * FROM categories
+WHERE id = ANY(sqlc.arg(ids)::int[])
+ORDER BY id;
@@ -0,0 +1,34 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+    user_email,
+    key,
+    request_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (user_email, key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash,
+    response_status = 0,
+    response_body = '',
+    created_at = now(),
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at < now()
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE user_email = $1 AND key = $2;
+
+-- name: SaveIdempotencyResponse :exec
+UPDATE idempotency_keys
+SET response_status = $3, response_body = $4
+WHERE user_email = $1 AND key = $2;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_email = $1 AND key = $2;
+
+-- name: PurgeExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at < now();
@@ -0,0 +1,64 @@
+-- name: CreateReminder :one
+INSERT INTO reminders (
+    todo_id,
+    user_email,
+    remind_at,
+    offset_minutes,
+    channel,
+    target,
+    next_attempt_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $3
+) RETURNING *;
+
+-- name: ListRemindersByTodo :many
+SELECT * FROM reminders
+WHERE todo_id = $1 AND user_email = $2
+ORDER BY remind_at;
+
+-- name: DeleteReminder :execrows
+DELETE FROM reminders
+WHERE id = $1 AND todo_id = $2 AND user_email = $3;
+
+-- name: RescheduleTodoReminders :exec
+UPDATE reminders
+SET remind_at = sqlc.arg(due_at)::timestamptz - offset_minutes * interval '1 minute',
+    next_attempt_at = sqlc.arg(due_at)::timestamptz - offset_minutes * interval '1 minute'
+WHERE todo_id = sqlc.arg(todo_id)
+    AND offset_minutes IS NOT NULL
+    AND status = 'pending';
+
+-- name: ClaimDueReminders :many
+UPDATE reminders r
+SET next_attempt_at = sqlc.arg(lease_until)
+FROM todos t
+WHERE t.id = r.todo_id
+    AND r.id IN (
+        SELECT due.id
+        FROM reminders due
+        INNER JOIN todos dt
+            ON dt.id = due.todo_id
+        WHERE due.status = 'pending'
+            AND due.next_attempt_at <= now()
+            AND dt.deleted_at IS NULL
+            AND dt.status = FALSE
+        ORDER BY due.next_attempt_at
+        LIMIT sqlc.arg(max_rows)
+        FOR UPDATE OF due SKIP LOCKED
+    )
+RETURNING r.id, r.todo_id, r.user_email, r.remind_at, r.channel, r.target, r.attempts, t.title, t.date;
+
+-- name: MarkReminderSent :exec
+UPDATE reminders
+SET status = 'sent', attempts = attempts + 1, last_error = '', sent_at = now()
+WHERE id = $1;
+
+-- name: MarkReminderRetry :exec
+UPDATE reminders
+SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+WHERE id = $1;
+
+-- name: MarkReminderFailed :exec
+UPDATE reminders
+SET status = 'failed', attempts = attempts + 1, last_error = $2
+WHERE id = $1;
@@ -0,0 +1,35 @@
+-- name: GetSyncHorizon :one
+SELECT txid_snapshot_xmin(txid_current_snapshot())::bigint AS horizon;
+
+-- name: ListTodoChangesSince :many
+SELECT
+    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.all_day, t.color, t.is_priority, t.status,
+    t.version, t.deleted_at, t.change_seq, t.change_txid,
+    COALESCE((
+        SELECT json_agg(json_build_object('id', tg.id, 'name', tg.name) ORDER BY tg.name)
+        FROM todo_tags tt
+        INNER JOIN tags tg
+            ON tg.id = tt.tag_id
+        WHERE tt.todo_id = t.id
+    ), '[]')::json as tags
+FROM todos t
+WHERE t.user_email = sqlc.arg(user_email)
+    AND (t.change_txid, t.change_seq) > (sqlc.arg(since_txid)::bigint, sqlc.arg(since_seq)::bigint)
+    AND t.change_txid < sqlc.arg(horizon)::bigint
+ORDER BY t.change_txid, t.change_seq
+LIMIT sqlc.arg(max_rows);
+
+-- name: ListCategoryChangesSince :many
+SELECT * FROM categories
+WHERE (change_txid, change_seq) > (sqlc.arg(since_txid)::bigint, sqlc.arg(since_seq)::bigint)
+    AND change_txid < sqlc.arg(horizon)::bigint
+ORDER BY change_txid, change_seq
+LIMIT sqlc.arg(max_rows);
+
+-- name: ListTombstonesSince :many
+SELECT * FROM tombstones
+WHERE (change_txid, change_seq) > (sqlc.arg(since_txid)::bigint, sqlc.arg(since_seq)::bigint)
+    AND change_txid < sqlc.arg(horizon)::bigint
+    AND (user_email = sqlc.arg(user_email)::varchar OR user_email IS NULL)
+ORDER BY change_txid, change_seq
+LIMIT sqlc.arg(max_rows);
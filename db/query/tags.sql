@@ -0,0 +1,72 @@
+-- name: CreateTag :one
+INSERT INTO tags (
+    user_email,
+    name
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: ListTags :many
+SELECT * FROM tags
+WHERE user_email = $1
+ORDER BY name
+LIMIT $2
+OFFSET $3;
+
+-- name: UpdateTag :one
+WITH touched AS (
+    UPDATE todos
+    SET updated_at = now()
+    WHERE id IN (
+        SELECT tt.todo_id
+        FROM todo_tags tt
+        INNER JOIN tags tg
+            ON tg.id = tt.tag_id
+        WHERE tg.id = $1 AND tg.user_email = $2 AND tg.name <> $3
+    )
+)
+UPDATE tags
+SET name = $3
+WHERE id = $1 AND user_email = $2
+RETURNING id, user_email, name, created_at;
+
+-- name: DeleteTag :execrows
+WITH touched AS (
+    UPDATE todos
+    SET updated_at = now()
+    WHERE id IN (
+        SELECT tt.todo_id
+        FROM todo_tags tt
+        INNER JOIN tags tg
+            ON tg.id = tt.tag_id
+        WHERE tg.id = $1 AND tg.user_email = $2
+    )
+)
+DELETE FROM tags
+WHERE id = $1 AND user_email = $2;
+
+-- name: CountTagsByUser :one
+SELECT count(*) FROM tags
+WHERE user_email = sqlc.arg(user_email)
+    AND id = ANY(sqlc.arg(ids)::int[]);
+
+-- name: ListTagsByTodo :many
+SELECT tg.id, tg.user_email, tg.name, tg.created_at
+FROM tags tg
+INNER JOIN todo_tags tt
+    ON tt.tag_id = tg.id
+WHERE tt.todo_id = $1
+ORDER BY tg.name;
+
+-- name: AddTodoTags :exec
+INSERT INTO todo_tags (todo_id, tag_id)
+SELECT sqlc.arg(todo_id)::int, unnest(sqlc.arg(tag_ids)::int[])
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteTodoTags :exec
+DELETE FROM todo_tags
+WHERE todo_id = $1;
+
+-- name: GetTagByName :one
+SELECT * FROM tags
+WHERE user_email = $1 AND name = $2;
@@ -6,88 +6,257 @@ INSERT INTO todos (
     content,
     date,
     color,
-    is_priority
+    is_priority,
+    all_day
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
 -- name: ListTodoByUser :many
 SELECT
-    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.color, t.is_priority, t.status,
-    c.name as category_name
+    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.all_day, t.color, t.is_priority, t.status,
+    c.name as category_name,
+    COALESCE((
+        SELECT json_agg(json_build_object('id', tg.id, 'name', tg.name) ORDER BY tg.name)
+        FROM todo_tags tt
+        INNER JOIN tags tg
+            ON tg.id = tt.tag_id
+        WHERE tt.todo_id = t.id
+    ), '[]')::json as tags
 FROM todos t
 INNER JOIN categories c
     ON c.id = t.category_id
 WHERE t.user_email = $1 
-
-ORDER BY created_at ASC
+    AND t.deleted_at IS NULL
+    AND ($4 = 0 OR EXISTS (
+        SELECT 1 FROM todo_tags tt WHERE tt.todo_id = t.id AND tt.tag_id = $4
+    ))
+ORDER BY t.created_at ASC
 LIMIT $2
 OFFSET $3;
 
 -- name: ListTodayTodo :many
 SELECT
-    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.color, t.is_priority, t.status,
-    c.name as category_name
+    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.all_day, t.color, t.is_priority, t.status,
+    c.name as category_name,
+    COALESCE((
+        SELECT json_agg(json_build_object('id', tg.id, 'name', tg.name) ORDER BY tg.name)
+        FROM todo_tags tt
+        INNER JOIN tags tg
+            ON tg.id = tt.tag_id
+        WHERE tt.todo_id = t.id
+    ), '[]')::json as tags
 FROM todos t
 INNER JOIN categories c
     ON c.id = t.category_id
+INNER JOIN users u
+    ON u.email = t.user_email
 WHERE t.user_email = $1 
-    AND date <= now() 
+    AND (t.date AT TIME ZONE u.time_zone)::date <= (now() AT TIME ZONE u.time_zone)::date
     AND status = FALSE 
+    AND t.deleted_at IS NULL
+    AND ($4 = 0 OR EXISTS (
+        SELECT 1 FROM todo_tags tt WHERE tt.todo_id = t.id AND tt.tag_id = $4
+    ))
 ORDER BY is_priority DESC
 LIMIT $2
 OFFSET $3;
 
 -- name: ListUpcomingTodo :many
 SELECT
-    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.color, t.is_priority, t.status,
-    c.name as category_name
+    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.all_day, t.color, t.is_priority, t.status,
+    c.name as category_name,
+    COALESCE((
+        SELECT json_agg(json_build_object('id', tg.id, 'name', tg.name) ORDER BY tg.name)
+        FROM todo_tags tt
+        INNER JOIN tags tg
+            ON tg.id = tt.tag_id
+        WHERE tt.todo_id = t.id
+    ), '[]')::json as tags
 FROM todos t
 INNER JOIN categories c
     ON c.id = t.category_id
+INNER JOIN users u
+    ON u.email = t.user_email
 WHERE t.user_email = $1 
-    AND date > now() 
+    AND (t.date AT TIME ZONE u.time_zone)::date > (now() AT TIME ZONE u.time_zone)::date
     AND status = FALSE 
-ORDER BY is_priority DESC, date ASC
+    AND t.deleted_at IS NULL
+    AND ($4 = 0 OR EXISTS (
+        SELECT 1 FROM todo_tags tt WHERE tt.todo_id = t.id AND tt.tag_id = $4
+    ))
+ORDER BY is_priority DESC, t.date ASC
 LIMIT $2
 OFFSET $3;
 
 -- name: ListDoneTodo :many
 SELECT
-    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.color, t.is_priority, t.status,
-    c.name as category_name
+    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.all_day, t.color, t.is_priority, t.status,
+    c.name as category_name,
+    COALESCE((
+        SELECT json_agg(json_build_object('id', tg.id, 'name', tg.name) ORDER BY tg.name)
+        FROM todo_tags tt
+        INNER JOIN tags tg
+            ON tg.id = tt.tag_id
+        WHERE tt.todo_id = t.id
+    ), '[]')::json as tags
 FROM todos t
 INNER JOIN categories c
     ON c.id = t.category_id
 WHERE t.user_email = $1 
     AND status = TRUE 
-ORDER BY date DESC
+    AND t.deleted_at IS NULL
+    AND ($4 = 0 OR EXISTS (
+        SELECT 1 FROM todo_tags tt WHERE tt.todo_id = t.id AND tt.tag_id = $4
+    ))
+ORDER BY t.date DESC
 LIMIT $2
 OFFSET $3;
 
 
 -- name: GetTodo :one
 SELECT
-    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.color, t.is_priority,
-    c.name as category_name
+    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.all_day, t.color, t.is_priority, t.version,
+    c.name as category_name,
+    COALESCE((
+        SELECT json_agg(json_build_object('id', tg.id, 'name', tg.name) ORDER BY tg.name)
+        FROM todo_tags tt
+        INNER JOIN tags tg
+            ON tg.id = tt.tag_id
+        WHERE tt.todo_id = t.id
+    ), '[]')::json as tags
 FROM todos t
 INNER JOIN categories c
     ON c.id = t.category_id
-WHERE t.id = $1 LIMIT 1
+WHERE t.id = $1 AND t.deleted_at IS NULL LIMIT 1
 FOR NO KEY UPDATE;
 
 -- name: UpdateTodoByUser :one
 UPDATE todos
-SET category_id = $2, title = $3, content = $4, updated_at = now(), date = $5, color = $6, is_priority = $7
-WHERE id = $1
+SET category_id = $2, title = $3, content = $4, updated_at = now(), date = $5, all_day = $8, color = $6, is_priority = $7
+WHERE id = $1 AND deleted_at IS NULL
 RETURNING *;
 
 -- name: DeleteTodo :exec
-DELETE FROM todos
-WHERE id = $1;
+UPDATE todos
+SET deleted_at = now()
+WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: MarkAsCompleteTodo :one
 UPDATE todos
 SET status = true
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ListTrashedTodo :many
+SELECT * FROM todos
+WHERE user_email = $1
+    AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: RestoreTodo :one
+UPDATE todos
+SET deleted_at = NULL
 WHERE id = $1
+    AND user_email = $2
+    AND deleted_at IS NOT NULL
 RETURNING *;
+
+-- name: PurgeTrashedTodos :execrows
+DELETE FROM todos
+WHERE deleted_at < sqlc.arg(deleted_before)::timestamp;
+
+-- name: CountTodosByCategory :one
+SELECT count(*) FROM todos
+WHERE category_id = $1 AND deleted_at IS NULL;
+
+-- name: MoveTodosToCategory :execrows
+UPDATE todos
+SET category_id = sqlc.arg(to_category_id), updated_at = now()
+WHERE category_id = sqlc.arg(from_category_id) AND deleted_at IS NULL;
+
+-- name: ListOpenTodosForCalendar :many
+SELECT
+    t.id, t.title, t.content, t.created_at, t.updated_at, t.date, t.all_day, t.is_priority, t.status,
+    c.name as category_name
+FROM todos t
+INNER JOIN categories c
+    ON c.id = t.category_id
+WHERE t.user_email = $1
+    AND t.deleted_at IS NULL
+    AND t.status = false
+ORDER BY t.date ASC;
+
+-- name: ListTodosForExport :many
+SELECT
+    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.all_day, t.color, t.is_priority, t.status,
+    c.name as category_name,
+    COALESCE((
+        SELECT json_agg(json_build_object('id', tg.id, 'name', tg.name) ORDER BY tg.name)
+        FROM todo_tags tt
+        INNER JOIN tags tg
+            ON tg.id = tt.tag_id
+        WHERE tt.todo_id = t.id
+    ), '[]')::json as tags
+FROM todos t
+INNER JOIN categories c
+    ON c.id = t.category_id
+WHERE t.user_email = $1
+    AND t.deleted_at IS NULL
+ORDER BY t.created_at ASC, t.id ASC;
+
+-- name: ListTodosForUpdate :many
+SELECT * FROM todos
+WHERE id = ANY(sqlc.arg(ids)::int[]) AND deleted_at IS NULL
+ORDER BY id
+FOR NO KEY UPDATE;
+
+-- name: GetTodoForUpdate :one
+SELECT * FROM todos
+WHERE id = $1 AND deleted_at IS NULL
+FOR NO KEY UPDATE;
+
+-- name: MarkAsIncompleteTodo :one
+UPDATE todos
+SET status = false
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: MoveTodo :one
+UPDATE todos
+SET category_id = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SetTodoPriority :one
+UPDATE todos
+SET is_priority = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: RescheduleTodo :one
+UPDATE todos
+SET date = $2, all_day = $3, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: PatchTodo :one
+UPDATE todos
+SET
+    category_id = COALESCE(sqlc.narg(category_id), category_id),
+    title = COALESCE(sqlc.narg(title), title),
+    content = COALESCE(sqlc.narg(content), content),
+    date = COALESCE(sqlc.narg(date), date),
+    all_day = COALESCE(sqlc.narg(all_day), all_day),
+    color = COALESCE(sqlc.narg(color), color),
+    is_priority = COALESCE(sqlc.narg(is_priority), is_priority),
+    updated_at = now()
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL
+RETURNING *;
+
+-- name: GetAnyTodoForUpdate :one
+SELECT * FROM todos
+WHERE id = $1
+FOR NO KEY UPDATE;
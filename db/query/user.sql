@@ -4,9 +4,10 @@ INSERT INTO users (
     address,
     pic,
     hashed_password,
-    email
+    email,
+    time_zone
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
 -- name: ListUsers :many
@@ -34,3 +35,19 @@ RETURNING *;
 -- name: DeleteUser :exec
 DELETE FROM users
 WHERE id = $1;
+
+-- name: UpdateUserTimeZone :one
+UPDATE users
+SET time_zone = $2, updated_at = now()
+WHERE email = $1
+RETURNING *;
+
+-- name: SetCalendarToken :one
+UPDATE users
+SET calendar_token = $2, updated_at = now()
+WHERE email = $1
+RETURNING *;
+
+-- name: GetUserByCalendarToken :one
+SELECT * FROM users
+WHERE calendar_token = $1;
@@ -0,0 +1,76 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (
+    user_email,
+    url,
+    secret,
+    events
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListWebhooks :many
+SELECT * FROM webhooks
+WHERE user_email = $1
+ORDER BY id;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = $1 AND user_email = $2;
+
+-- name: EnqueueWebhookEvent :execrows
+INSERT INTO webhook_deliveries (
+    webhook_id,
+    event,
+    payload
+)
+SELECT w.id, sqlc.arg(event), sqlc.arg(payload)
+FROM webhooks w
+WHERE w.user_email = sqlc.arg(user_email)
+    AND w.active
+    AND (
+        sqlc.arg(event)::text = ANY(w.events)
+        OR split_part(sqlc.arg(event)::text, '.', 1) || '.*' = ANY(w.events)
+    );
+
+-- name: ListWebhookDeliveries :many
+SELECT
+    d.id, d.webhook_id, d.event, d.payload, d.status, d.attempts, d.response_status,
+    d.last_error, d.next_attempt_at, d.delivered_at, d.created_at
+FROM webhook_deliveries d
+INNER JOIN webhooks w
+    ON w.id = d.webhook_id
+WHERE d.webhook_id = $1 AND w.user_email = $2
+ORDER BY d.id DESC
+LIMIT $3
+OFFSET $4;
+
+-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries d
+SET next_attempt_at = sqlc.arg(lease_until)
+FROM webhooks w
+WHERE w.id = d.webhook_id
+    AND d.id IN (
+        SELECT due.id
+        FROM webhook_deliveries due
+        WHERE due.status = 'pending'
+            AND due.next_attempt_at <= now()
+        ORDER BY due.next_attempt_at
+        LIMIT sqlc.arg(max_rows)
+        FOR UPDATE OF due SKIP LOCKED
+    )
+RETURNING d.id, d.webhook_id, d.event, d.payload, d.attempts, w.url, w.secret;
+
+-- name: MarkWebhookDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered', attempts = attempts + 1, response_status = $2, last_error = '', delivered_at = now()
+WHERE id = $1;
+
+-- name: MarkWebhookRetry :exec
+UPDATE webhook_deliveries
+SET attempts = attempts + 1, response_status = $2, last_error = $3, next_attempt_at = $4
+WHERE id = $1;
+
+-- name: MarkWebhookFailed :exec
+UPDATE webhook_deliveries
+SET status = 'failed', attempts = attempts + 1, response_status = $2, last_error = $3
+WHERE id = $1;
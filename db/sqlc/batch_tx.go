@@ -0,0 +1,167 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	BatchOpComplete    = "complete"
+	BatchOpReopen      = "reopen"
+	BatchOpDelete      = "delete"
+	BatchOpMove        = "move"
+	BatchOpSetPriority = "set_priority"
+	BatchOpReschedule  = "reschedule"
+)
+
+var (
+	ErrTodoNotFound    = errors.New("todo-not-found")
+	ErrWrongUser       = errors.New("wrong-user")
+	ErrInvalidCategory = errors.New("invalid-category")
+)
+
+// BatchTodoOperation applies Op to every todo in TodoIDs. Only the fields the
+// operation needs are read.
+type BatchTodoOperation struct {
+	Op         string    `json:"op"`
+	TodoIDs    []int32   `json:"todo_ids"`
+	CategoryID int32     `json:"category_id"`
+	IsPriority bool      `json:"is_priority"`
+	Date       time.Time `json:"date"`
+	AllDay     bool      `json:"all_day"`
+}
+
+type BatchTodoTxParams struct {
+	UserEmail  string               `json:"user_email"`
+	Operations []BatchTodoOperation `json:"operations"`
+}
+
+type BatchTodoItemResult struct {
+	Operation int    `json:"operation"`
+	Op        string `json:"op"`
+	TodoID    int32  `json:"todo_id"`
+	// Todo is the todo after the operation; it is nil for deletes and failures.
+	Todo  *Todo  `json:"todo,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type BatchTodoTxResult struct {
+	Results []BatchTodoItemResult `json:"results"`
+}
+
+// BatchTodoTx runs the operations in order in a single transaction. Todos
+// that don't exist or belong to someone else are reported and skipped; any
+// other error rolls the whole batch back.
+func (store *SQLStore) BatchTodoTx(ctx context.Context, arg BatchTodoTxParams) (BatchTodoTxResult, error) {
+	var result BatchTodoTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		result.Results = []BatchTodoItemResult{}
+
+		var ids []int32
+		for _, op := range arg.Operations {
+			ids = append(ids, op.TodoIDs...)
+		}
+
+		// locked in id order so concurrent batches can't deadlock
+		locked, err := q.ListTodosForUpdate(ctx, uniqueIDs(ids))
+		if err != nil {
+			return err
+		}
+		todos := make(map[int32]Todo, len(locked))
+		for _, todo := range locked {
+			todos[todo.ID] = todo
+		}
+
+		for i, op := range arg.Operations {
+			var categoryErr error
+			if op.Op == BatchOpMove {
+				_, err := q.GetCategory(ctx, op.CategoryID)
+				if err == sql.ErrNoRows {
+					categoryErr = ErrInvalidCategory
+				} else if err != nil {
+					return err
+				}
+			}
+
+			for _, id := range op.TodoIDs {
+				item := BatchTodoItemResult{Operation: i, Op: op.Op, TodoID: id}
+
+				todo, ok := todos[id]
+				switch {
+				case !ok:
+					item.Error = ErrTodoNotFound.Error()
+				case todo.UserEmail != arg.UserEmail:
+					item.Error = ErrWrongUser.Error()
+				case categoryErr != nil:
+					item.Error = categoryErr.Error()
+				}
+				if item.Error != "" {
+					result.Results = append(result.Results, item)
+					continue
+				}
+
+				todo, err := applyBatchTodoOperation(ctx, q, op, todo)
+				if err != nil {
+					return err
+				}
+
+				if op.Op == BatchOpDelete {
+					delete(todos, id)
+				} else {
+					todos[id] = todo
+					item.Todo = &todo
+				}
+				result.Results = append(result.Results, item)
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+func applyBatchTodoOperation(ctx context.Context, q *Queries, op BatchTodoOperation, todo Todo) (Todo, error) {
+	var err error
+	event := EventTodoUpdated
+
+	switch op.Op {
+	case BatchOpComplete:
+		event = EventTodoCompleted
+		todo, err = q.MarkAsCompleteTodo(ctx, todo.ID)
+	case BatchOpReopen:
+		todo, err = q.MarkAsIncompleteTodo(ctx, todo.ID)
+	case BatchOpDelete:
+		err = q.DeleteTodo(ctx, todo.ID)
+		if err != nil {
+			return Todo{}, err
+		}
+		return todo, enqueueEvent(ctx, q, todo.UserEmail, EventTodoDeleted, DeleteTodoTxParams{
+			ID:        todo.ID,
+			UserEmail: todo.UserEmail,
+		})
+	case BatchOpMove:
+		todo, err = q.MoveTodo(ctx, MoveTodoParams{ID: todo.ID, CategoryID: op.CategoryID})
+	case BatchOpSetPriority:
+		todo, err = q.SetTodoPriority(ctx, SetTodoPriorityParams{ID: todo.ID, IsPriority: op.IsPriority})
+	case BatchOpReschedule:
+		todo, err = q.RescheduleTodo(ctx, RescheduleTodoParams{ID: todo.ID, Date: op.Date, AllDay: op.AllDay})
+		if err == nil {
+			err = q.RescheduleTodoReminders(ctx, RescheduleTodoRemindersParams{
+				DueAt:  todo.Date,
+				TodoID: todo.ID,
+			})
+		}
+	default:
+		return Todo{}, fmt.Errorf("unknown batch operation %q", op.Op)
+	}
+	if err != nil {
+		return Todo{}, err
+	}
+
+	return todo, enqueueEvent(ctx, q, todo.UserEmail, event, todo)
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchTodoTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	category := createRandomCategory(t)
+	target := createRandomCategory(t)
+
+	todo1 := createRandomTodo(t, user.Email, category.ID)
+	todo2 := createRandomTodo(t, user.Email, category.ID)
+	foreign := createRandomTodo(t, other.Email, category.ID)
+	due := time.Date(2020, 1, 2, 9, 30, 0, 0, time.UTC)
+
+	result, err := store.BatchTodoTx(context.Background(), BatchTodoTxParams{
+		UserEmail: user.Email,
+		Operations: []BatchTodoOperation{
+			{Op: BatchOpComplete, TodoIDs: []int32{todo1.ID, todo2.ID, foreign.ID}},
+			{Op: BatchOpMove, TodoIDs: []int32{todo1.ID}, CategoryID: target.ID},
+			{Op: BatchOpSetPriority, TodoIDs: []int32{todo1.ID}, IsPriority: true},
+			{Op: BatchOpReschedule, TodoIDs: []int32{todo1.ID}, Date: due},
+			{Op: BatchOpReopen, TodoIDs: []int32{todo1.ID}},
+			{Op: BatchOpDelete, TodoIDs: []int32{todo2.ID}},
+			{Op: BatchOpReopen, TodoIDs: []int32{todo2.ID}},
+			{Op: BatchOpMove, TodoIDs: []int32{todo1.ID}, CategoryID: -1},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Results, 10)
+
+	require.Equal(t, ErrWrongUser.Error(), result.Results[2].Error)
+	// the second reopen comes after the delete
+	require.Equal(t, ErrTodoNotFound.Error(), result.Results[8].Error)
+	require.Equal(t, ErrInvalidCategory.Error(), result.Results[9].Error)
+
+	got, err := store.GetTodo(context.Background(), todo1.ID)
+	require.NoError(t, err)
+	require.Equal(t, target.ID, got.CategoryID)
+	require.True(t, got.IsPriority)
+	require.False(t, got.AllDay)
+	require.WithinDuration(t, due, got.Date, time.Second)
+
+	completed, err := store.ListTodosForUpdate(context.Background(), []int32{todo1.ID, todo2.ID, foreign.ID})
+	require.NoError(t, err)
+	require.Len(t, completed, 2)
+	for _, todo := range completed {
+		// todo1 was reopened and the foreign todo was left alone
+		require.False(t, todo.Status)
+	}
+}
@@ -5,6 +5,9 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 const createCategory = `-- name: CreateCategory :one
@@ -12,7 +15,7 @@ INSERT INTO categories (
     name
 ) VALUES (
     $1
-) RETURNING id, name, created_at, updated_at
+) RETURNING id, name, created_at, updated_at, deleted_at, version, change_seq, change_txid, is_default
 `
 
 func (q *Queries) CreateCategory(ctx context.Context, name string) (Category, error) {
@@ -23,13 +26,44 @@ func (q *Queries) CreateCategory(ctx context.Context, name string) (Category, er
 		&i.Name,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
+		&i.IsDefault,
+	)
+	return i, err
+}
+
+const createDefaultCategory = `-- name: CreateDefaultCategory :one
+INSERT INTO categories (
+    name, is_default
+) VALUES (
+    $1, true
+) RETURNING id, name, created_at, updated_at, deleted_at, version, change_seq, change_txid, is_default
+`
+
+func (q *Queries) CreateDefaultCategory(ctx context.Context, name string) (Category, error) {
+	row := q.db.QueryRowContext(ctx, createDefaultCategory, name)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
+		&i.IsDefault,
 	)
 	return i, err
 }
 
 const deleteCategory = `-- name: DeleteCategory :exec
-DELETE FROM categories
-WHERE id = $1
+UPDATE categories
+SET deleted_at = now()
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) DeleteCategory(ctx context.Context, id int32) error {
@@ -37,9 +71,32 @@ func (q *Queries) DeleteCategory(ctx context.Context, id int32) error {
 	return err
 }
 
-const getCategory = `-- name: GetCategory :one
-SELECT id, name, created_at, updated_at FROM categories
+const getAnyCategoryForUpdate = `-- name: GetAnyCategoryForUpdate :one
+SELECT id, name, created_at, updated_at, deleted_at, version, change_seq, change_txid, is_default FROM categories
 WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetAnyCategoryForUpdate(ctx context.Context, id int32) (Category, error) {
+	row := q.db.QueryRowContext(ctx, getAnyCategoryForUpdate, id)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
+		&i.IsDefault,
+	)
+	return i, err
+}
+
+const getCategory = `-- name: GetCategory :one
+SELECT id, name, created_at, updated_at, deleted_at, version, change_seq, change_txid, is_default FROM categories
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetCategory(ctx context.Context, id int32) (Category, error) {
@@ -50,12 +107,87 @@ func (q *Queries) GetCategory(ctx context.Context, id int32) (Category, error) {
 		&i.Name,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
+		&i.IsDefault,
+	)
+	return i, err
+}
+
+const getCategoryByName = `-- name: GetCategoryByName :one
+SELECT id, name, created_at, updated_at, deleted_at, version, change_seq, change_txid, is_default FROM categories
+WHERE name = $1 AND deleted_at IS NULL
+ORDER BY id
+LIMIT 1
+`
+
+func (q *Queries) GetCategoryByName(ctx context.Context, name string) (Category, error) {
+	row := q.db.QueryRowContext(ctx, getCategoryByName, name)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
+		&i.IsDefault,
+	)
+	return i, err
+}
+
+const getCategoryForUpdate = `-- name: GetCategoryForUpdate :one
+SELECT id, name, created_at, updated_at, deleted_at, version, change_seq, change_txid, is_default FROM categories
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE
+`
+
+func (q *Queries) GetCategoryForUpdate(ctx context.Context, id int32) (Category, error) {
+	row := q.db.QueryRowContext(ctx, getCategoryForUpdate, id)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
+		&i.IsDefault,
+	)
+	return i, err
+}
+
+const getDefaultCategory = `-- name: GetDefaultCategory :one
+SELECT id, name, created_at, updated_at, deleted_at, version, change_seq, change_txid, is_default FROM categories
+WHERE is_default
+`
+
+func (q *Queries) GetDefaultCategory(ctx context.Context) (Category, error) {
+	row := q.db.QueryRowContext(ctx, getDefaultCategory)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
+		&i.IsDefault,
 	)
 	return i, err
 }
 
 const listCategories = `-- name: ListCategories :many
-SELECT id, name, created_at, updated_at FROM categories
+SELECT id, name, created_at, updated_at, deleted_at, version, change_seq, change_txid, is_default FROM categories
+WHERE deleted_at IS NULL
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -80,6 +212,11 @@ func (q *Queries) ListCategories(ctx context.Context, arg ListCategoriesParams)
 			&i.Name,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Version,
+			&i.ChangeSeq,
+			&i.ChangeTxid,
+			&i.IsDefault,
 		); err != nil {
 			return nil, err
 		}
@@ -94,11 +231,136 @@ func (q *Queries) ListCategories(ctx context.Context, arg ListCategoriesParams)
 	return items, nil
 }
 
+const listCategoriesByIDs = `-- name: ListCategoriesByIDs :many
+SELECT id, name, created_at, updated_at, deleted_at, version, change_seq, change_txid, is_default FROM categories
+WHERE id = ANY($1::int[])
+ORDER BY id
+`
+
+func (q *Queries) ListCategoriesByIDs(ctx context.Context, ids []int32) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listCategoriesByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Version,
+			&i.ChangeSeq,
+			&i.ChangeTxid,
+			&i.IsDefault,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTrashedCategories = `-- name: ListTrashedCategories :many
+SELECT id, name, created_at, updated_at, deleted_at, version, change_seq, change_txid, is_default FROM categories
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT $1
+OFFSET $2
+`
+
+type ListTrashedCategoriesParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListTrashedCategories(ctx context.Context, arg ListTrashedCategoriesParams) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listTrashedCategories, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Version,
+			&i.ChangeSeq,
+			&i.ChangeTxid,
+			&i.IsDefault,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeTrashedCategories = `-- name: PurgeTrashedCategories :execrows
+DELETE FROM categories c
+WHERE c.deleted_at < $1::timestamp
+    AND NOT EXISTS (
+        SELECT 1 FROM todos t WHERE t.category_id = c.id
+    )
+`
+
+func (q *Queries) PurgeTrashedCategories(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeTrashedCategories, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreCategory = `-- name: RestoreCategory :one
+UPDATE categories
+SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, name, created_at, updated_at, deleted_at, version, change_seq, change_txid, is_default
+`
+
+func (q *Queries) RestoreCategory(ctx context.Context, id int32) (Category, error) {
+	row := q.db.QueryRowContext(ctx, restoreCategory, id)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
+		&i.IsDefault,
+	)
+	return i, err
+}
+
 const updateCategory = `-- name: UpdateCategory :one
 UPDATE categories
 SET name = $2
-WHERE id = $1
-RETURNING id, name, created_at, updated_at
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, created_at, updated_at, deleted_at, version, change_seq, change_txid, is_default
 `
 
 type UpdateCategoryParams struct {
@@ -114,6 +376,11 @@ func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams)
 		&i.Name,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
+		&i.IsDefault,
 	)
 	return i, err
 }
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/maslow123/todoapp-services/util"
@@ -68,3 +69,20 @@ func TestDeleteCategory(t *testing.T) {
 	err := testQueries.DeleteCategory(context.Background(), category1.ID)
 	require.NoError(t, err)
 }
+
+func TestRestoreCategory(t *testing.T) {
+	category1 := createRandomCategory(t)
+	err := testQueries.DeleteCategory(context.Background(), category1.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetCategory(context.Background(), category1.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	category2, err := testQueries.RestoreCategory(context.Background(), category1.ID)
+	require.NoError(t, err)
+	require.Equal(t, category1.ID, category2.ID)
+	require.False(t, category2.DeletedAt.Valid)
+
+	_, err = testQueries.RestoreCategory(context.Background(), category1.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// DefaultCategoryName is the name the default category is created with. The
+// category is identified by its IsDefault flag, so it can be renamed.
+const DefaultCategoryName = "Uncategorized"
+
+var ErrDefaultCategory = errors.New("cannot-delete-default-category")
+
+// CategoryNotEmptyError is returned by DeleteCategoryTx when the category
+// still has todos and they were not asked to be moved.
+type CategoryNotEmptyError struct {
+	TodoCount int64
+}
+
+func (e *CategoryNotEmptyError) Error() string {
+	return fmt.Sprintf("category-has-%d-todos", e.TodoCount)
+}
+
+type CreateCategoryTxParams struct {
+	Name string `json:"name"`
+	// UserEmail is the user making the change, whose webhooks are notified.
+	UserEmail string `json:"user_email"`
+	// IsDefault creates the default category, if there is none yet.
+	IsDefault bool `json:"is_default"`
+}
+
+func (store *SQLStore) CreateCategoryTx(ctx context.Context, arg CreateCategoryTxParams) (Category, error) {
+	var category Category
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		if arg.IsDefault {
+			category, err = q.CreateDefaultCategory(ctx, arg.Name)
+		} else {
+			category, err = q.CreateCategory(ctx, arg.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		return enqueueEvent(ctx, q, arg.UserEmail, EventCategoryCreated, category)
+	})
+
+	return category, err
+}
+
+type UpdateCategoryTxParams struct {
+	UpdateCategoryParams
+	UserEmail string `json:"user_email"`
+	// Version, when set, must be the category's current version.
+	Version int32 `json:"version"`
+}
+
+func (store *SQLStore) UpdateCategoryTx(ctx context.Context, arg UpdateCategoryTxParams) (Category, error) {
+	var category Category
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		if arg.Version != 0 {
+			current, err := q.GetCategoryForUpdate(ctx, arg.ID)
+			if err != nil {
+				return err
+			}
+			if current.Version != arg.Version {
+				return ErrVersionMismatch
+			}
+		}
+
+		var err error
+		category, err = q.UpdateCategory(ctx, arg.UpdateCategoryParams)
+		if err != nil {
+			return err
+		}
+
+		return enqueueEvent(ctx, q, arg.UserEmail, EventCategoryUpdated, category)
+	})
+
+	return category, err
+}
+
+type DeleteCategoryTxParams struct {
+	CategoryID int32  `json:"category_id"`
+	MoveTodos  bool   `json:"move_todos"`
+	UserEmail  string `json:"user_email"`
+	// Version, when set, must be the category's current version.
+	Version int32 `json:"version"`
+}
+
+type DeleteCategoryTxResult struct {
+	MovedTodos int64 `json:"moved_todos"`
+}
+
+// DeleteCategoryTx trashes a category. Todos in it either block the delete
+// or are moved to the default category, depending on arg.MoveTodos.
+func (store *SQLStore) DeleteCategoryTx(ctx context.Context, arg DeleteCategoryTxParams) (DeleteCategoryTxResult, error) {
+	var result DeleteCategoryTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// the row lock conflicts with the FK check of concurrent todo inserts
+		category, err := q.GetCategoryForUpdate(ctx, arg.CategoryID)
+		if err != nil {
+			return err
+		}
+		if arg.Version != 0 && category.Version != arg.Version {
+			return ErrVersionMismatch
+		}
+		if category.IsDefault {
+			return ErrDefaultCategory
+		}
+
+		if !arg.MoveTodos {
+			count, err := q.CountTodosByCategory(ctx, category.ID)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return &CategoryNotEmptyError{TodoCount: count}
+			}
+		} else {
+			defaultCategory, err := q.GetDefaultCategory(ctx)
+			if err == sql.ErrNoRows {
+				defaultCategory, err = q.CreateDefaultCategory(ctx, DefaultCategoryName)
+			}
+			if err != nil {
+				return err
+			}
+
+			result.MovedTodos, err = q.MoveTodosToCategory(ctx, MoveTodosToCategoryParams{
+				ToCategoryID:   defaultCategory.ID,
+				FromCategoryID: category.ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = q.DeleteCategory(ctx, category.ID)
+		if err != nil {
+			return err
+		}
+
+		return enqueueEvent(ctx, q, arg.UserEmail, EventCategoryDeleted, map[string]interface{}{
+			"id":          category.ID,
+			"moved_todos": result.MovedTodos,
+		})
+	})
+
+	return result, err
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteCategoryTxRestrict(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	category := createRandomCategory(t)
+	createRandomTodo(t, user.Email, category.ID)
+	createRandomTodo(t, user.Email, category.ID)
+
+	_, err := store.DeleteCategoryTx(context.Background(), DeleteCategoryTxParams{
+		CategoryID: category.ID,
+	})
+	var notEmptyErr *CategoryNotEmptyError
+	require.ErrorAs(t, err, &notEmptyErr)
+	require.Equal(t, int64(2), notEmptyErr.TodoCount)
+
+	_, err = testQueries.GetCategory(context.Background(), category.ID)
+	require.NoError(t, err)
+}
+
+func TestDeleteCategoryTxMove(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	category := createRandomCategory(t)
+	todo := createRandomTodo(t, user.Email, category.ID)
+	trashed := createRandomTodo(t, user.Email, category.ID)
+	require.NoError(t, testQueries.DeleteTodo(context.Background(), trashed.ID))
+
+	result, err := store.DeleteCategoryTx(context.Background(), DeleteCategoryTxParams{
+		CategoryID: category.ID,
+		MoveTodos:  true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.MovedTodos)
+
+	_, err = testQueries.GetCategory(context.Background(), category.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	defaultCategory, err := testQueries.GetDefaultCategory(context.Background())
+	require.NoError(t, err)
+
+	moved, err := testQueries.GetTodo(context.Background(), todo.ID)
+	require.NoError(t, err)
+	require.Equal(t, defaultCategory.ID, moved.CategoryID)
+
+	// trashed todos stay with the trashed category
+	left, err := testQueries.RestoreTodo(context.Background(), RestoreTodoParams{ID: trashed.ID, UserEmail: user.Email})
+	require.NoError(t, err)
+	require.Equal(t, category.ID, left.CategoryID)
+}
+
+func TestDeleteCategoryTxDefault(t *testing.T) {
+	store := NewStore(testDB)
+
+	category, err := testQueries.GetDefaultCategory(context.Background())
+	if err == sql.ErrNoRows {
+		category, err = testQueries.CreateDefaultCategory(context.Background(), DefaultCategoryName)
+	}
+	require.NoError(t, err)
+	require.True(t, category.IsDefault)
+
+	_, err = store.DeleteCategoryTx(context.Background(), DeleteCategoryTxParams{
+		CategoryID: category.ID,
+		MoveTodos:  true,
+	})
+	require.ErrorIs(t, err, ErrDefaultCategory)
+}
+
+func TestDeleteCategoryTxNamedLikeDefault(t *testing.T) {
+	store := NewStore(testDB)
+
+	category, err := testQueries.CreateCategory(context.Background(), DefaultCategoryName)
+	require.NoError(t, err)
+	require.False(t, category.IsDefault)
+
+	_, err = store.DeleteCategoryTx(context.Background(), DeleteCategoryTxParams{
+		CategoryID: category.ID,
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,18 @@
+package db
+
+import (
+	"context"
+)
+
+// Ping checks that the database can be reached.
+func (store *SQLStore) Ping(ctx context.Context) error {
+	return store.db.PingContext(ctx)
+}
+
+// MigrationVersion reads the version golang-migrate last applied and whether
+// that migration failed halfway.
+func (store *SQLStore) MigrationVersion(ctx context.Context) (version int64, dirty bool, err error) {
+	row := store.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1")
+	err = row.Scan(&version, &dirty)
+	return
+}
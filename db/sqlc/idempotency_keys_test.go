@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	user := createRandomUser(t)
+	arg := CreateIdempotencyKeyParams{
+		UserEmail:   user.Email,
+		Key:         util.RandomString(16),
+		RequestHash: util.RandomString(64),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	key, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, key.ResponseStatus)
+
+	// a live key can't be claimed twice
+	_, err = testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	err = testQueries.SaveIdempotencyResponse(context.Background(), SaveIdempotencyResponseParams{
+		UserEmail:      arg.UserEmail,
+		Key:            arg.Key,
+		ResponseStatus: 200,
+		ResponseBody:   []byte(`{}`),
+	})
+	require.NoError(t, err)
+
+	stored, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		UserEmail: arg.UserEmail,
+		Key:       arg.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(200), stored.ResponseStatus)
+	require.Equal(t, []byte(`{}`), stored.ResponseBody)
+}
+
+func TestExpiredIdempotencyKey(t *testing.T) {
+	user := createRandomUser(t)
+	arg := CreateIdempotencyKeyParams{
+		UserEmail:   user.Email,
+		Key:         util.RandomString(16),
+		RequestHash: util.RandomString(64),
+		ExpiresAt:   time.Now().Add(-time.Minute),
+	}
+
+	_, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+
+	// an expired key is taken over by the next request
+	arg.RequestHash = util.RandomString(64)
+	arg.ExpiresAt = time.Now().Add(-time.Second)
+	key, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.RequestHash, key.RequestHash)
+
+	purged, err := testQueries.PurgeExpiredIdempotencyKeys(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, purged, int64(1))
+
+	_, err = testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		UserEmail: arg.UserEmail,
+		Key:       arg.Key,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// errDryRun rolls back an import that was only meant to be checked.
+var errDryRun = errors.New("dry-run")
+
+type ImportTodo struct {
+	Title        string    `json:"title"`
+	Content      string    `json:"content"`
+	CategoryName string    `json:"category_name"`
+	Date         time.Time `json:"date"`
+	AllDay       bool      `json:"all_day"`
+	Color        string    `json:"color"`
+	IsPriority   bool      `json:"is_priority"`
+	Status       bool      `json:"status"`
+	Tags         []string  `json:"tags"`
+}
+
+type ImportTxParams struct {
+	UserEmail string `json:"user_email"`
+	// Categories are created when missing, even if no todo uses them.
+	Categories []string     `json:"categories"`
+	Todos      []ImportTodo `json:"todos"`
+	// DryRun runs the whole import and rolls it back.
+	DryRun bool `json:"dry_run"`
+}
+
+type ImportTxResult struct {
+	Categories []Category     `json:"categories"`
+	Tags       []Tag          `json:"tags"`
+	Todos      []TodoTxResult `json:"todos"`
+}
+
+// ImportTx creates the categories, tags and todos of an import in a single
+// transaction, so a failing row leaves nothing behind.
+func (store *SQLStore) ImportTx(ctx context.Context, arg ImportTxParams) (ImportTxResult, error) {
+	var result ImportTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		result = ImportTxResult{
+			Categories: []Category{},
+			Tags:       []Tag{},
+			Todos:      []TodoTxResult{},
+		}
+		categories := make(map[string]Category)
+		tags := make(map[string]Tag)
+
+		// todos without a category go to the default one, whatever its name
+		category := func(name string) (Category, error) {
+			if c, ok := categories[name]; ok {
+				return c, nil
+			}
+
+			var c Category
+			var err error
+			if name == "" {
+				c, err = q.GetDefaultCategory(ctx)
+			} else {
+				c, err = q.GetCategoryByName(ctx, name)
+			}
+			if err == sql.ErrNoRows {
+				if name == "" {
+					c, err = q.CreateDefaultCategory(ctx, DefaultCategoryName)
+				} else {
+					c, err = q.CreateCategory(ctx, name)
+				}
+				if err == nil {
+					result.Categories = append(result.Categories, c)
+					err = enqueueEvent(ctx, q, arg.UserEmail, EventCategoryCreated, c)
+				}
+			}
+			if err != nil {
+				return Category{}, err
+			}
+
+			categories[name] = c
+			return c, nil
+		}
+
+		tag := func(name string) (Tag, error) {
+			if t, ok := tags[name]; ok {
+				return t, nil
+			}
+
+			t, err := q.GetTagByName(ctx, GetTagByNameParams{UserEmail: arg.UserEmail, Name: name})
+			if err == sql.ErrNoRows {
+				t, err = q.CreateTag(ctx, CreateTagParams{UserEmail: arg.UserEmail, Name: name})
+				if err == nil {
+					result.Tags = append(result.Tags, t)
+				}
+			}
+			if err != nil {
+				return Tag{}, err
+			}
+
+			tags[name] = t
+			return t, nil
+		}
+
+		for _, name := range arg.Categories {
+			if _, err := category(name); err != nil {
+				return err
+			}
+		}
+
+		for _, item := range arg.Todos {
+			c, err := category(item.CategoryName)
+			if err != nil {
+				return err
+			}
+
+			tagIDs := make([]int32, 0, len(item.Tags))
+			for _, name := range item.Tags {
+				t, err := tag(name)
+				if err != nil {
+					return err
+				}
+				tagIDs = append(tagIDs, t.ID)
+			}
+
+			todo, err := q.CreateTodo(ctx, CreateTodoParams{
+				CategoryID: c.ID,
+				UserEmail:  arg.UserEmail,
+				Title:      item.Title,
+				Content:    item.Content,
+				Date:       item.Date,
+				Color:      item.Color,
+				IsPriority: item.IsPriority,
+				AllDay:     item.AllDay,
+			})
+			if err != nil {
+				return err
+			}
+			if item.Status {
+				todo, err = q.MarkAsCompleteTodo(ctx, todo.ID)
+				if err != nil {
+					return err
+				}
+			}
+
+			todoTags, err := setTodoTags(ctx, q, todo, tagIDs)
+			if err != nil {
+				return err
+			}
+
+			todoResult := TodoTxResult{Todo: todo, Tags: todoTags}
+			err = enqueueEvent(ctx, q, arg.UserEmail, EventTodoCreated, todoResult)
+			if err != nil {
+				return err
+			}
+			result.Todos = append(result.Todos, todoResult)
+		}
+
+		if arg.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err == errDryRun {
+		err = nil
+	}
+
+	return result, err
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomImportTxParams(t *testing.T) ImportTxParams {
+	user := createRandomUser(t)
+	category := createRandomCategory(t)
+	tag := createRandomTag(t, user.Email)
+
+	return ImportTxParams{
+		UserEmail:  user.Email,
+		Categories: []string{util.RandomString(8)},
+		Todos: []ImportTodo{
+			{
+				Title:        util.RandomString(10),
+				Content:      util.RandomString(20),
+				CategoryName: category.Name,
+				Date:         time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				AllDay:       true,
+				Color:        util.RandomColor(),
+				Tags:         []string{tag.Name, util.RandomString(8)},
+			},
+			{
+				Title:        util.RandomString(10),
+				CategoryName: util.RandomString(8),
+				Date:         time.Date(2020, 1, 2, 9, 30, 0, 0, time.UTC),
+				Color:        util.RandomColor(),
+				IsPriority:   true,
+				Status:       true,
+			},
+		},
+	}
+}
+
+func TestImportTx(t *testing.T) {
+	store := NewStore(testDB)
+	arg := randomImportTxParams(t)
+
+	result, err := store.ImportTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// the listed category and the second todo's category are new, the tag
+	// that already existed is reused
+	require.Len(t, result.Categories, 2)
+	require.Len(t, result.Tags, 1)
+	require.Len(t, result.Todos, 2)
+
+	first := result.Todos[0]
+	require.Equal(t, arg.Todos[0].Title, first.Todo.Title)
+	require.Equal(t, arg.UserEmail, first.Todo.UserEmail)
+	require.Len(t, first.Tags, 2)
+
+	second := result.Todos[1]
+	require.True(t, second.Todo.Status)
+	require.True(t, second.Todo.IsPriority)
+	require.False(t, second.Todo.AllDay)
+
+	category, err := store.GetCategoryByName(context.Background(), arg.Todos[1].CategoryName)
+	require.NoError(t, err)
+	require.Equal(t, category.ID, second.Todo.CategoryID)
+}
+
+func TestImportTxDryRun(t *testing.T) {
+	store := NewStore(testDB)
+	arg := randomImportTxParams(t)
+	arg.DryRun = true
+
+	result, err := store.ImportTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, result.Todos, 2)
+
+	_, err = store.GetCategoryByName(context.Background(), arg.Categories[0])
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	todos, err := store.ListTodosForExport(context.Background(), arg.UserEmail)
+	require.NoError(t, err)
+	require.Empty(t, todos)
+}
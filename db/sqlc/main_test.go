@@ -11,6 +11,7 @@ import (
 )
 
 var testQueries *Queries
+var testDB *sql.DB
 
 func TestMain(m *testing.M) {
 	config, err := util.LoadConfig("../..")
@@ -18,7 +19,7 @@ func TestMain(m *testing.M) {
 		log.Fatal("cannot log config: ", err)
 	}
 
-	testDB, err := sql.Open(config.DBDriver, config.DBSource)
+	testDB, err = sql.Open(config.DBDriver, config.DBSource)
 	if err != nil {
 		log.Fatal("cannot connect to db: ", err)
 	}
@@ -3,37 +3,123 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
 type Category struct {
+	ID         int32        `json:"id"`
+	Name       string       `json:"name"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+	DeletedAt  sql.NullTime `json:"deleted_at"`
+	Version    int32        `json:"version"`
+	ChangeSeq  int64        `json:"change_seq"`
+	ChangeTxid int64        `json:"change_txid"`
+	IsDefault  bool         `json:"is_default"`
+}
+
+type IdempotencyKey struct {
+	UserEmail      string    `json:"user_email"`
+	Key            string    `json:"key"`
+	RequestHash    string    `json:"request_hash"`
+	ResponseStatus int32     `json:"response_status"`
+	ResponseBody   []byte    `json:"response_body"`
+	CreatedAt      time.Time `json:"created_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+type Reminder struct {
+	ID            int32         `json:"id"`
+	TodoID        int32         `json:"todo_id"`
+	UserEmail     string        `json:"user_email"`
+	RemindAt      time.Time     `json:"remind_at"`
+	OffsetMinutes sql.NullInt32 `json:"offset_minutes"`
+	Channel       string        `json:"channel"`
+	Target        string        `json:"target"`
+	Status        string        `json:"status"`
+	Attempts      int32         `json:"attempts"`
+	LastError     string        `json:"last_error"`
+	NextAttemptAt time.Time     `json:"next_attempt_at"`
+	SentAt        sql.NullTime  `json:"sent_at"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+type Tag struct {
 	ID        int32     `json:"id"`
+	UserEmail string    `json:"user_email"`
 	Name      string    `json:"name"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type Todo struct {
-	ID         int32     `json:"id"`
-	CategoryID int32     `json:"category_id"`
-	Title      string    `json:"title"`
-	Content    string    `json:"content"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
-	UserEmail  string    `json:"user_email"`
-	Color      string    `json:"color"`
-	Date       time.Time `json:"date"`
-	IsPriority bool      `json:"is_priority"`
-	Status     bool      `json:"status"`
+	ID         int32        `json:"id"`
+	CategoryID int32        `json:"category_id"`
+	Title      string       `json:"title"`
+	Content    string       `json:"content"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+	UserEmail  string       `json:"user_email"`
+	Color      string       `json:"color"`
+	Date       time.Time    `json:"date"`
+	IsPriority bool         `json:"is_priority"`
+	Status     bool         `json:"status"`
+	DeletedAt  sql.NullTime `json:"deleted_at"`
+	AllDay     bool         `json:"all_day"`
+	Version    int32        `json:"version"`
+	ChangeSeq  int64        `json:"change_seq"`
+	ChangeTxid int64        `json:"change_txid"`
+}
+
+type TodoTag struct {
+	TodoID int32 `json:"todo_id"`
+	TagID  int32 `json:"tag_id"`
+}
+
+type Tombstone struct {
+	ID         int64          `json:"id"`
+	Entity     string         `json:"entity"`
+	EntityID   int32          `json:"entity_id"`
+	UserEmail  sql.NullString `json:"user_email"`
+	ChangeSeq  int64          `json:"change_seq"`
+	DeletedAt  time.Time      `json:"deleted_at"`
+	ChangeTxid int64          `json:"change_txid"`
 }
 
 type User struct {
-	ID             int32     `json:"id"`
-	Name           string    `json:"name"`
-	Address        string    `json:"address"`
-	Pic            string    `json:"pic"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
-	HashedPassword string    `json:"hashed_password"`
-	Email          string    `json:"email"`
+	ID             int32          `json:"id"`
+	Name           string         `json:"name"`
+	Address        string         `json:"address"`
+	Pic            string         `json:"pic"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	HashedPassword string         `json:"hashed_password"`
+	Email          string         `json:"email"`
+	TimeZone       string         `json:"time_zone"`
+	CalendarToken  sql.NullString `json:"calendar_token"`
+}
+
+type Webhook struct {
+	ID        int32     `json:"id"`
+	UserEmail string    `json:"user_email"`
+	Url       string    `json:"url"`
+	Secret    string    `json:"secret"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int32           `json:"id"`
+	WebhookID      int32           `json:"webhook_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	ResponseStatus int32           `json:"response_status"`
+	LastError      string          `json:"last_error"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	DeliveredAt    sql.NullTime    `json:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at"`
 }
@@ -4,29 +4,93 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 type Querier interface {
+	AddTodoTags(ctx context.Context, arg AddTodoTagsParams) error
+	ClaimDueReminders(ctx context.Context, arg ClaimDueRemindersParams) ([]ClaimDueRemindersRow, error)
+	ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]ClaimWebhookDeliveriesRow, error)
+	CountTagsByUser(ctx context.Context, arg CountTagsByUserParams) (int64, error)
+	CountTodosByCategory(ctx context.Context, categoryID int32) (int64, error)
 	CreateCategory(ctx context.Context, name string) (Category, error)
+	CreateDefaultCategory(ctx context.Context, name string) (Category, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateReminder(ctx context.Context, arg CreateReminderParams) (Reminder, error)
+	CreateTag(ctx context.Context, arg CreateTagParams) (Tag, error)
 	CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error)
 	DeleteCategory(ctx context.Context, id int32) error
+	DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error
+	DeleteReminder(ctx context.Context, arg DeleteReminderParams) (int64, error)
+	DeleteTag(ctx context.Context, arg DeleteTagParams) (int64, error)
 	DeleteTodo(ctx context.Context, id int32) error
+	DeleteTodoTags(ctx context.Context, todoID int32) error
 	DeleteUser(ctx context.Context, id int32) error
+	DeleteWebhook(ctx context.Context, arg DeleteWebhookParams) (int64, error)
+	EnqueueWebhookEvent(ctx context.Context, arg EnqueueWebhookEventParams) (int64, error)
+	GetAnyCategoryForUpdate(ctx context.Context, id int32) (Category, error)
+	GetAnyTodoForUpdate(ctx context.Context, id int32) (Todo, error)
 	GetCategory(ctx context.Context, id int32) (Category, error)
+	GetCategoryByName(ctx context.Context, name string) (Category, error)
+	GetCategoryForUpdate(ctx context.Context, id int32) (Category, error)
+	GetDefaultCategory(ctx context.Context) (Category, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetSyncHorizon(ctx context.Context) (int64, error)
+	GetTagByName(ctx context.Context, arg GetTagByNameParams) (Tag, error)
 	GetTodo(ctx context.Context, id int32) (GetTodoRow, error)
+	GetTodoForUpdate(ctx context.Context, id int32) (Todo, error)
 	GetUser(ctx context.Context, email string) (User, error)
+	GetUserByCalendarToken(ctx context.Context, calendarToken sql.NullString) (User, error)
 	ListCategories(ctx context.Context, arg ListCategoriesParams) ([]Category, error)
+	ListCategoriesByIDs(ctx context.Context, ids []int32) ([]Category, error)
+	ListCategoryChangesSince(ctx context.Context, arg ListCategoryChangesSinceParams) ([]Category, error)
 	ListDoneTodo(ctx context.Context, arg ListDoneTodoParams) ([]ListDoneTodoRow, error)
+	ListOpenTodosForCalendar(ctx context.Context, userEmail string) ([]ListOpenTodosForCalendarRow, error)
+	ListRemindersByTodo(ctx context.Context, arg ListRemindersByTodoParams) ([]Reminder, error)
+	ListTags(ctx context.Context, arg ListTagsParams) ([]Tag, error)
+	ListTagsByTodo(ctx context.Context, todoID int32) ([]Tag, error)
 	ListTodayTodo(ctx context.Context, arg ListTodayTodoParams) ([]ListTodayTodoRow, error)
 	ListTodoByUser(ctx context.Context, arg ListTodoByUserParams) ([]ListTodoByUserRow, error)
+	ListTodoChangesSince(ctx context.Context, arg ListTodoChangesSinceParams) ([]ListTodoChangesSinceRow, error)
+	ListTodosForExport(ctx context.Context, userEmail string) ([]ListTodosForExportRow, error)
+	ListTodosForUpdate(ctx context.Context, ids []int32) ([]Todo, error)
+	ListTombstonesSince(ctx context.Context, arg ListTombstonesSinceParams) ([]Tombstone, error)
+	ListTrashedCategories(ctx context.Context, arg ListTrashedCategoriesParams) ([]Category, error)
+	ListTrashedTodo(ctx context.Context, arg ListTrashedTodoParams) ([]Todo, error)
 	ListUpcomingTodo(ctx context.Context, arg ListUpcomingTodoParams) ([]ListUpcomingTodoRow, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhooks(ctx context.Context, userEmail string) ([]Webhook, error)
 	MarkAsCompleteTodo(ctx context.Context, id int32) (Todo, error)
+	MarkAsIncompleteTodo(ctx context.Context, id int32) (Todo, error)
+	MarkReminderFailed(ctx context.Context, arg MarkReminderFailedParams) error
+	MarkReminderRetry(ctx context.Context, arg MarkReminderRetryParams) error
+	MarkReminderSent(ctx context.Context, id int32) error
+	MarkWebhookDelivered(ctx context.Context, arg MarkWebhookDeliveredParams) error
+	MarkWebhookFailed(ctx context.Context, arg MarkWebhookFailedParams) error
+	MarkWebhookRetry(ctx context.Context, arg MarkWebhookRetryParams) error
+	MoveTodo(ctx context.Context, arg MoveTodoParams) (Todo, error)
+	MoveTodosToCategory(ctx context.Context, arg MoveTodosToCategoryParams) (int64, error)
+	PatchTodo(ctx context.Context, arg PatchTodoParams) (Todo, error)
+	PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	PurgeTrashedCategories(ctx context.Context, deletedBefore time.Time) (int64, error)
+	PurgeTrashedTodos(ctx context.Context, deletedBefore time.Time) (int64, error)
+	RescheduleTodo(ctx context.Context, arg RescheduleTodoParams) (Todo, error)
+	RescheduleTodoReminders(ctx context.Context, arg RescheduleTodoRemindersParams) error
+	RestoreCategory(ctx context.Context, id int32) (Category, error)
+	RestoreTodo(ctx context.Context, arg RestoreTodoParams) (Todo, error)
+	SaveIdempotencyResponse(ctx context.Context, arg SaveIdempotencyResponseParams) error
+	SetCalendarToken(ctx context.Context, arg SetCalendarTokenParams) (User, error)
+	SetTodoPriority(ctx context.Context, arg SetTodoPriorityParams) (Todo, error)
 	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error)
+	UpdateTag(ctx context.Context, arg UpdateTagParams) (Tag, error)
 	UpdateTodoByUser(ctx context.Context, arg UpdateTodoByUserParams) (Todo, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UpdateUserPhoto(ctx context.Context, arg UpdateUserPhotoParams) (User, error)
+	UpdateUserTimeZone(ctx context.Context, arg UpdateUserTimeZoneParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	ReminderStatusPending = "pending"
+	ReminderStatusSent    = "sent"
+	ReminderStatusFailed  = "failed"
+)
+
+type DeliverRemindersParams struct {
+	Limit int32
+	// Lease is how long claimed reminders stay hidden from other pollers. It
+	// must outlast delivering the whole batch, or a reminder may go out twice.
+	Lease time.Duration
+	// Deliver sends a single reminder; a non-nil error schedules a retry.
+	Deliver     func(ctx context.Context, reminder ClaimDueRemindersRow) error
+	MaxAttempts int32
+	// RetryDelay returns how long to wait after the given number of attempts.
+	RetryDelay func(attempts int32) time.Duration
+}
+
+type DeliverRemindersResult struct {
+	Sent    int `json:"sent"`
+	Retried int `json:"retried"`
+	Failed  int `json:"failed"`
+}
+
+// DeliverReminders claims due reminders by pushing them back by the lease,
+// so several instances can poll at once without sending the same reminder
+// twice. The claim commits before anything is sent, so no row locks or
+// connections are held while a slow notifier runs; a reminder whose outcome
+// is never recorded is picked up again once its lease runs out.
+func (store *SQLStore) DeliverReminders(ctx context.Context, arg DeliverRemindersParams) (DeliverRemindersResult, error) {
+	var result DeliverRemindersResult
+
+	reminders, err := store.ClaimDueReminders(ctx, ClaimDueRemindersParams{
+		LeaseUntil: time.Now().Add(arg.Lease),
+		MaxRows:    arg.Limit,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	for _, reminder := range reminders {
+		deliverErr := arg.Deliver(ctx, reminder)
+		attempts := reminder.Attempts + 1
+
+		switch {
+		case deliverErr == nil:
+			err = store.MarkReminderSent(ctx, reminder.ID)
+			result.Sent++
+		case attempts >= arg.MaxAttempts:
+			err = store.MarkReminderFailed(ctx, MarkReminderFailedParams{
+				ID:        reminder.ID,
+				LastError: deliverErr.Error(),
+			})
+			result.Failed++
+		default:
+			err = store.MarkReminderRetry(ctx, MarkReminderRetryParams{
+				ID:            reminder.ID,
+				LastError:     deliverErr.Error(),
+				NextAttemptAt: time.Now().Add(arg.RetryDelay(attempts)),
+			})
+			result.Retried++
+		}
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
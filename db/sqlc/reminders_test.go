@@ -0,0 +1,166 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomReminder(t *testing.T, todo Todo, remindAt time.Time) Reminder {
+	arg := CreateReminderParams{
+		TodoID:    todo.ID,
+		UserEmail: todo.UserEmail,
+		RemindAt:  remindAt,
+		Channel:   "log",
+	}
+
+	reminder, err := testQueries.CreateReminder(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, ReminderStatusPending, reminder.Status)
+	require.WithinDuration(t, remindAt, reminder.NextAttemptAt, time.Second)
+
+	return reminder
+}
+
+func TestDeliverReminders(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	category := createRandomCategory(t)
+	todo := createRandomTodo(t, user.Email, category.ID)
+
+	ok := createRandomReminder(t, todo, time.Now().Add(-time.Minute))
+	flaky := createRandomReminder(t, todo, time.Now().Add(-time.Minute))
+	later := createRandomReminder(t, todo, time.Now().Add(time.Hour))
+
+	result, err := store.DeliverReminders(context.Background(), DeliverRemindersParams{
+		Limit: 100,
+		Lease: time.Minute,
+		Deliver: func(ctx context.Context, reminder ClaimDueRemindersRow) error {
+			require.NotEqual(t, later.ID, reminder.ID)
+			if reminder.ID == flaky.ID {
+				return errors.New("boom")
+			}
+			return nil
+		},
+		MaxAttempts: 2,
+		RetryDelay:  func(attempts int32) time.Duration { return -time.Second },
+	})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, result.Sent, 1)
+	require.GreaterOrEqual(t, result.Retried, 1)
+
+	reminders, err := testQueries.ListRemindersByTodo(context.Background(), ListRemindersByTodoParams{
+		TodoID:    todo.ID,
+		UserEmail: user.Email,
+	})
+	require.NoError(t, err)
+
+	for _, reminder := range reminders {
+		switch reminder.ID {
+		case ok.ID:
+			require.Equal(t, ReminderStatusSent, reminder.Status)
+			require.True(t, reminder.SentAt.Valid)
+		case flaky.ID:
+			require.Equal(t, ReminderStatusPending, reminder.Status)
+			require.Equal(t, int32(1), reminder.Attempts)
+			require.Equal(t, "boom", reminder.LastError)
+		case later.ID:
+			require.Equal(t, int32(0), reminder.Attempts)
+		}
+	}
+
+	// second failure reaches MaxAttempts
+	_, err = store.DeliverReminders(context.Background(), DeliverRemindersParams{
+		Limit: 100,
+		Lease: time.Minute,
+		Deliver: func(ctx context.Context, reminder ClaimDueRemindersRow) error {
+			return errors.New("boom")
+		},
+		MaxAttempts: 2,
+		RetryDelay:  func(attempts int32) time.Duration { return time.Minute },
+	})
+	require.NoError(t, err)
+
+	reminders, err = testQueries.ListRemindersByTodo(context.Background(), ListRemindersByTodoParams{
+		TodoID:    todo.ID,
+		UserEmail: user.Email,
+	})
+	require.NoError(t, err)
+	for _, reminder := range reminders {
+		if reminder.ID == flaky.ID {
+			require.Equal(t, ReminderStatusFailed, reminder.Status)
+			require.Equal(t, int32(2), reminder.Attempts)
+		}
+	}
+
+	rows, err := testQueries.DeleteReminder(context.Background(), DeleteReminderParams{
+		ID:        flaky.ID,
+		TodoID:    todo.ID,
+		UserEmail: user.Email,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+}
+
+func TestClaimDueRemindersLease(t *testing.T) {
+	user := createRandomUser(t)
+	category := createRandomCategory(t)
+	todo := createRandomTodo(t, user.Email, category.ID)
+	reminder := createRandomReminder(t, todo, time.Now().Add(-time.Minute))
+
+	claimed := func() bool {
+		rows, err := testQueries.ClaimDueReminders(context.Background(), ClaimDueRemindersParams{
+			LeaseUntil: time.Now().Add(time.Minute),
+			MaxRows:    1000,
+		})
+		require.NoError(t, err)
+		for _, row := range rows {
+			if row.ID == reminder.ID {
+				require.Equal(t, todo.Title, row.Title)
+				return true
+			}
+		}
+		return false
+	}
+
+	// the claim is committed, so other pollers skip the reminder while it is
+	// being delivered without anyone holding a lock
+	require.True(t, claimed())
+	require.False(t, claimed())
+}
+
+func TestRescheduleTodoReminders(t *testing.T) {
+	user := createRandomUser(t)
+	category := createRandomCategory(t)
+	todo := createRandomTodo(t, user.Email, category.ID)
+
+	reminder, err := testQueries.CreateReminder(context.Background(), CreateReminderParams{
+		TodoID:        todo.ID,
+		UserEmail:     user.Email,
+		RemindAt:      todo.Date.Add(-time.Hour),
+		OffsetMinutes: sql.NullInt32{Int32: 60, Valid: true},
+		Channel:       "log",
+	})
+	require.NoError(t, err)
+
+	due := todo.Date.Add(48 * time.Hour)
+	err = testQueries.RescheduleTodoReminders(context.Background(), RescheduleTodoRemindersParams{
+		DueAt:  due,
+		TodoID: todo.ID,
+	})
+	require.NoError(t, err)
+
+	reminders, err := testQueries.ListRemindersByTodo(context.Background(), ListRemindersByTodoParams{
+		TodoID:    todo.ID,
+		UserEmail: user.Email,
+	})
+	require.NoError(t, err)
+	require.Len(t, reminders, 1)
+	require.Equal(t, reminder.ID, reminders[0].ID)
+	require.WithinDuration(t, due.Add(-time.Hour), reminders[0].RemindAt, time.Second)
+}
@@ -1,9 +1,28 @@
 package db
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
 
 type Store interface {
 	Querier
+	CreateCategoryTx(ctx context.Context, arg CreateCategoryTxParams) (Category, error)
+	UpdateCategoryTx(ctx context.Context, arg UpdateCategoryTxParams) (Category, error)
+	DeleteCategoryTx(ctx context.Context, arg DeleteCategoryTxParams) (DeleteCategoryTxResult, error)
+	CreateTodoTx(ctx context.Context, arg CreateTodoTxParams) (TodoTxResult, error)
+	UpdateTodoTx(ctx context.Context, arg UpdateTodoTxParams) (TodoTxResult, error)
+	PatchTodoTx(ctx context.Context, arg PatchTodoTxParams) (TodoTxResult, error)
+	CompleteTodoTx(ctx context.Context, arg CompleteTodoTxParams) (Todo, error)
+	DeleteTodoTx(ctx context.Context, arg DeleteTodoTxParams) error
+	ImportTx(ctx context.Context, arg ImportTxParams) (ImportTxResult, error)
+	BatchTodoTx(ctx context.Context, arg BatchTodoTxParams) (BatchTodoTxResult, error)
+	SyncTx(ctx context.Context, arg SyncTxParams) (SyncTxResult, error)
+	DeliverReminders(ctx context.Context, arg DeliverRemindersParams) (DeliverRemindersResult, error)
+	DeliverWebhooks(ctx context.Context, arg DeliverWebhooksParams) (DeliverWebhooksResult, error)
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (version int64, dirty bool, err error)
 }
 
 type SQLStore struct {
@@ -17,3 +36,21 @@ func NewStore(db *sql.DB) Store {
 		Queries: New(db),
 	}
 }
+
+func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	q := New(tx)
+	err = fn(q)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,288 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+const (
+	SyncEntityTodo     = "todo"
+	SyncEntityCategory = "category"
+
+	SyncOpUpsert = "upsert"
+	SyncOpDelete = "delete"
+
+	SyncStatusApplied  = "applied"
+	SyncStatusConflict = "conflict"
+	SyncStatusRejected = "rejected"
+)
+
+var ErrUnsupportedSyncOp = errors.New("unsupported-operation")
+
+// SyncChange is one change made by a client while offline. A zero ID creates
+// the entity. BaseVersion is the version the client last saw; when set, the
+// change only applies if the entity is still at that version.
+type SyncChange struct {
+	Entity      string `json:"entity"`
+	Op          string `json:"op"`
+	ID          int32  `json:"id"`
+	ClientID    string `json:"client_id"`
+	BaseVersion int32  `json:"base_version"`
+	// Todo holds the todo fields to set; unset fields are left as they are.
+	Todo   PatchTodoParams `json:"todo"`
+	Status sql.NullBool    `json:"status"`
+	// TagIDs replaces the todo's tags; nil leaves them untouched.
+	TagIDs []int32 `json:"tag_ids"`
+	// Name is the category name.
+	Name string `json:"name"`
+}
+
+type SyncTxParams struct {
+	UserEmail string       `json:"user_email"`
+	Changes   []SyncChange `json:"changes"`
+}
+
+// SyncChangeResult reports what happened to a change. On a conflict the
+// server's copy wins and is returned so the client can rebase onto it.
+type SyncChangeResult struct {
+	Index    int           `json:"index"`
+	ClientID string        `json:"client_id,omitempty"`
+	Entity   string        `json:"entity"`
+	Op       string        `json:"op"`
+	ID       int32         `json:"id"`
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Created  bool          `json:"created,omitempty"`
+	Deleted  bool          `json:"deleted,omitempty"`
+	Todo     *TodoTxResult `json:"todo,omitempty"`
+	Category *Category     `json:"category,omitempty"`
+}
+
+type SyncTxResult struct {
+	Results []SyncChangeResult `json:"results"`
+}
+
+// SyncTx applies the changes in order in a single transaction. Conflicts and
+// changes to todos that are missing or belong to someone else are reported
+// and skipped; any other error rolls everything back.
+func (store *SQLStore) SyncTx(ctx context.Context, arg SyncTxParams) (SyncTxResult, error) {
+	var result SyncTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		result.Results = make([]SyncChangeResult, 0, len(arg.Changes))
+
+		for i, change := range arg.Changes {
+			item := SyncChangeResult{
+				Index:    i,
+				ClientID: change.ClientID,
+				Entity:   change.Entity,
+				Op:       change.Op,
+				ID:       change.ID,
+			}
+
+			var err error
+			if change.Entity == SyncEntityCategory {
+				err = applySyncCategoryChange(ctx, q, arg.UserEmail, change, &item)
+			} else {
+				err = applySyncTodoChange(ctx, q, arg.UserEmail, change, &item)
+			}
+			if err != nil {
+				return err
+			}
+
+			result.Results = append(result.Results, item)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+func applySyncTodoChange(ctx context.Context, q *Queries, userEmail string, change SyncChange, item *SyncChangeResult) error {
+	if change.Todo.CategoryID.Valid {
+		_, err := q.GetCategory(ctx, change.Todo.CategoryID.Int32)
+		if err == sql.ErrNoRows {
+			item.Status, item.Error = SyncStatusRejected, ErrInvalidCategory.Error()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if change.ID == 0 {
+		return createSyncTodo(ctx, q, userEmail, change, item)
+	}
+
+	todo, err := q.GetAnyTodoForUpdate(ctx, change.ID)
+	if err == sql.ErrNoRows {
+		item.Status, item.Error = SyncStatusRejected, ErrTodoNotFound.Error()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if todo.UserEmail != userEmail {
+		item.Status, item.Error = SyncStatusRejected, ErrWrongUser.Error()
+		return nil
+	}
+
+	// deletes win: a todo trashed on the server stays trashed
+	if todo.DeletedAt.Valid {
+		item.Deleted = true
+		item.Status = SyncStatusApplied
+		if change.Op != SyncOpDelete {
+			item.Status = SyncStatusConflict
+		}
+		return nil
+	}
+
+	if change.BaseVersion != 0 && change.BaseVersion != todo.Version {
+		item.Status = SyncStatusConflict
+		return setSyncTodo(ctx, q, todo, item)
+	}
+
+	if change.Op == SyncOpDelete {
+		err = q.DeleteTodo(ctx, todo.ID)
+		if err != nil {
+			return err
+		}
+		item.Status, item.Deleted = SyncStatusApplied, true
+		return enqueueEvent(ctx, q, userEmail, EventTodoDeleted, DeleteTodoTxParams{
+			ID:        todo.ID,
+			UserEmail: userEmail,
+		})
+	}
+
+	patch := change.Todo
+	patch.ID = todo.ID
+	todo, err = q.PatchTodo(ctx, patch)
+	if err != nil {
+		return err
+	}
+
+	if patch.Date.Valid {
+		err = q.RescheduleTodoReminders(ctx, RescheduleTodoRemindersParams{
+			DueAt:  todo.Date,
+			TodoID: todo.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if change.Status.Valid && change.Status.Bool != todo.Status {
+		todo, err = setSyncTodoStatus(ctx, q, todo.ID, change.Status.Bool)
+		if err != nil {
+			return err
+		}
+	}
+
+	if change.TagIDs != nil {
+		_, err = setTodoTags(ctx, q, todo, change.TagIDs)
+		if err != nil {
+			return err
+		}
+	}
+
+	item.Status = SyncStatusApplied
+	if err := setSyncTodo(ctx, q, todo, item); err != nil {
+		return err
+	}
+	return enqueueEvent(ctx, q, userEmail, EventTodoUpdated, item.Todo)
+}
+
+func createSyncTodo(ctx context.Context, q *Queries, userEmail string, change SyncChange, item *SyncChangeResult) error {
+	todo, err := q.CreateTodo(ctx, CreateTodoParams{
+		CategoryID: change.Todo.CategoryID.Int32,
+		UserEmail:  userEmail,
+		Title:      change.Todo.Title.String,
+		Content:    change.Todo.Content.String,
+		Date:       change.Todo.Date.Time,
+		Color:      change.Todo.Color.String,
+		IsPriority: change.Todo.IsPriority.Bool,
+		AllDay:     change.Todo.AllDay.Bool,
+	})
+	if err != nil {
+		return err
+	}
+
+	if change.Status.Bool {
+		todo, err = q.MarkAsCompleteTodo(ctx, todo.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = setTodoTags(ctx, q, todo, change.TagIDs)
+	if err != nil {
+		return err
+	}
+
+	item.ID, item.Status, item.Created = todo.ID, SyncStatusApplied, true
+	if err := setSyncTodo(ctx, q, todo, item); err != nil {
+		return err
+	}
+	return enqueueEvent(ctx, q, userEmail, EventTodoCreated, item.Todo)
+}
+
+func setSyncTodoStatus(ctx context.Context, q *Queries, id int32, done bool) (Todo, error) {
+	if done {
+		return q.MarkAsCompleteTodo(ctx, id)
+	}
+	return q.MarkAsIncompleteTodo(ctx, id)
+}
+
+// setSyncTodo puts the server's copy of todo, with its tags, on the result.
+func setSyncTodo(ctx context.Context, q *Queries, todo Todo, item *SyncChangeResult) error {
+	tags, err := q.ListTagsByTodo(ctx, todo.ID)
+	if err != nil {
+		return err
+	}
+	item.Todo = &TodoTxResult{Todo: todo, Tags: tags}
+	return nil
+}
+
+func applySyncCategoryChange(ctx context.Context, q *Queries, userEmail string, change SyncChange, item *SyncChangeResult) error {
+	// categories are shared, so clients can't delete them through sync
+	if change.Op == SyncOpDelete {
+		item.Status, item.Error = SyncStatusRejected, ErrUnsupportedSyncOp.Error()
+		return nil
+	}
+
+	if change.ID == 0 {
+		category, err := q.CreateCategory(ctx, change.Name)
+		if err != nil {
+			return err
+		}
+		item.ID, item.Status, item.Created, item.Category = category.ID, SyncStatusApplied, true, &category
+		return enqueueEvent(ctx, q, userEmail, EventCategoryCreated, category)
+	}
+
+	category, err := q.GetAnyCategoryForUpdate(ctx, change.ID)
+	if err == sql.ErrNoRows {
+		item.Status, item.Error = SyncStatusRejected, ErrInvalidCategory.Error()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if category.DeletedAt.Valid {
+		item.Status, item.Deleted = SyncStatusConflict, true
+		return nil
+	}
+	if change.BaseVersion != 0 && change.BaseVersion != category.Version {
+		item.Status, item.Category = SyncStatusConflict, &category
+		return nil
+	}
+
+	category, err = q.UpdateCategory(ctx, UpdateCategoryParams{ID: category.ID, Name: change.Name})
+	if err != nil {
+		return err
+	}
+	item.Status, item.Category = SyncStatusApplied, &category
+	return enqueueEvent(ctx, q, userEmail, EventCategoryUpdated, category)
+}
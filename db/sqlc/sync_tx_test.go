@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	category := createRandomCategory(t)
+
+	todo1 := createRandomTodo(t, user.Email, category.ID)
+	todo2 := createRandomTodo(t, user.Email, category.ID)
+	foreign := createRandomTodo(t, other.Email, category.ID)
+
+	sinceTxid, sinceSeq := todo2.ChangeTxid, todo2.ChangeSeq
+
+	result, err := store.SyncTx(context.Background(), SyncTxParams{
+		UserEmail: user.Email,
+		Changes: []SyncChange{
+			{
+				Entity:   SyncEntityTodo,
+				Op:       SyncOpUpsert,
+				ClientID: "offline-1",
+				Todo: PatchTodoParams{
+					CategoryID: sql.NullInt32{Int32: category.ID, Valid: true},
+					Title:      sql.NullString{String: "made offline", Valid: true},
+					Date:       sql.NullTime{Time: time.Now(), Valid: true},
+					Color:      sql.NullString{String: "#fff", Valid: true},
+				},
+				Status: sql.NullBool{Bool: true, Valid: true},
+			},
+			{
+				Entity:      SyncEntityTodo,
+				Op:          SyncOpUpsert,
+				ID:          todo1.ID,
+				BaseVersion: todo1.Version,
+				Todo:        PatchTodoParams{Title: sql.NullString{String: "edited", Valid: true}},
+			},
+			// the first edit bumped the version, so this one is stale
+			{
+				Entity:      SyncEntityTodo,
+				Op:          SyncOpUpsert,
+				ID:          todo1.ID,
+				BaseVersion: todo1.Version,
+				Todo:        PatchTodoParams{Title: sql.NullString{String: "stale", Valid: true}},
+			},
+			{Entity: SyncEntityTodo, Op: SyncOpDelete, ID: todo2.ID},
+			{Entity: SyncEntityTodo, Op: SyncOpUpsert, ID: todo2.ID, Todo: PatchTodoParams{Title: sql.NullString{String: "x", Valid: true}}},
+			{Entity: SyncEntityTodo, Op: SyncOpDelete, ID: foreign.ID},
+			{Entity: SyncEntityCategory, Op: SyncOpDelete, ID: category.ID},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Results, 7)
+
+	require.Equal(t, SyncStatusApplied, result.Results[0].Status)
+	require.True(t, result.Results[0].Created)
+	require.Equal(t, "offline-1", result.Results[0].ClientID)
+	require.True(t, result.Results[0].Todo.Todo.Status)
+
+	require.Equal(t, SyncStatusApplied, result.Results[1].Status)
+	require.Equal(t, SyncStatusConflict, result.Results[2].Status)
+	require.Equal(t, "edited", result.Results[2].Todo.Todo.Title)
+
+	require.Equal(t, SyncStatusApplied, result.Results[3].Status)
+	require.Equal(t, SyncStatusConflict, result.Results[4].Status)
+	require.True(t, result.Results[4].Deleted)
+
+	require.Equal(t, ErrWrongUser.Error(), result.Results[5].Error)
+	require.Equal(t, ErrUnsupportedSyncOp.Error(), result.Results[6].Error)
+
+	horizon, err := store.GetSyncHorizon(context.Background())
+	require.NoError(t, err)
+
+	changes, err := store.ListTodoChangesSince(context.Background(), ListTodoChangesSinceParams{
+		UserEmail: user.Email,
+		SinceTxid: sinceTxid,
+		SinceSeq:  sinceSeq,
+		Horizon:   horizon,
+		MaxRows:   10,
+	})
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	for i := 1; i < len(changes); i++ {
+		require.Greater(t, changes[i].ChangeSeq, changes[i-1].ChangeSeq)
+	}
+	require.True(t, changes[len(changes)-1].DeletedAt.Valid)
+}
+
+func TestTombstones(t *testing.T) {
+	user := createRandomUser(t)
+	category := createRandomCategory(t)
+	todo := createRandomTodo(t, user.Email, category.ID)
+
+	err := testQueries.DeleteTodo(context.Background(), todo.ID)
+	require.NoError(t, err)
+
+	_, err = testDB.Exec("DELETE FROM todos WHERE id = $1", todo.ID)
+	require.NoError(t, err)
+
+	horizon, err := testQueries.GetSyncHorizon(context.Background())
+	require.NoError(t, err)
+
+	tombstones, err := testQueries.ListTombstonesSince(context.Background(), ListTombstonesSinceParams{
+		SinceTxid: todo.ChangeTxid,
+		SinceSeq:  todo.ChangeSeq,
+		Horizon:   horizon,
+		UserEmail: user.Email,
+		MaxRows:   100,
+	})
+	require.NoError(t, err)
+
+	var found bool
+	for _, tombstone := range tombstones {
+		if tombstone.Entity == SyncEntityTodo && tombstone.EntityID == todo.ID {
+			found = true
+			require.Equal(t, user.Email, tombstone.UserEmail.String)
+		}
+	}
+	require.True(t, found)
+}
+
+func TestSyncHorizon(t *testing.T) {
+	user := createRandomUser(t)
+	category := createRandomCategory(t)
+	todo := createRandomTodo(t, user.Email, category.ID)
+
+	// a transaction that wrote a change but hasn't committed holds the
+	// horizon back, so the change can't be skipped once it commits
+	tx, err := testDB.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	_, err = New(tx).MarkAsCompleteTodo(context.Background(), todo.ID)
+	require.NoError(t, err)
+
+	var txid int64
+	err = tx.QueryRow("SELECT txid_current()").Scan(&txid)
+	require.NoError(t, err)
+
+	horizon, err := testQueries.GetSyncHorizon(context.Background())
+	require.NoError(t, err)
+	require.LessOrEqual(t, horizon, txid)
+
+	require.NoError(t, tx.Commit())
+
+	horizon, err = testQueries.GetSyncHorizon(context.Background())
+	require.NoError(t, err)
+	require.Greater(t, horizon, txid)
+
+	changes, err := testQueries.ListTodoChangesSince(context.Background(), ListTodoChangesSinceParams{
+		UserEmail: user.Email,
+		SinceTxid: todo.ChangeTxid,
+		SinceSeq:  todo.ChangeSeq,
+		Horizon:   horizon,
+		MaxRows:   10,
+	})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, txid, changes[0].ChangeTxid)
+	require.True(t, changes[0].Status)
+}
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomTag(t *testing.T, userEmail string) Tag {
+	arg := CreateTagParams{
+		UserEmail: userEmail,
+		Name:      util.RandomString(8),
+	}
+
+	tag, err := testQueries.CreateTag(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, tag)
+
+	require.Equal(t, arg.UserEmail, tag.UserEmail)
+	require.Equal(t, arg.Name, tag.Name)
+	require.NotZero(t, tag.ID)
+
+	return tag
+}
+
+func TestCreateTag(t *testing.T) {
+	user := createRandomUser(t)
+	tag := createRandomTag(t, user.Email)
+
+	_, err := testQueries.CreateTag(context.Background(), CreateTagParams{
+		UserEmail: user.Email,
+		Name:      tag.Name,
+	})
+	require.Error(t, err)
+}
+
+func TestUpdateAndDeleteTag(t *testing.T) {
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	tag := createRandomTag(t, user.Email)
+	todo := createRandomTodo(t, user.Email, createRandomCategory(t).ID)
+
+	err := testQueries.AddTodoTags(context.Background(), AddTodoTagsParams{TodoID: todo.ID, TagIds: []int32{tag.ID}})
+	require.NoError(t, err)
+
+	updated, err := testQueries.UpdateTag(context.Background(), UpdateTagParams{
+		ID:        tag.ID,
+		UserEmail: user.Email,
+		Name:      util.RandomString(8),
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, tag.Name, updated.Name)
+
+	// the todos carrying the tag change with it, so sync pulls them again
+	renamed, err := testQueries.GetTodoForUpdate(context.Background(), todo.ID)
+	require.NoError(t, err)
+	require.Greater(t, renamed.ChangeSeq, todo.ChangeSeq)
+
+	rows, err := testQueries.DeleteTag(context.Background(), DeleteTagParams{
+		ID:        tag.ID,
+		UserEmail: other.Email,
+	})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	rows, err = testQueries.DeleteTag(context.Background(), DeleteTagParams{
+		ID:        tag.ID,
+		UserEmail: user.Email,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	untagged, err := testQueries.GetTodoForUpdate(context.Background(), todo.ID)
+	require.NoError(t, err)
+	require.Greater(t, untagged.ChangeSeq, renamed.ChangeSeq)
+}
+
+func TestCreateTodoTxWithTags(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	category := createRandomCategory(t)
+	tag1 := createRandomTag(t, user.Email)
+	tag2 := createRandomTag(t, user.Email)
+
+	result, err := store.CreateTodoTx(context.Background(), CreateTodoTxParams{
+		CreateTodoParams: CreateTodoParams{
+			CategoryID: category.ID,
+			UserEmail:  user.Email,
+			Title:      "Todo title 1",
+			Content:    "Todo content 1",
+			Color:      util.RandomColor(),
+		},
+		TagIDs: []int32{tag1.ID, tag2.ID, tag1.ID},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Tags, 2)
+
+	todos, err := testQueries.ListTodoByUser(context.Background(), ListTodoByUserParams{
+		UserEmail: user.Email,
+		Limit:     10,
+		TagID:     tag2.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, todos, 1)
+	require.Equal(t, result.Todo.ID, todos[0].ID)
+
+	updated, err := store.UpdateTodoTx(context.Background(), UpdateTodoTxParams{
+		UpdateTodoByUserParams: UpdateTodoByUserParams{
+			ID:         result.Todo.ID,
+			CategoryID: category.ID,
+			Title:      result.Todo.Title,
+			Content:    result.Todo.Content,
+			Color:      result.Todo.Color,
+		},
+		TagIDs: []int32{tag1.ID},
+	})
+	require.NoError(t, err)
+	require.Len(t, updated.Tags, 1)
+	require.Equal(t, tag1.ID, updated.Tags[0].ID)
+}
+
+func TestCreateTodoTxForeignTag(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	category := createRandomCategory(t)
+	tag := createRandomTag(t, other.Email)
+
+	_, err := store.CreateTodoTx(context.Background(), CreateTodoTxParams{
+		CreateTodoParams: CreateTodoParams{
+			CategoryID: category.ID,
+			UserEmail:  user.Email,
+			Title:      "Todo title 1",
+			Content:    "Todo content 1",
+			Color:      util.RandomColor(),
+		},
+		TagIDs: []int32{tag.ID},
+	})
+	require.ErrorIs(t, err, ErrInvalidTags)
+}
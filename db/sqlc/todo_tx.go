@@ -0,0 +1,250 @@
+package db
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrInvalidTags     = errors.New("invalid-tags")
+	ErrVersionMismatch = errors.New("version-mismatch")
+)
+
+type CreateTodoTxParams struct {
+	CreateTodoParams
+	TagIDs []int32 `json:"tag_ids"`
+}
+
+type UpdateTodoTxParams struct {
+	UpdateTodoByUserParams
+	// TagIDs replaces the todo's tags; nil leaves them untouched.
+	TagIDs []int32 `json:"tag_ids"`
+	// Version, when set, must be the todo's current version.
+	Version int32 `json:"version"`
+}
+
+type TodoTxResult struct {
+	Todo Todo  `json:"todo"`
+	Tags []Tag `json:"tags"`
+}
+
+func (store *SQLStore) CreateTodoTx(ctx context.Context, arg CreateTodoTxParams) (TodoTxResult, error) {
+	var result TodoTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Todo, err = q.CreateTodo(ctx, arg.CreateTodoParams)
+		if err != nil {
+			return err
+		}
+
+		result.Tags, err = setTodoTags(ctx, q, result.Todo, arg.TagIDs)
+		if err != nil {
+			return err
+		}
+
+		return enqueueEvent(ctx, q, result.Todo.UserEmail, EventTodoCreated, result)
+	})
+
+	return result, err
+}
+
+func (store *SQLStore) UpdateTodoTx(ctx context.Context, arg UpdateTodoTxParams) (TodoTxResult, error) {
+	var result TodoTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		err := checkTodoVersion(ctx, q, arg.ID, arg.Version)
+		if err != nil {
+			return err
+		}
+
+		result.Todo, err = q.UpdateTodoByUser(ctx, arg.UpdateTodoByUserParams)
+		if err != nil {
+			return err
+		}
+
+		// reminders set relative to the due date follow it
+		err = q.RescheduleTodoReminders(ctx, RescheduleTodoRemindersParams{
+			DueAt:  result.Todo.Date,
+			TodoID: result.Todo.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.TagIDs == nil {
+			result.Tags, err = q.ListTagsByTodo(ctx, result.Todo.ID)
+		} else {
+			result.Tags, err = setTodoTags(ctx, q, result.Todo, arg.TagIDs)
+		}
+		if err != nil {
+			return err
+		}
+
+		return enqueueEvent(ctx, q, result.Todo.UserEmail, EventTodoUpdated, result)
+	})
+
+	return result, err
+}
+
+type PatchTodoTxParams struct {
+	PatchTodoParams
+	// TagIDs replaces the todo's tags; nil leaves them untouched.
+	TagIDs []int32 `json:"tag_ids"`
+	// Version, when set, must be the todo's current version.
+	Version int32 `json:"version"`
+}
+
+// PatchTodoTx updates only the fields that are set in arg.
+func (store *SQLStore) PatchTodoTx(ctx context.Context, arg PatchTodoTxParams) (TodoTxResult, error) {
+	var result TodoTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		err := checkTodoVersion(ctx, q, arg.ID, arg.Version)
+		if err != nil {
+			return err
+		}
+
+		result.Todo, err = q.PatchTodo(ctx, arg.PatchTodoParams)
+		if err != nil {
+			return err
+		}
+
+		if arg.Date.Valid {
+			err = q.RescheduleTodoReminders(ctx, RescheduleTodoRemindersParams{
+				DueAt:  result.Todo.Date,
+				TodoID: result.Todo.ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if arg.TagIDs == nil {
+			result.Tags, err = q.ListTagsByTodo(ctx, result.Todo.ID)
+		} else {
+			result.Tags, err = setTodoTags(ctx, q, result.Todo, arg.TagIDs)
+		}
+		if err != nil {
+			return err
+		}
+
+		return enqueueEvent(ctx, q, result.Todo.UserEmail, EventTodoUpdated, result)
+	})
+
+	return result, err
+}
+
+type CompleteTodoTxParams struct {
+	ID int32 `json:"id"`
+	// Version, when set, must be the todo's current version.
+	Version int32 `json:"version"`
+}
+
+func (store *SQLStore) CompleteTodoTx(ctx context.Context, arg CompleteTodoTxParams) (Todo, error) {
+	var todo Todo
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		err := checkTodoVersion(ctx, q, arg.ID, arg.Version)
+		if err != nil {
+			return err
+		}
+
+		todo, err = q.MarkAsCompleteTodo(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		return enqueueEvent(ctx, q, todo.UserEmail, EventTodoCompleted, todo)
+	})
+
+	return todo, err
+}
+
+type DeleteTodoTxParams struct {
+	ID        int32  `json:"id"`
+	UserEmail string `json:"user_email"`
+	// Version, when set, must be the todo's current version.
+	Version int32 `json:"-"`
+}
+
+func (store *SQLStore) DeleteTodoTx(ctx context.Context, arg DeleteTodoTxParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		err := checkTodoVersion(ctx, q, arg.ID, arg.Version)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteTodo(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		return enqueueEvent(ctx, q, arg.UserEmail, EventTodoDeleted, arg)
+	})
+}
+
+// checkTodoVersion locks the todo and fails with ErrVersionMismatch if it has
+// changed since version. A zero version skips the check.
+func checkTodoVersion(ctx context.Context, q *Queries, id int32, version int32) error {
+	if version == 0 {
+		return nil
+	}
+
+	todo, err := q.GetTodoForUpdate(ctx, id)
+	if err != nil {
+		return err
+	}
+	if todo.Version != version {
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
+// setTodoTags replaces the tags of todo with tagIDs, which must all belong to
+// the todo's owner.
+func setTodoTags(ctx context.Context, q *Queries, todo Todo, tagIDs []int32) ([]Tag, error) {
+	err := q.DeleteTodoTags(ctx, todo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tagIDs = uniqueIDs(tagIDs)
+	if len(tagIDs) == 0 {
+		return []Tag{}, nil
+	}
+
+	count, err := q.CountTagsByUser(ctx, CountTagsByUserParams{
+		UserEmail: todo.UserEmail,
+		Ids:       tagIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if count != int64(len(tagIDs)) {
+		return nil, ErrInvalidTags
+	}
+
+	err = q.AddTodoTags(ctx, AddTodoTagsParams{
+		TodoID: todo.ID,
+		TagIds: tagIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return q.ListTagsByTodo(ctx, todo.ID)
+}
+
+func uniqueIDs(ids []int32) []int32 {
+	seen := make(map[int32]bool, len(ids))
+	unique := make([]int32, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -5,9 +5,25 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+const countTodosByCategory = `-- name: CountTodosByCategory :one
+SELECT count(*) FROM todos
+WHERE category_id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) CountTodosByCategory(ctx context.Context, categoryID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTodosByCategory, categoryID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTodo = `-- name: CreateTodo :one
 INSERT INTO todos (
     category_id,
@@ -16,10 +32,11 @@ INSERT INTO todos (
     content,
     date,
     color,
-    is_priority
+    is_priority,
+    all_day
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
-) RETURNING id, category_id, title, content, created_at, updated_at, user_email, color, date, is_priority, status
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, category_id, title, content, created_at, updated_at, user_email, color, date, is_priority, status, deleted_at, all_day, version, change_seq, change_txid
 `
 
 type CreateTodoParams struct {
@@ -30,6 +47,7 @@ type CreateTodoParams struct {
 	Date       time.Time `json:"date"`
 	Color      string    `json:"color"`
 	IsPriority bool      `json:"is_priority"`
+	AllDay     bool      `json:"all_day"`
 }
 
 func (q *Queries) CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, error) {
@@ -41,6 +59,7 @@ func (q *Queries) CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, e
 		arg.Date,
 		arg.Color,
 		arg.IsPriority,
+		arg.AllDay,
 	)
 	var i Todo
 	err := row.Scan(
@@ -55,13 +74,19 @@ func (q *Queries) CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, e
 		&i.Date,
 		&i.IsPriority,
 		&i.Status,
+		&i.DeletedAt,
+		&i.AllDay,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
 	)
 	return i, err
 }
 
 const deleteTodo = `-- name: DeleteTodo :exec
-DELETE FROM todos
-WHERE id = $1
+UPDATE todos
+SET deleted_at = now()
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) DeleteTodo(ctx context.Context, id int32) error {
@@ -69,29 +94,69 @@ func (q *Queries) DeleteTodo(ctx context.Context, id int32) error {
 	return err
 }
 
+const getAnyTodoForUpdate = `-- name: GetAnyTodoForUpdate :one
+SELECT id, category_id, title, content, created_at, updated_at, user_email, color, date, is_priority, status, deleted_at, all_day, version, change_seq, change_txid FROM todos
+WHERE id = $1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetAnyTodoForUpdate(ctx context.Context, id int32) (Todo, error) {
+	row := q.db.QueryRowContext(ctx, getAnyTodoForUpdate, id)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Title,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserEmail,
+		&i.Color,
+		&i.Date,
+		&i.IsPriority,
+		&i.Status,
+		&i.DeletedAt,
+		&i.AllDay,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
+	)
+	return i, err
+}
+
 const getTodo = `-- name: GetTodo :one
 SELECT
-    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.color, t.is_priority,
-    c.name as category_name
+    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.all_day, t.color, t.is_priority, t.version,
+    c.name as category_name,
+    COALESCE((
+        SELECT json_agg(json_build_object('id', tg.id, 'name', tg.name) ORDER BY tg.name)
+        FROM todo_tags tt
+        INNER JOIN tags tg
+            ON tg.id = tt.tag_id
+        WHERE tt.todo_id = t.id
+    ), '[]')::json as tags
 FROM todos t
 INNER JOIN categories c
     ON c.id = t.category_id
-WHERE t.id = $1 LIMIT 1
+WHERE t.id = $1 AND t.deleted_at IS NULL LIMIT 1
 FOR NO KEY UPDATE
 `
 
 type GetTodoRow struct {
-	ID           int32     `json:"id"`
-	CategoryID   int32     `json:"category_id"`
-	UserEmail    string    `json:"user_email"`
-	Title        string    `json:"title"`
-	Content      string    `json:"content"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	Date         time.Time `json:"date"`
-	Color        string    `json:"color"`
-	IsPriority   bool      `json:"is_priority"`
-	CategoryName string    `json:"category_name"`
+	ID           int32           `json:"id"`
+	CategoryID   int32           `json:"category_id"`
+	UserEmail    string          `json:"user_email"`
+	Title        string          `json:"title"`
+	Content      string          `json:"content"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	Date         time.Time       `json:"date"`
+	AllDay       bool            `json:"all_day"`
+	Color        string          `json:"color"`
+	IsPriority   bool            `json:"is_priority"`
+	Version      int32           `json:"version"`
+	CategoryName string          `json:"category_name"`
+	Tags         json.RawMessage `json:"tags"`
 }
 
 func (q *Queries) GetTodo(ctx context.Context, id int32) (GetTodoRow, error) {
@@ -106,23 +171,67 @@ func (q *Queries) GetTodo(ctx context.Context, id int32) (GetTodoRow, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Date,
+		&i.AllDay,
 		&i.Color,
 		&i.IsPriority,
+		&i.Version,
 		&i.CategoryName,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const getTodoForUpdate = `-- name: GetTodoForUpdate :one
+SELECT id, category_id, title, content, created_at, updated_at, user_email, color, date, is_priority, status, deleted_at, all_day, version, change_seq, change_txid FROM todos
+WHERE id = $1 AND deleted_at IS NULL
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTodoForUpdate(ctx context.Context, id int32) (Todo, error) {
+	row := q.db.QueryRowContext(ctx, getTodoForUpdate, id)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Title,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserEmail,
+		&i.Color,
+		&i.Date,
+		&i.IsPriority,
+		&i.Status,
+		&i.DeletedAt,
+		&i.AllDay,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
 	)
 	return i, err
 }
 
 const listDoneTodo = `-- name: ListDoneTodo :many
 SELECT
-    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.color, t.is_priority, t.status,
-    c.name as category_name
+    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.all_day, t.color, t.is_priority, t.status,
+    c.name as category_name,
+    COALESCE((
+        SELECT json_agg(json_build_object('id', tg.id, 'name', tg.name) ORDER BY tg.name)
+        FROM todo_tags tt
+        INNER JOIN tags tg
+            ON tg.id = tt.tag_id
+        WHERE tt.todo_id = t.id
+    ), '[]')::json as tags
 FROM todos t
 INNER JOIN categories c
     ON c.id = t.category_id
 WHERE t.user_email = $1 
     AND status = TRUE 
-ORDER BY date DESC
+    AND t.deleted_at IS NULL
+    AND ($4 = 0 OR EXISTS (
+        SELECT 1 FROM todo_tags tt WHERE tt.todo_id = t.id AND tt.tag_id = $4
+    ))
+ORDER BY t.date DESC
 LIMIT $2
 OFFSET $3
 `
@@ -131,42 +240,112 @@ type ListDoneTodoParams struct {
 	UserEmail string `json:"user_email"`
 	Limit     int32  `json:"limit"`
 	Offset    int32  `json:"offset"`
+	TagID     int32  `json:"tag_id"`
 }
 
 type ListDoneTodoRow struct {
+	ID           int32           `json:"id"`
+	CategoryID   int32           `json:"category_id"`
+	UserEmail    string          `json:"user_email"`
+	Title        string          `json:"title"`
+	Content      string          `json:"content"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	Date         time.Time       `json:"date"`
+	AllDay       bool            `json:"all_day"`
+	Color        string          `json:"color"`
+	IsPriority   bool            `json:"is_priority"`
+	Status       bool            `json:"status"`
+	CategoryName string          `json:"category_name"`
+	Tags         json.RawMessage `json:"tags"`
+}
+
+func (q *Queries) ListDoneTodo(ctx context.Context, arg ListDoneTodoParams) ([]ListDoneTodoRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDoneTodo,
+		arg.UserEmail,
+		arg.Limit,
+		arg.Offset,
+		arg.TagID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDoneTodoRow{}
+	for rows.Next() {
+		var i ListDoneTodoRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryID,
+			&i.UserEmail,
+			&i.Title,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Date,
+			&i.AllDay,
+			&i.Color,
+			&i.IsPriority,
+			&i.Status,
+			&i.CategoryName,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpenTodosForCalendar = `-- name: ListOpenTodosForCalendar :many
+SELECT
+    t.id, t.title, t.content, t.created_at, t.updated_at, t.date, t.all_day, t.is_priority, t.status,
+    c.name as category_name
+FROM todos t
+INNER JOIN categories c
+    ON c.id = t.category_id
+WHERE t.user_email = $1
+    AND t.deleted_at IS NULL
+    AND t.status = false
+ORDER BY t.date ASC
+`
+
+type ListOpenTodosForCalendarRow struct {
 	ID           int32     `json:"id"`
-	CategoryID   int32     `json:"category_id"`
-	UserEmail    string    `json:"user_email"`
 	Title        string    `json:"title"`
 	Content      string    `json:"content"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 	Date         time.Time `json:"date"`
-	Color        string    `json:"color"`
+	AllDay       bool      `json:"all_day"`
 	IsPriority   bool      `json:"is_priority"`
 	Status       bool      `json:"status"`
 	CategoryName string    `json:"category_name"`
 }
 
-func (q *Queries) ListDoneTodo(ctx context.Context, arg ListDoneTodoParams) ([]ListDoneTodoRow, error) {
-	rows, err := q.db.QueryContext(ctx, listDoneTodo, arg.UserEmail, arg.Limit, arg.Offset)
+func (q *Queries) ListOpenTodosForCalendar(ctx context.Context, userEmail string) ([]ListOpenTodosForCalendarRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOpenTodosForCalendar, userEmail)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []ListDoneTodoRow{}
+	items := []ListOpenTodosForCalendarRow{}
 	for rows.Next() {
-		var i ListDoneTodoRow
+		var i ListOpenTodosForCalendarRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.CategoryID,
-			&i.UserEmail,
 			&i.Title,
 			&i.Content,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Date,
-			&i.Color,
+			&i.AllDay,
 			&i.IsPriority,
 			&i.Status,
 			&i.CategoryName,
@@ -186,14 +365,27 @@ func (q *Queries) ListDoneTodo(ctx context.Context, arg ListDoneTodoParams) ([]L
 
 const listTodayTodo = `-- name: ListTodayTodo :many
 SELECT
-    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.color, t.is_priority, t.status,
-    c.name as category_name
+    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.all_day, t.color, t.is_priority, t.status,
+    c.name as category_name,
+    COALESCE((
+        SELECT json_agg(json_build_object('id', tg.id, 'name', tg.name) ORDER BY tg.name)
+        FROM todo_tags tt
+        INNER JOIN tags tg
+            ON tg.id = tt.tag_id
+        WHERE tt.todo_id = t.id
+    ), '[]')::json as tags
 FROM todos t
 INNER JOIN categories c
     ON c.id = t.category_id
+INNER JOIN users u
+    ON u.email = t.user_email
 WHERE t.user_email = $1 
-    AND date <= now() 
+    AND (t.date AT TIME ZONE u.time_zone)::date <= (now() AT TIME ZONE u.time_zone)::date
     AND status = FALSE 
+    AND t.deleted_at IS NULL
+    AND ($4 = 0 OR EXISTS (
+        SELECT 1 FROM todo_tags tt WHERE tt.todo_id = t.id AND tt.tag_id = $4
+    ))
 ORDER BY is_priority DESC
 LIMIT $2
 OFFSET $3
@@ -203,25 +395,33 @@ type ListTodayTodoParams struct {
 	UserEmail string `json:"user_email"`
 	Limit     int32  `json:"limit"`
 	Offset    int32  `json:"offset"`
+	TagID     int32  `json:"tag_id"`
 }
 
 type ListTodayTodoRow struct {
-	ID           int32     `json:"id"`
-	CategoryID   int32     `json:"category_id"`
-	UserEmail    string    `json:"user_email"`
-	Title        string    `json:"title"`
-	Content      string    `json:"content"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	Date         time.Time `json:"date"`
-	Color        string    `json:"color"`
-	IsPriority   bool      `json:"is_priority"`
-	Status       bool      `json:"status"`
-	CategoryName string    `json:"category_name"`
+	ID           int32           `json:"id"`
+	CategoryID   int32           `json:"category_id"`
+	UserEmail    string          `json:"user_email"`
+	Title        string          `json:"title"`
+	Content      string          `json:"content"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	Date         time.Time       `json:"date"`
+	AllDay       bool            `json:"all_day"`
+	Color        string          `json:"color"`
+	IsPriority   bool            `json:"is_priority"`
+	Status       bool            `json:"status"`
+	CategoryName string          `json:"category_name"`
+	Tags         json.RawMessage `json:"tags"`
 }
 
 func (q *Queries) ListTodayTodo(ctx context.Context, arg ListTodayTodoParams) ([]ListTodayTodoRow, error) {
-	rows, err := q.db.QueryContext(ctx, listTodayTodo, arg.UserEmail, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listTodayTodo,
+		arg.UserEmail,
+		arg.Limit,
+		arg.Offset,
+		arg.TagID,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -238,10 +438,12 @@ func (q *Queries) ListTodayTodo(ctx context.Context, arg ListTodayTodoParams) ([
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Date,
+			&i.AllDay,
 			&i.Color,
 			&i.IsPriority,
 			&i.Status,
 			&i.CategoryName,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -258,14 +460,24 @@ func (q *Queries) ListTodayTodo(ctx context.Context, arg ListTodayTodoParams) ([
 
 const listTodoByUser = `-- name: ListTodoByUser :many
 SELECT
-    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.color, t.is_priority, t.status,
-    c.name as category_name
+    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.all_day, t.color, t.is_priority, t.status,
+    c.name as category_name,
+    COALESCE((
+        SELECT json_agg(json_build_object('id', tg.id, 'name', tg.name) ORDER BY tg.name)
+        FROM todo_tags tt
+        INNER JOIN tags tg
+            ON tg.id = tt.tag_id
+        WHERE tt.todo_id = t.id
+    ), '[]')::json as tags
 FROM todos t
 INNER JOIN categories c
     ON c.id = t.category_id
 WHERE t.user_email = $1 
-
-ORDER BY created_at ASC
+    AND t.deleted_at IS NULL
+    AND ($4 = 0 OR EXISTS (
+        SELECT 1 FROM todo_tags tt WHERE tt.todo_id = t.id AND tt.tag_id = $4
+    ))
+ORDER BY t.created_at ASC
 LIMIT $2
 OFFSET $3
 `
@@ -274,25 +486,33 @@ type ListTodoByUserParams struct {
 	UserEmail string `json:"user_email"`
 	Limit     int32  `json:"limit"`
 	Offset    int32  `json:"offset"`
+	TagID     int32  `json:"tag_id"`
 }
 
 type ListTodoByUserRow struct {
-	ID           int32     `json:"id"`
-	CategoryID   int32     `json:"category_id"`
-	UserEmail    string    `json:"user_email"`
-	Title        string    `json:"title"`
-	Content      string    `json:"content"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	Date         time.Time `json:"date"`
-	Color        string    `json:"color"`
-	IsPriority   bool      `json:"is_priority"`
-	Status       bool      `json:"status"`
-	CategoryName string    `json:"category_name"`
+	ID           int32           `json:"id"`
+	CategoryID   int32           `json:"category_id"`
+	UserEmail    string          `json:"user_email"`
+	Title        string          `json:"title"`
+	Content      string          `json:"content"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	Date         time.Time       `json:"date"`
+	AllDay       bool            `json:"all_day"`
+	Color        string          `json:"color"`
+	IsPriority   bool            `json:"is_priority"`
+	Status       bool            `json:"status"`
+	CategoryName string          `json:"category_name"`
+	Tags         json.RawMessage `json:"tags"`
 }
 
 func (q *Queries) ListTodoByUser(ctx context.Context, arg ListTodoByUserParams) ([]ListTodoByUserRow, error) {
-	rows, err := q.db.QueryContext(ctx, listTodoByUser, arg.UserEmail, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listTodoByUser,
+		arg.UserEmail,
+		arg.Limit,
+		arg.Offset,
+		arg.TagID,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -309,10 +529,188 @@ func (q *Queries) ListTodoByUser(ctx context.Context, arg ListTodoByUserParams)
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Date,
+			&i.AllDay,
 			&i.Color,
 			&i.IsPriority,
 			&i.Status,
 			&i.CategoryName,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodosForExport = `-- name: ListTodosForExport :many
+SELECT
+    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.all_day, t.color, t.is_priority, t.status,
+    c.name as category_name,
+    COALESCE((
+        SELECT json_agg(json_build_object('id', tg.id, 'name', tg.name) ORDER BY tg.name)
+        FROM todo_tags tt
+        INNER JOIN tags tg
+            ON tg.id = tt.tag_id
+        WHERE tt.todo_id = t.id
+    ), '[]')::json as tags
+FROM todos t
+INNER JOIN categories c
+    ON c.id = t.category_id
+WHERE t.user_email = $1
+    AND t.deleted_at IS NULL
+ORDER BY t.created_at ASC, t.id ASC
+`
+
+type ListTodosForExportRow struct {
+	ID           int32           `json:"id"`
+	CategoryID   int32           `json:"category_id"`
+	UserEmail    string          `json:"user_email"`
+	Title        string          `json:"title"`
+	Content      string          `json:"content"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	Date         time.Time       `json:"date"`
+	AllDay       bool            `json:"all_day"`
+	Color        string          `json:"color"`
+	IsPriority   bool            `json:"is_priority"`
+	Status       bool            `json:"status"`
+	CategoryName string          `json:"category_name"`
+	Tags         json.RawMessage `json:"tags"`
+}
+
+func (q *Queries) ListTodosForExport(ctx context.Context, userEmail string) ([]ListTodosForExportRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTodosForExport, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTodosForExportRow{}
+	for rows.Next() {
+		var i ListTodosForExportRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryID,
+			&i.UserEmail,
+			&i.Title,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Date,
+			&i.AllDay,
+			&i.Color,
+			&i.IsPriority,
+			&i.Status,
+			&i.CategoryName,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodosForUpdate = `-- name: ListTodosForUpdate :many
+SELECT id, category_id, title, content, created_at, updated_at, user_email, color, date, is_priority, status, deleted_at, all_day, version, change_seq, change_txid FROM todos
+WHERE id = ANY($1::int[]) AND deleted_at IS NULL
+ORDER BY id
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) ListTodosForUpdate(ctx context.Context, ids []int32) ([]Todo, error) {
+	rows, err := q.db.QueryContext(ctx, listTodosForUpdate, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Todo{}
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryID,
+			&i.Title,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserEmail,
+			&i.Color,
+			&i.Date,
+			&i.IsPriority,
+			&i.Status,
+			&i.DeletedAt,
+			&i.AllDay,
+			&i.Version,
+			&i.ChangeSeq,
+			&i.ChangeTxid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTrashedTodo = `-- name: ListTrashedTodo :many
+SELECT id, category_id, title, content, created_at, updated_at, user_email, color, date, is_priority, status, deleted_at, all_day, version, change_seq, change_txid FROM todos
+WHERE user_email = $1
+    AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListTrashedTodoParams struct {
+	UserEmail string `json:"user_email"`
+	Limit     int32  `json:"limit"`
+	Offset    int32  `json:"offset"`
+}
+
+func (q *Queries) ListTrashedTodo(ctx context.Context, arg ListTrashedTodoParams) ([]Todo, error) {
+	rows, err := q.db.QueryContext(ctx, listTrashedTodo, arg.UserEmail, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Todo{}
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryID,
+			&i.Title,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserEmail,
+			&i.Color,
+			&i.Date,
+			&i.IsPriority,
+			&i.Status,
+			&i.DeletedAt,
+			&i.AllDay,
+			&i.Version,
+			&i.ChangeSeq,
+			&i.ChangeTxid,
 		); err != nil {
 			return nil, err
 		}
@@ -329,15 +727,28 @@ func (q *Queries) ListTodoByUser(ctx context.Context, arg ListTodoByUserParams)
 
 const listUpcomingTodo = `-- name: ListUpcomingTodo :many
 SELECT
-    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.color, t.is_priority, t.status,
-    c.name as category_name
+    t.id, t.category_id, t.user_email, t.title, t.content, t.created_at, t.updated_at, t.date, t.all_day, t.color, t.is_priority, t.status,
+    c.name as category_name,
+    COALESCE((
+        SELECT json_agg(json_build_object('id', tg.id, 'name', tg.name) ORDER BY tg.name)
+        FROM todo_tags tt
+        INNER JOIN tags tg
+            ON tg.id = tt.tag_id
+        WHERE tt.todo_id = t.id
+    ), '[]')::json as tags
 FROM todos t
 INNER JOIN categories c
     ON c.id = t.category_id
+INNER JOIN users u
+    ON u.email = t.user_email
 WHERE t.user_email = $1 
-    AND date > now() 
+    AND (t.date AT TIME ZONE u.time_zone)::date > (now() AT TIME ZONE u.time_zone)::date
     AND status = FALSE 
-ORDER BY is_priority DESC, date ASC
+    AND t.deleted_at IS NULL
+    AND ($4 = 0 OR EXISTS (
+        SELECT 1 FROM todo_tags tt WHERE tt.todo_id = t.id AND tt.tag_id = $4
+    ))
+ORDER BY is_priority DESC, t.date ASC
 LIMIT $2
 OFFSET $3
 `
@@ -346,25 +757,33 @@ type ListUpcomingTodoParams struct {
 	UserEmail string `json:"user_email"`
 	Limit     int32  `json:"limit"`
 	Offset    int32  `json:"offset"`
+	TagID     int32  `json:"tag_id"`
 }
 
 type ListUpcomingTodoRow struct {
-	ID           int32     `json:"id"`
-	CategoryID   int32     `json:"category_id"`
-	UserEmail    string    `json:"user_email"`
-	Title        string    `json:"title"`
-	Content      string    `json:"content"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	Date         time.Time `json:"date"`
-	Color        string    `json:"color"`
-	IsPriority   bool      `json:"is_priority"`
-	Status       bool      `json:"status"`
-	CategoryName string    `json:"category_name"`
+	ID           int32           `json:"id"`
+	CategoryID   int32           `json:"category_id"`
+	UserEmail    string          `json:"user_email"`
+	Title        string          `json:"title"`
+	Content      string          `json:"content"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	Date         time.Time       `json:"date"`
+	AllDay       bool            `json:"all_day"`
+	Color        string          `json:"color"`
+	IsPriority   bool            `json:"is_priority"`
+	Status       bool            `json:"status"`
+	CategoryName string          `json:"category_name"`
+	Tags         json.RawMessage `json:"tags"`
 }
 
 func (q *Queries) ListUpcomingTodo(ctx context.Context, arg ListUpcomingTodoParams) ([]ListUpcomingTodoRow, error) {
-	rows, err := q.db.QueryContext(ctx, listUpcomingTodo, arg.UserEmail, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listUpcomingTodo,
+		arg.UserEmail,
+		arg.Limit,
+		arg.Offset,
+		arg.TagID,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -381,10 +800,12 @@ func (q *Queries) ListUpcomingTodo(ctx context.Context, arg ListUpcomingTodoPara
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Date,
+			&i.AllDay,
 			&i.Color,
 			&i.IsPriority,
 			&i.Status,
 			&i.CategoryName,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -402,8 +823,8 @@ func (q *Queries) ListUpcomingTodo(ctx context.Context, arg ListUpcomingTodoPara
 const markAsCompleteTodo = `-- name: MarkAsCompleteTodo :one
 UPDATE todos
 SET status = true
-WHERE id = $1
-RETURNING id, category_id, title, content, created_at, updated_at, user_email, color, date, is_priority, status
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, category_id, title, content, created_at, updated_at, user_email, color, date, is_priority, status, deleted_at, all_day, version, change_seq, change_txid
 `
 
 func (q *Queries) MarkAsCompleteTodo(ctx context.Context, id int32) (Todo, error) {
@@ -421,15 +842,289 @@ func (q *Queries) MarkAsCompleteTodo(ctx context.Context, id int32) (Todo, error
 		&i.Date,
 		&i.IsPriority,
 		&i.Status,
+		&i.DeletedAt,
+		&i.AllDay,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
 	)
 	return i, err
 }
 
-const updateTodoByUser = `-- name: UpdateTodoByUser :one
+const markAsIncompleteTodo = `-- name: MarkAsIncompleteTodo :one
+UPDATE todos
+SET status = false
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, category_id, title, content, created_at, updated_at, user_email, color, date, is_priority, status, deleted_at, all_day, version, change_seq, change_txid
+`
+
+func (q *Queries) MarkAsIncompleteTodo(ctx context.Context, id int32) (Todo, error) {
+	row := q.db.QueryRowContext(ctx, markAsIncompleteTodo, id)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Title,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserEmail,
+		&i.Color,
+		&i.Date,
+		&i.IsPriority,
+		&i.Status,
+		&i.DeletedAt,
+		&i.AllDay,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
+	)
+	return i, err
+}
+
+const moveTodo = `-- name: MoveTodo :one
+UPDATE todos
+SET category_id = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, category_id, title, content, created_at, updated_at, user_email, color, date, is_priority, status, deleted_at, all_day, version, change_seq, change_txid
+`
+
+type MoveTodoParams struct {
+	ID         int32 `json:"id"`
+	CategoryID int32 `json:"category_id"`
+}
+
+func (q *Queries) MoveTodo(ctx context.Context, arg MoveTodoParams) (Todo, error) {
+	row := q.db.QueryRowContext(ctx, moveTodo, arg.ID, arg.CategoryID)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Title,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserEmail,
+		&i.Color,
+		&i.Date,
+		&i.IsPriority,
+		&i.Status,
+		&i.DeletedAt,
+		&i.AllDay,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
+	)
+	return i, err
+}
+
+const moveTodosToCategory = `-- name: MoveTodosToCategory :execrows
+UPDATE todos
+SET category_id = $1, updated_at = now()
+WHERE category_id = $2 AND deleted_at IS NULL
+`
+
+type MoveTodosToCategoryParams struct {
+	ToCategoryID   int32 `json:"to_category_id"`
+	FromCategoryID int32 `json:"from_category_id"`
+}
+
+func (q *Queries) MoveTodosToCategory(ctx context.Context, arg MoveTodosToCategoryParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, moveTodosToCategory, arg.ToCategoryID, arg.FromCategoryID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const patchTodo = `-- name: PatchTodo :one
 UPDATE todos
-SET category_id = $2, title = $3, content = $4, updated_at = now(), date = $5, color = $6, is_priority = $7
+SET
+    category_id = COALESCE($1, category_id),
+    title = COALESCE($2, title),
+    content = COALESCE($3, content),
+    date = COALESCE($4, date),
+    all_day = COALESCE($5, all_day),
+    color = COALESCE($6, color),
+    is_priority = COALESCE($7, is_priority),
+    updated_at = now()
+WHERE id = $8 AND deleted_at IS NULL
+RETURNING id, category_id, title, content, created_at, updated_at, user_email, color, date, is_priority, status, deleted_at, all_day, version, change_seq, change_txid
+`
+
+type PatchTodoParams struct {
+	CategoryID sql.NullInt32  `json:"category_id"`
+	Title      sql.NullString `json:"title"`
+	Content    sql.NullString `json:"content"`
+	Date       sql.NullTime   `json:"date"`
+	AllDay     sql.NullBool   `json:"all_day"`
+	Color      sql.NullString `json:"color"`
+	IsPriority sql.NullBool   `json:"is_priority"`
+	ID         int32          `json:"id"`
+}
+
+func (q *Queries) PatchTodo(ctx context.Context, arg PatchTodoParams) (Todo, error) {
+	row := q.db.QueryRowContext(ctx, patchTodo,
+		arg.CategoryID,
+		arg.Title,
+		arg.Content,
+		arg.Date,
+		arg.AllDay,
+		arg.Color,
+		arg.IsPriority,
+		arg.ID,
+	)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Title,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserEmail,
+		&i.Color,
+		&i.Date,
+		&i.IsPriority,
+		&i.Status,
+		&i.DeletedAt,
+		&i.AllDay,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
+	)
+	return i, err
+}
+
+const purgeTrashedTodos = `-- name: PurgeTrashedTodos :execrows
+DELETE FROM todos
+WHERE deleted_at < $1::timestamp
+`
+
+func (q *Queries) PurgeTrashedTodos(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeTrashedTodos, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const rescheduleTodo = `-- name: RescheduleTodo :one
+UPDATE todos
+SET date = $2, all_day = $3, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, category_id, title, content, created_at, updated_at, user_email, color, date, is_priority, status, deleted_at, all_day, version, change_seq, change_txid
+`
+
+type RescheduleTodoParams struct {
+	ID     int32     `json:"id"`
+	Date   time.Time `json:"date"`
+	AllDay bool      `json:"all_day"`
+}
+
+func (q *Queries) RescheduleTodo(ctx context.Context, arg RescheduleTodoParams) (Todo, error) {
+	row := q.db.QueryRowContext(ctx, rescheduleTodo, arg.ID, arg.Date, arg.AllDay)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Title,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserEmail,
+		&i.Color,
+		&i.Date,
+		&i.IsPriority,
+		&i.Status,
+		&i.DeletedAt,
+		&i.AllDay,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
+	)
+	return i, err
+}
+
+const restoreTodo = `-- name: RestoreTodo :one
+UPDATE todos
+SET deleted_at = NULL
 WHERE id = $1
-RETURNING id, category_id, title, content, created_at, updated_at, user_email, color, date, is_priority, status
+    AND user_email = $2
+    AND deleted_at IS NOT NULL
+RETURNING id, category_id, title, content, created_at, updated_at, user_email, color, date, is_priority, status, deleted_at, all_day, version, change_seq, change_txid
+`
+
+type RestoreTodoParams struct {
+	ID        int32  `json:"id"`
+	UserEmail string `json:"user_email"`
+}
+
+func (q *Queries) RestoreTodo(ctx context.Context, arg RestoreTodoParams) (Todo, error) {
+	row := q.db.QueryRowContext(ctx, restoreTodo, arg.ID, arg.UserEmail)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Title,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserEmail,
+		&i.Color,
+		&i.Date,
+		&i.IsPriority,
+		&i.Status,
+		&i.DeletedAt,
+		&i.AllDay,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
+	)
+	return i, err
+}
+
+const setTodoPriority = `-- name: SetTodoPriority :one
+UPDATE todos
+SET is_priority = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, category_id, title, content, created_at, updated_at, user_email, color, date, is_priority, status, deleted_at, all_day, version, change_seq, change_txid
+`
+
+type SetTodoPriorityParams struct {
+	ID         int32 `json:"id"`
+	IsPriority bool  `json:"is_priority"`
+}
+
+func (q *Queries) SetTodoPriority(ctx context.Context, arg SetTodoPriorityParams) (Todo, error) {
+	row := q.db.QueryRowContext(ctx, setTodoPriority, arg.ID, arg.IsPriority)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Title,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserEmail,
+		&i.Color,
+		&i.Date,
+		&i.IsPriority,
+		&i.Status,
+		&i.DeletedAt,
+		&i.AllDay,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
+	)
+	return i, err
+}
+
+const updateTodoByUser = `-- name: UpdateTodoByUser :one
+UPDATE todos
+SET category_id = $2, title = $3, content = $4, updated_at = now(), date = $5, all_day = $8, color = $6, is_priority = $7
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, category_id, title, content, created_at, updated_at, user_email, color, date, is_priority, status, deleted_at, all_day, version, change_seq, change_txid
 `
 
 type UpdateTodoByUserParams struct {
@@ -440,6 +1135,7 @@ type UpdateTodoByUserParams struct {
 	Date       time.Time `json:"date"`
 	Color      string    `json:"color"`
 	IsPriority bool      `json:"is_priority"`
+	AllDay     bool      `json:"all_day"`
 }
 
 func (q *Queries) UpdateTodoByUser(ctx context.Context, arg UpdateTodoByUserParams) (Todo, error) {
@@ -451,6 +1147,7 @@ func (q *Queries) UpdateTodoByUser(ctx context.Context, arg UpdateTodoByUserPara
 		arg.Date,
 		arg.Color,
 		arg.IsPriority,
+		arg.AllDay,
 	)
 	var i Todo
 	err := row.Scan(
@@ -465,6 +1162,11 @@ func (q *Queries) UpdateTodoByUser(ctx context.Context, arg UpdateTodoByUserPara
 		&i.Date,
 		&i.IsPriority,
 		&i.Status,
+		&i.DeletedAt,
+		&i.AllDay,
+		&i.Version,
+		&i.ChangeSeq,
+		&i.ChangeTxid,
 	)
 	return i, err
 }
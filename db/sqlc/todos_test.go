@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -111,3 +112,142 @@ func TestDeleteTodo(t *testing.T) {
 	err := testQueries.DeleteTodo(context.Background(), todo.ID)
 	require.NoError(t, err)
 }
+
+func TestRestoreTodo(t *testing.T) {
+	user := createRandomUser(t)
+	category := createRandomCategory(t)
+	todo1 := createRandomTodo(t, user.Email, category.ID)
+
+	err := testQueries.DeleteTodo(context.Background(), todo1.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetTodo(context.Background(), todo1.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	trashed, err := testQueries.ListTrashedTodo(context.Background(), ListTrashedTodoParams{
+		UserEmail: user.Email,
+		Limit:     5,
+		Offset:    0,
+	})
+	require.NoError(t, err)
+	require.Len(t, trashed, 1)
+	require.Equal(t, todo1.ID, trashed[0].ID)
+	require.True(t, trashed[0].DeletedAt.Valid)
+
+	todo2, err := testQueries.RestoreTodo(context.Background(), RestoreTodoParams{
+		ID:        todo1.ID,
+		UserEmail: user.Email,
+	})
+	require.NoError(t, err)
+	require.Equal(t, todo1.ID, todo2.ID)
+	require.False(t, todo2.DeletedAt.Valid)
+}
+
+func TestRestoreTodoWrongUser(t *testing.T) {
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	category := createRandomCategory(t)
+	todo := createRandomTodo(t, user.Email, category.ID)
+
+	err := testQueries.DeleteTodo(context.Background(), todo.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.RestoreTodo(context.Background(), RestoreTodoParams{
+		ID:        todo.ID,
+		UserEmail: other.Email,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestPurgeTrashedTodos(t *testing.T) {
+	user := createRandomUser(t)
+	category := createRandomCategory(t)
+	todo := createRandomTodo(t, user.Email, category.ID)
+
+	err := testQueries.DeleteTodo(context.Background(), todo.ID)
+	require.NoError(t, err)
+
+	purged, err := testQueries.PurgeTrashedTodos(context.Background(), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, purged, int64(1))
+
+	_, err = testQueries.RestoreTodo(context.Background(), RestoreTodoParams{
+		ID:        todo.ID,
+		UserEmail: user.Email,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestListTodayTodoInUserTimeZone(t *testing.T) {
+	user := createRandomUser(t)
+	category := createRandomCategory(t)
+
+	// far enough from UTC that its calendar day often differs from the server's
+	user, err := testQueries.UpdateUserTimeZone(context.Background(), UpdateUserTimeZoneParams{
+		Email:    user.Email,
+		TimeZone: "Pacific/Kiritimati",
+	})
+	require.NoError(t, err)
+
+	loc, err := util.LoadTimeZone(user.TimeZone)
+	require.NoError(t, err)
+
+	tomorrow := time.Now().In(loc).AddDate(0, 0, 1)
+	todo, err := testQueries.CreateTodo(context.Background(), CreateTodoParams{
+		CategoryID: category.ID,
+		UserEmail:  user.Email,
+		Title:      "Todo title 1",
+		Content:    "Todo content 1",
+		Date:       time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 0, 0, 0, 0, loc),
+		AllDay:     true,
+		Color:      util.RandomColor(),
+	})
+	require.NoError(t, err)
+
+	arg := ListTodayTodoParams{UserEmail: user.Email, Limit: 10}
+	today, err := testQueries.ListTodayTodo(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, today)
+
+	upcoming, err := testQueries.ListUpcomingTodo(context.Background(), ListUpcomingTodoParams(arg))
+	require.NoError(t, err)
+	require.Len(t, upcoming, 1)
+	require.Equal(t, todo.ID, upcoming[0].ID)
+}
+
+func TestTodoVersion(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	category := createRandomCategory(t)
+	todo1 := createRandomTodo(t, user.Email, category.ID)
+	require.Equal(t, int32(1), todo1.Version)
+
+	todo2, err := store.CompleteTodoTx(context.Background(), CompleteTodoTxParams{
+		ID:      todo1.ID,
+		Version: todo1.Version,
+	})
+	require.NoError(t, err)
+	require.Equal(t, todo1.Version+1, todo2.Version)
+
+	// a client still holding the first version must not overwrite the change
+	_, err = store.PatchTodoTx(context.Background(), PatchTodoTxParams{
+		PatchTodoParams: PatchTodoParams{
+			ID:    todo1.ID,
+			Title: sql.NullString{String: "stale", Valid: true},
+		},
+		Version: todo1.Version,
+	})
+	require.ErrorIs(t, err, ErrVersionMismatch)
+
+	err = store.DeleteTodoTx(context.Background(), DeleteTodoTxParams{
+		ID:        todo1.ID,
+		UserEmail: user.Email,
+		Version:   todo1.Version,
+	})
+	require.ErrorIs(t, err, ErrVersionMismatch)
+
+	row, err := testQueries.GetTodo(context.Background(), todo1.ID)
+	require.NoError(t, err)
+	require.Equal(t, todo1.Title, row.Title)
+	require.Equal(t, todo2.Version, row.Version)
+}
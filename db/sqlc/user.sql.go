@@ -5,6 +5,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 const createUser = `-- name: CreateUser :one
@@ -13,10 +14,11 @@ INSERT INTO users (
     address,
     pic,
     hashed_password,
-    email
+    email,
+    time_zone
 ) VALUES (
-    $1, $2, $3, $4, $5
-) RETURNING id, name, address, pic, created_at, updated_at, hashed_password, email
+    $1, $2, $3, $4, $5, $6
+) RETURNING id, name, address, pic, created_at, updated_at, hashed_password, email, time_zone, calendar_token
 `
 
 type CreateUserParams struct {
@@ -25,6 +27,7 @@ type CreateUserParams struct {
 	Pic            string `json:"pic"`
 	HashedPassword string `json:"hashed_password"`
 	Email          string `json:"email"`
+	TimeZone       string `json:"time_zone"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -34,6 +37,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.Pic,
 		arg.HashedPassword,
 		arg.Email,
+		arg.TimeZone,
 	)
 	var i User
 	err := row.Scan(
@@ -45,6 +49,8 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.UpdatedAt,
 		&i.HashedPassword,
 		&i.Email,
+		&i.TimeZone,
+		&i.CalendarToken,
 	)
 	return i, err
 }
@@ -60,7 +66,7 @@ func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, name, address, pic, created_at, updated_at, hashed_password, email FROM users
+SELECT id, name, address, pic, created_at, updated_at, hashed_password, email, time_zone, calendar_token FROM users
 WHERE email = $1
 `
 
@@ -76,12 +82,37 @@ func (q *Queries) GetUser(ctx context.Context, email string) (User, error) {
 		&i.UpdatedAt,
 		&i.HashedPassword,
 		&i.Email,
+		&i.TimeZone,
+		&i.CalendarToken,
+	)
+	return i, err
+}
+
+const getUserByCalendarToken = `-- name: GetUserByCalendarToken :one
+SELECT id, name, address, pic, created_at, updated_at, hashed_password, email, time_zone, calendar_token FROM users
+WHERE calendar_token = $1
+`
+
+func (q *Queries) GetUserByCalendarToken(ctx context.Context, calendarToken sql.NullString) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByCalendarToken, calendarToken)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Address,
+		&i.Pic,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.HashedPassword,
+		&i.Email,
+		&i.TimeZone,
+		&i.CalendarToken,
 	)
 	return i, err
 }
 
 const listUsers = `-- name: ListUsers :many
-SELECT id, name, address, pic, created_at, updated_at, hashed_password, email FROM users
+SELECT id, name, address, pic, created_at, updated_at, hashed_password, email, time_zone, calendar_token FROM users
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -110,6 +141,8 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.UpdatedAt,
 			&i.HashedPassword,
 			&i.Email,
+			&i.TimeZone,
+			&i.CalendarToken,
 		); err != nil {
 			return nil, err
 		}
@@ -124,11 +157,41 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	return items, nil
 }
 
+const setCalendarToken = `-- name: SetCalendarToken :one
+UPDATE users
+SET calendar_token = $2, updated_at = now()
+WHERE email = $1
+RETURNING id, name, address, pic, created_at, updated_at, hashed_password, email, time_zone, calendar_token
+`
+
+type SetCalendarTokenParams struct {
+	Email         string         `json:"email"`
+	CalendarToken sql.NullString `json:"calendar_token"`
+}
+
+func (q *Queries) SetCalendarToken(ctx context.Context, arg SetCalendarTokenParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setCalendarToken, arg.Email, arg.CalendarToken)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Address,
+		&i.Pic,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.HashedPassword,
+		&i.Email,
+		&i.TimeZone,
+		&i.CalendarToken,
+	)
+	return i, err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET name = $2, address = $3, pic = $4, email = $5, updated_at = now()
 WHERE id = $1
-RETURNING id, name, address, pic, created_at, updated_at, hashed_password, email
+RETURNING id, name, address, pic, created_at, updated_at, hashed_password, email, time_zone, calendar_token
 `
 
 type UpdateUserParams struct {
@@ -157,6 +220,8 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.UpdatedAt,
 		&i.HashedPassword,
 		&i.Email,
+		&i.TimeZone,
+		&i.CalendarToken,
 	)
 	return i, err
 }
@@ -165,7 +230,7 @@ const updateUserPhoto = `-- name: UpdateUserPhoto :one
 UPDATE users
 SET pic = $2, updated_at = now()
 WHERE email = $1
-RETURNING id, name, address, pic, created_at, updated_at, hashed_password, email
+RETURNING id, name, address, pic, created_at, updated_at, hashed_password, email, time_zone, calendar_token
 `
 
 type UpdateUserPhotoParams struct {
@@ -185,6 +250,38 @@ func (q *Queries) UpdateUserPhoto(ctx context.Context, arg UpdateUserPhotoParams
 		&i.UpdatedAt,
 		&i.HashedPassword,
 		&i.Email,
+		&i.TimeZone,
+		&i.CalendarToken,
+	)
+	return i, err
+}
+
+const updateUserTimeZone = `-- name: UpdateUserTimeZone :one
+UPDATE users
+SET time_zone = $2, updated_at = now()
+WHERE email = $1
+RETURNING id, name, address, pic, created_at, updated_at, hashed_password, email, time_zone, calendar_token
+`
+
+type UpdateUserTimeZoneParams struct {
+	Email    string `json:"email"`
+	TimeZone string `json:"time_zone"`
+}
+
+func (q *Queries) UpdateUserTimeZone(ctx context.Context, arg UpdateUserTimeZoneParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserTimeZone, arg.Email, arg.TimeZone)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Address,
+		&i.Pic,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.HashedPassword,
+		&i.Email,
+		&i.TimeZone,
+		&i.CalendarToken,
 	)
 	return i, err
 }
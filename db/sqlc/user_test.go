@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/maslow123/todoapp-services/util"
@@ -17,6 +18,7 @@ func createRandomUser(t *testing.T) User {
 		Pic:            "omama.jpg",
 		Email:          util.RandomEmail(),
 		HashedPassword: hashedPassword,
+		TimeZone:       util.DefaultTimeZone,
 	}
 
 	user, err := testQueries.CreateUser(context.Background(), arg)
@@ -27,6 +29,7 @@ func createRandomUser(t *testing.T) User {
 	require.Equal(t, arg.Address, user.Address)
 	require.Equal(t, arg.Pic, user.Pic)
 	require.Equal(t, arg.Email, user.Email)
+	require.Equal(t, arg.TimeZone, user.TimeZone)
 
 	return user
 }
@@ -91,3 +94,37 @@ func TestDeleteUser(t *testing.T) {
 	err := testQueries.DeleteUser(context.Background(), user1.ID)
 	require.NoError(t, err)
 }
+
+func TestUpdateUserTimeZone(t *testing.T) {
+	user1 := createRandomUser(t)
+
+	user2, err := testQueries.UpdateUserTimeZone(context.Background(), UpdateUserTimeZoneParams{
+		Email:    user1.Email,
+		TimeZone: "Asia/Jakarta",
+	})
+	require.NoError(t, err)
+	require.Equal(t, user1.ID, user2.ID)
+	require.Equal(t, "Asia/Jakarta", user2.TimeZone)
+}
+
+func TestCalendarToken(t *testing.T) {
+	user1 := createRandomUser(t)
+	calendarToken := sql.NullString{String: util.RandomString(48), Valid: true}
+
+	user2, err := testQueries.SetCalendarToken(context.Background(), SetCalendarTokenParams{
+		Email:         user1.Email,
+		CalendarToken: calendarToken,
+	})
+	require.NoError(t, err)
+	require.Equal(t, calendarToken, user2.CalendarToken)
+
+	user3, err := testQueries.GetUserByCalendarToken(context.Background(), calendarToken)
+	require.NoError(t, err)
+	require.Equal(t, user1.ID, user3.ID)
+
+	_, err = testQueries.SetCalendarToken(context.Background(), SetCalendarTokenParams{Email: user1.Email})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetUserByCalendarToken(context.Background(), calendarToken)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+type DeliverWebhooksParams struct {
+	Limit int32
+	// Lease is how long claimed deliveries stay hidden from other pollers,
+	// see DeliverRemindersParams.
+	Lease time.Duration
+	// Deliver sends a single delivery and returns the HTTP status it got back.
+	Deliver     func(ctx context.Context, delivery ClaimWebhookDeliveriesRow) (int32, error)
+	MaxAttempts int32
+	RetryDelay  func(attempts int32) time.Duration
+}
+
+type DeliverWebhooksResult struct {
+	Delivered int `json:"delivered"`
+	Retried   int `json:"retried"`
+	Failed    int `json:"failed"`
+}
+
+// DeliverWebhooks drains due outbox rows. Like DeliverReminders it commits
+// the claim before calling any endpoint and records each outcome after.
+func (store *SQLStore) DeliverWebhooks(ctx context.Context, arg DeliverWebhooksParams) (DeliverWebhooksResult, error) {
+	var result DeliverWebhooksResult
+
+	deliveries, err := store.ClaimWebhookDeliveries(ctx, ClaimWebhookDeliveriesParams{
+		LeaseUntil: time.Now().Add(arg.Lease),
+		MaxRows:    arg.Limit,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	for _, delivery := range deliveries {
+		status, deliverErr := arg.Deliver(ctx, delivery)
+		attempts := delivery.Attempts + 1
+
+		switch {
+		case deliverErr == nil:
+			err = store.MarkWebhookDelivered(ctx, MarkWebhookDeliveredParams{
+				ID:             delivery.ID,
+				ResponseStatus: status,
+			})
+			result.Delivered++
+		case attempts >= arg.MaxAttempts:
+			err = store.MarkWebhookFailed(ctx, MarkWebhookFailedParams{
+				ID:             delivery.ID,
+				ResponseStatus: status,
+				LastError:      deliverErr.Error(),
+			})
+			result.Failed++
+		default:
+			err = store.MarkWebhookRetry(ctx, MarkWebhookRetryParams{
+				ID:             delivery.ID,
+				ResponseStatus: status,
+				LastError:      deliverErr.Error(),
+				NextAttemptAt:  time.Now().Add(arg.RetryDelay(attempts)),
+			})
+			result.Retried++
+		}
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
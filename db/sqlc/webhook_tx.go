@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const (
+	EventTodoCreated     = "todo.created"
+	EventTodoUpdated     = "todo.updated"
+	EventTodoCompleted   = "todo.completed"
+	EventTodoDeleted     = "todo.deleted"
+	EventCategoryCreated = "category.created"
+	EventCategoryUpdated = "category.updated"
+	EventCategoryDeleted = "category.deleted"
+)
+
+const (
+	WebhookStatusPending   = "pending"
+	WebhookStatusDelivered = "delivered"
+	WebhookStatusFailed    = "failed"
+)
+
+// EventPayload is the body sent to webhook endpoints.
+type EventPayload struct {
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// enqueueEvent writes a delivery to the outbox for every webhook of userEmail
+// subscribed to event. It must run in the transaction making the change.
+func enqueueEvent(ctx context.Context, q *Queries, userEmail, event string, data interface{}) error {
+	payload, err := json.Marshal(EventPayload{
+		Event:     event,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = q.EnqueueWebhookEvent(ctx, EnqueueWebhookEventParams{
+		Event:     event,
+		Payload:   payload,
+		UserEmail: userEmail,
+	})
+	return err
+}
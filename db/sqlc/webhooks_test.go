@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomWebhook(t *testing.T, userEmail string, events ...string) Webhook {
+	arg := CreateWebhookParams{
+		UserEmail: userEmail,
+		Url:       "https://example.com/" + util.RandomString(8),
+		Secret:    util.RandomString(64),
+		Events:    events,
+	}
+
+	webhook, err := testQueries.CreateWebhook(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Events, webhook.Events)
+	require.True(t, webhook.Active)
+
+	return webhook
+}
+
+func listDeliveries(t *testing.T, webhook Webhook) []WebhookDelivery {
+	deliveries, err := testQueries.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+		WebhookID: webhook.ID,
+		UserEmail: webhook.UserEmail,
+		Limit:     100,
+	})
+	require.NoError(t, err)
+	return deliveries
+}
+
+func TestTodoTxEnqueuesWebhookEvents(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	category := createRandomCategory(t)
+	created := createRandomWebhook(t, user.Email, EventTodoCreated)
+	all := createRandomWebhook(t, user.Email, "todo.*")
+	categories := createRandomWebhook(t, user.Email, "category.*")
+
+	result, err := store.CreateTodoTx(context.Background(), CreateTodoTxParams{
+		CreateTodoParams: CreateTodoParams{
+			CategoryID: category.ID,
+			UserEmail:  user.Email,
+			Title:      "Todo title 1",
+			Content:    "Todo content 1",
+			Color:      util.RandomColor(),
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = store.CompleteTodoTx(context.Background(), CompleteTodoTxParams{ID: result.Todo.ID})
+	require.NoError(t, err)
+
+	require.Len(t, listDeliveries(t, created), 1)
+	require.Len(t, listDeliveries(t, all), 2)
+	require.Empty(t, listDeliveries(t, categories))
+
+	// a failed change leaves nothing in the outbox
+	_, err = store.CreateTodoTx(context.Background(), CreateTodoTxParams{
+		CreateTodoParams: CreateTodoParams{
+			CategoryID: category.ID,
+			UserEmail:  user.Email,
+			Title:      "Todo title 2",
+			Content:    "Todo content 2",
+			Color:      util.RandomColor(),
+		},
+		TagIDs: []int32{-1},
+	})
+	require.ErrorIs(t, err, ErrInvalidTags)
+	require.Len(t, listDeliveries(t, created), 1)
+}
+
+func TestDeliverWebhooks(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	webhook := createRandomWebhook(t, user.Email, "category.*")
+
+	_, err := store.CreateCategoryTx(context.Background(), CreateCategoryTxParams{
+		Name:      util.RandomString(10),
+		UserEmail: user.Email,
+	})
+	require.NoError(t, err)
+
+	_, err = store.DeliverWebhooks(context.Background(), DeliverWebhooksParams{
+		Limit: 100,
+		Lease: time.Minute,
+		Deliver: func(ctx context.Context, delivery ClaimWebhookDeliveriesRow) (int32, error) {
+			if delivery.WebhookID != webhook.ID {
+				return 200, nil
+			}
+			require.Equal(t, webhook.Secret, delivery.Secret)
+			return 500, errors.New("boom")
+		},
+		MaxAttempts: 5,
+		RetryDelay:  func(attempts int32) time.Duration { return time.Minute },
+	})
+	require.NoError(t, err)
+
+	deliveries := listDeliveries(t, webhook)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, WebhookStatusPending, deliveries[0].Status)
+	require.Equal(t, int32(1), deliveries[0].Attempts)
+	require.Equal(t, int32(500), deliveries[0].ResponseStatus)
+	require.Equal(t, "boom", deliveries[0].LastError)
+}
@@ -0,0 +1,103 @@
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event is a change pushed to connected clients.
+type Event struct {
+	ID   uint64      `json:"id"`
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+	// UserEmail selects the receiving user; empty means every user, which is
+	// what shared resources such as categories use.
+	UserEmail string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Broker fans events out to subscribers. Hub keeps everything in process; a
+// Postgres LISTEN/NOTIFY implementation can replace it to span instances.
+type Broker interface {
+	Publish(event Event)
+	// Subscribe returns the user's event channel and a function that must be
+	// called to stop receiving and release it.
+	Subscribe(userEmail string) (<-chan Event, func())
+}
+
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan Event]struct{}
+	buffer      int
+	lastID      uint64
+}
+
+func NewHub(buffer int) *Hub {
+	return &Hub{
+		subscribers: make(map[string]map[chan Event]struct{}),
+		buffer:      buffer,
+	}
+}
+
+func (hub *Hub) Publish(event Event) {
+	event.ID = atomic.AddUint64(&hub.lastID, 1)
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+
+	if event.UserEmail != "" {
+		hub.send(hub.subscribers[event.UserEmail], event)
+		return
+	}
+	for _, channels := range hub.subscribers {
+		hub.send(channels, event)
+	}
+}
+
+// send never blocks, a client that stopped reading misses events instead of
+// stalling the publisher.
+func (hub *Hub) send(channels map[chan Event]struct{}, event Event) {
+	for ch := range channels {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (hub *Hub) Subscribe(userEmail string) (<-chan Event, func()) {
+	ch := make(chan Event, hub.buffer)
+
+	hub.mu.Lock()
+	if hub.subscribers[userEmail] == nil {
+		hub.subscribers[userEmail] = make(map[chan Event]struct{})
+	}
+	hub.subscribers[userEmail][ch] = struct{}{}
+	hub.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			hub.mu.Lock()
+			delete(hub.subscribers[userEmail], ch)
+			if len(hub.subscribers[userEmail]) == 0 {
+				delete(hub.subscribers, userEmail)
+			}
+			hub.mu.Unlock()
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+// Subscribers returns how many streams userEmail has open.
+func (hub *Hub) Subscribers(userEmail string) int {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	return len(hub.subscribers[userEmail])
+}
@@ -0,0 +1,68 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHubPublishToUser(t *testing.T) {
+	hub := NewHub(4)
+
+	alice, unsubscribeAlice := hub.Subscribe("alice@email.com")
+	defer unsubscribeAlice()
+	bob, unsubscribeBob := hub.Subscribe("bob@email.com")
+	defer unsubscribeBob()
+
+	hub.Publish(Event{Type: "todo.created", UserEmail: "alice@email.com"})
+
+	event := <-alice
+	require.Equal(t, "todo.created", event.Type)
+	require.NotZero(t, event.ID)
+	require.False(t, event.CreatedAt.IsZero())
+	require.Empty(t, bob)
+}
+
+func TestHubBroadcast(t *testing.T) {
+	hub := NewHub(4)
+
+	alice, unsubscribeAlice := hub.Subscribe("alice@email.com")
+	defer unsubscribeAlice()
+	bob, unsubscribeBob := hub.Subscribe("bob@email.com")
+	defer unsubscribeBob()
+
+	hub.Publish(Event{Type: "category.created"})
+
+	require.Equal(t, "category.created", (<-alice).Type)
+	require.Equal(t, "category.created", (<-bob).Type)
+}
+
+func TestHubUnsubscribe(t *testing.T) {
+	hub := NewHub(4)
+
+	ch, unsubscribe := hub.Subscribe("alice@email.com")
+	require.Equal(t, 1, hub.Subscribers("alice@email.com"))
+
+	unsubscribe()
+	unsubscribe()
+	require.Equal(t, 0, hub.Subscribers("alice@email.com"))
+
+	_, ok := <-ch
+	require.False(t, ok)
+
+	// publishing without subscribers is a no-op
+	hub.Publish(Event{Type: "todo.created", UserEmail: "alice@email.com"})
+}
+
+func TestHubSlowSubscriber(t *testing.T) {
+	hub := NewHub(1)
+
+	ch, unsubscribe := hub.Subscribe("alice@email.com")
+	defer unsubscribe()
+
+	hub.Publish(Event{Type: "todo.created", UserEmail: "alice@email.com"})
+	hub.Publish(Event{Type: "todo.updated", UserEmail: "alice@email.com"})
+
+	require.Equal(t, "todo.created", (<-ch).Type)
+	require.Empty(t, ch)
+}
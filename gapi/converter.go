@@ -0,0 +1,117 @@
+package gapi
+
+import (
+	"encoding/json"
+
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func convertUser(user db.User) *pb.User {
+	return &pb.User{
+		Name:      user.Name,
+		Address:   user.Address,
+		Pic:       user.Pic,
+		Email:     user.Email,
+		TimeZone:  user.TimeZone,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+	}
+}
+
+func convertCategory(category db.Category) *pb.Category {
+	return &pb.Category{
+		Id:        category.ID,
+		Name:      category.Name,
+		Version:   category.Version,
+		CreatedAt: timestamppb.New(category.CreatedAt),
+		UpdatedAt: timestamppb.New(category.UpdatedAt),
+	}
+}
+
+func convertTags(tags []db.Tag) []*pb.Tag {
+	converted := make([]*pb.Tag, len(tags))
+	for i, tag := range tags {
+		converted[i] = &pb.Tag{Id: tag.ID, Name: tag.Name}
+	}
+	return converted
+}
+
+func convertTodo(todo db.Todo, tags []db.Tag) *pb.Todo {
+	return &pb.Todo{
+		Id:         todo.ID,
+		CategoryId: todo.CategoryID,
+		UserEmail:  todo.UserEmail,
+		Title:      todo.Title,
+		Content:    todo.Content,
+		Color:      todo.Color,
+		Date:       timestamppb.New(todo.Date),
+		AllDay:     todo.AllDay,
+		IsPriority: todo.IsPriority,
+		Status:     todo.Status,
+		Version:    todo.Version,
+		Tags:       convertTags(tags),
+		CreatedAt:  timestamppb.New(todo.CreatedAt),
+		UpdatedAt:  timestamppb.New(todo.UpdatedAt),
+	}
+}
+
+// convertTodoRow converts a row of the today, upcoming or done lists, which
+// all have the same columns.
+func convertTodoRow(row db.ListTodayTodoRow) (*pb.Todo, error) {
+	tags, err := decodeTags(row.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Todo{
+		Id:           row.ID,
+		CategoryId:   row.CategoryID,
+		CategoryName: row.CategoryName,
+		UserEmail:    row.UserEmail,
+		Title:        row.Title,
+		Content:      row.Content,
+		Color:        row.Color,
+		Date:         timestamppb.New(row.Date),
+		AllDay:       row.AllDay,
+		IsPriority:   row.IsPriority,
+		Status:       row.Status,
+		Tags:         convertTags(tags),
+		CreatedAt:    timestamppb.New(row.CreatedAt),
+		UpdatedAt:    timestamppb.New(row.UpdatedAt),
+	}, nil
+}
+
+func convertGetTodoRow(row db.GetTodoRow) (*pb.Todo, error) {
+	tags, err := decodeTags(row.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Todo{
+		Id:           row.ID,
+		CategoryId:   row.CategoryID,
+		CategoryName: row.CategoryName,
+		UserEmail:    row.UserEmail,
+		Title:        row.Title,
+		Content:      row.Content,
+		Color:        row.Color,
+		Date:         timestamppb.New(row.Date),
+		AllDay:       row.AllDay,
+		IsPriority:   row.IsPriority,
+		Version:      row.Version,
+		Tags:         convertTags(tags),
+		CreatedAt:    timestamppb.New(row.CreatedAt),
+		UpdatedAt:    timestamppb.New(row.UpdatedAt),
+	}, nil
+}
+
+// decodeTags reads the tags the todo queries aggregate as JSON.
+func decodeTags(raw json.RawMessage) ([]db.Tag, error) {
+	var tags []db.Tag
+	if len(raw) == 0 {
+		return tags, nil
+	}
+	err := json.Unmarshal(raw, &tags)
+	return tags, err
+}
@@ -0,0 +1,58 @@
+package gapi
+
+import (
+	"database/sql"
+	"log"
+
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// storeError turns an error from the store into a status, the way the HTTP
+// API picks its status codes. notFound is the message for a missing row.
+func storeError(err error, notFound string) error {
+	switch err {
+	case sql.ErrNoRows:
+		return status.Error(codes.NotFound, notFound)
+	case db.ErrVersionMismatch:
+		return status.Error(codes.Aborted, err.Error())
+	case db.ErrInvalidTags:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	switch db.ErrorCode(err) {
+	case db.UniqueViolation:
+		return status.Error(codes.AlreadyExists, "already-exists")
+	case db.ForeignKeyViolation:
+		return status.Error(codes.FailedPrecondition, "invalid-reference")
+	case db.NotNullViolation, db.CheckViolation:
+		return status.Error(codes.InvalidArgument, "constraint-violation")
+	case db.StringDataTruncation:
+		return status.Error(codes.InvalidArgument, "value-too-long")
+	case db.SerializationFailure, db.DeadlockDetected:
+		return status.Error(codes.Unavailable, "retry-request")
+	}
+
+	log.Println(err)
+	return status.Error(codes.Internal, "internal-error")
+}
+
+func fieldViolation(field, code string) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: code,
+	}
+}
+
+// invalidArgumentError lists every invalid field, like validation-failed
+// does over HTTP.
+func invalidArgumentError(violations []*errdetails.BadRequest_FieldViolation) error {
+	st, err := status.New(codes.InvalidArgument, "validation-failed").
+		WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "validation-failed")
+	}
+	return st.Err()
+}
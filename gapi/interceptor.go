@@ -0,0 +1,134 @@
+package gapi
+
+import (
+	"context"
+	"log"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maslow123/todoapp-services/ratelimit"
+	"github.com/maslow123/todoapp-services/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+	retryAfterHeaderKey     = "retry-after"
+)
+
+// publicMethods can be called without a token.
+var publicMethods = map[string]bool{
+	"/pb.TodoApp/CreateUser": true,
+	"/pb.TodoApp/LoginUser":  true,
+}
+
+type payloadKey struct{}
+
+func authInterceptor(tokenMaker token.Maker) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		payload, err := authorize(ctx, tokenMaker)
+		if err != nil {
+			return nil, err
+		}
+		return handler(context.WithValue(ctx, payloadKey{}, payload), req)
+	}
+}
+
+func authorize(ctx context.Context, tokenMaker token.Maker) (*token.Payload, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(authorizationHeaderKey)
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization-header-is-not-provided")
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) < 2 {
+		return nil, status.Error(codes.Unauthenticated, "invalid-authorization-header-format")
+	}
+
+	authorizationType := strings.ToLower(fields[0])
+	if authorizationType != authorizationTypeBearer {
+		return nil, status.Errorf(codes.Unauthenticated, "unsupported-authorization-type-%s", authorizationType)
+	}
+
+	payload, err := tokenMaker.VerifyToken(fields[1])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return payload, nil
+}
+
+// authPayload returns the payload authInterceptor put in the context.
+func authPayload(ctx context.Context) *token.Payload {
+	payload, _ := ctx.Value(payloadKey{}).(*token.Payload)
+	return payload
+}
+
+// rateLimitInterceptor limits calls to the methods in limiters per client
+// address, the same key the HTTP API limits by.
+func rateLimitInterceptor(limiters map[string]*ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		limiter := limiters[info.FullMethod]
+		if limiter == nil {
+			return handler(ctx, req)
+		}
+
+		if result := limiter.Allow(peerKey(ctx)); !result.Allowed {
+			return nil, tooManyRequests(ctx, result)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// userRateLimitInterceptor limits authenticated calls per user, keyed like
+// the HTTP API's per-user limit. It goes after authInterceptor.
+func userRateLimitInterceptor(limiter *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		payload := authPayload(ctx)
+		if limiter == nil || payload == nil {
+			return handler(ctx, req)
+		}
+
+		if result := limiter.Allow(payload.Username); !result.Allowed {
+			return nil, tooManyRequests(ctx, result)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func tooManyRequests(ctx context.Context, result ratelimit.Result) error {
+	retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+	grpc.SetHeader(ctx, metadata.Pairs(retryAfterHeaderKey, strconv.Itoa(retryAfter)))
+	return status.Error(codes.ResourceExhausted, "too-many-requests")
+}
+
+func peerKey(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func logInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	log.Printf("grpc %s %s %s", info.FullMethod, status.Code(err), time.Since(start))
+	return resp, err
+}
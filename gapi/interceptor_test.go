@@ -0,0 +1,184 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/events"
+	"github.com/maslow123/todoapp-services/pb"
+	"github.com/maslow123/todoapp-services/ratelimit"
+	"github.com/maslow123/todoapp-services/token"
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAuthInterceptor(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name       string
+		setupAuth  func(t *testing.T, tokenMaker token.Maker) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+		message    string
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return withToken(t, tokenMaker, user.Email)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListCategories(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListCategories(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			code:    codes.Unauthenticated,
+			message: "authorization-header-is-not-provided",
+		},
+		{
+			name: "InvalidFormat",
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, "token")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListCategories(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			code:    codes.Unauthenticated,
+			message: "invalid-authorization-header-format",
+		},
+		{
+			name: "UnsupportedType",
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				accessToken, err := tokenMaker.CreateToken(user.Email, time.Minute)
+				require.NoError(t, err)
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, "basic "+accessToken)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListCategories(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			code:    codes.Unauthenticated,
+			message: "unsupported-authorization-type-basic",
+		},
+		{
+			name: "ExpiredToken",
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				accessToken, err := tokenMaker.CreateToken(user.Email, -time.Minute)
+				require.NoError(t, err)
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, "bearer "+accessToken)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListCategories(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			code:    codes.Unauthenticated,
+			message: token.ErrExpiredToken.Error(),
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			client, tokenMaker := newTestClient(t, store)
+			_, err := client.ListCategories(tc.setupAuth(t, tokenMaker), &pb.ListCategoriesRequest{PageId: 1, PageSize: 5})
+			if tc.code == codes.OK {
+				require.NoError(t, err)
+				return
+			}
+			requireStatus(t, err, tc.code, tc.message)
+		})
+	}
+}
+
+func TestRateLimitInterceptor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.User{}, sql.ErrNoRows)
+
+	loginLimiter := ratelimit.New(1, time.Minute)
+	server := newTestServer(t, store, events.NewHub(8), ratelimit.New(1, time.Minute), loginLimiter, nil)
+	client := serveTestClient(t, server)
+
+	req := &pb.LoginUserRequest{Email: "user@email.com", Password: "secret"}
+	_, err := client.LoginUser(context.Background(), req)
+	requireStatus(t, err, codes.Unauthenticated, "invalid-password")
+
+	var header metadata.MD
+	_, err = client.LoginUser(context.Background(), req, grpc.Header(&header))
+	requireStatus(t, err, codes.ResourceExhausted, "too-many-requests")
+	require.Equal(t, []string{"60"}, header.Get(retryAfterHeaderKey))
+
+	// the HTTP API shares the limiter, so its logins count against the same
+	// client, and sign ups have a quota of their own
+	require.False(t, loginLimiter.Allow("bufconn").Allowed)
+	_, err = client.CreateUser(context.Background(), &pb.CreateUserRequest{})
+	requireStatus(t, err, codes.InvalidArgument, "validation-failed")
+}
+
+func TestUserRateLimitInterceptor(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListCategories(gomock.Any(), gomock.Any()).
+		Times(2).
+		Return(nil, nil)
+
+	userLimiter := ratelimit.New(1, time.Minute)
+	server := newTestServer(t, store, events.NewHub(8), nil, nil, userLimiter)
+	client := serveTestClient(t, server)
+
+	req := &pb.ListCategoriesRequest{PageId: 1, PageSize: 5}
+	_, err := client.ListCategories(withToken(t, server.tokenMaker, user.Email), req)
+	require.NoError(t, err)
+
+	var header metadata.MD
+	_, err = client.ListCategories(withToken(t, server.tokenMaker, user.Email), req, grpc.Header(&header))
+	requireStatus(t, err, codes.ResourceExhausted, "too-many-requests")
+	require.Equal(t, []string{"60"}, header.Get(retryAfterHeaderKey))
+
+	// each user has a quota of their own, shared with the HTTP API
+	_, err = client.ListCategories(withToken(t, server.tokenMaker, util.RandomEmail()), req)
+	require.NoError(t, err)
+	require.False(t, userLimiter.Allow(user.Email).Allowed)
+}
@@ -0,0 +1,112 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/events"
+	"github.com/maslow123/todoapp-services/pb"
+	"github.com/maslow123/todoapp-services/ratelimit"
+	"github.com/maslow123/todoapp-services/token"
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves a Server over an in-memory connection and returns a
+// client for it.
+func newTestClient(t *testing.T, store db.Store) (pb.TodoAppClient, token.Maker) {
+	server := newTestServer(t, store, events.NewHub(8), nil, nil, nil)
+	return serveTestClient(t, server), server.tokenMaker
+}
+
+func newTestServer(t *testing.T, store db.Store, broker events.Broker, registerLimiter, loginLimiter, userLimiter *ratelimit.Limiter) *Server {
+	tokenMaker, err := token.NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	config := util.Config{
+		AccessTokenDuration: time.Minute,
+	}
+	return NewServer(config, store, tokenMaker, broker, registerLimiter, loginLimiter, userLimiter)
+}
+
+func serveTestClient(t *testing.T, server *Server) pb.TodoAppClient {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := server.newGRPCServer()
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithInsecure(),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewTodoAppClient(conn)
+}
+
+func withToken(t *testing.T, tokenMaker token.Maker, email string) context.Context {
+	accessToken, err := tokenMaker.CreateToken(email, time.Minute)
+	require.NoError(t, err)
+
+	header := fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken)
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, header)
+}
+
+func requireStatus(t *testing.T, err error, code codes.Code, message string) {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, code, st.Code())
+	require.Equal(t, message, st.Message())
+}
+
+func requireFieldViolations(t *testing.T, err error, fields ...string) {
+	requireStatus(t, err, codes.InvalidArgument, "validation-failed")
+
+	var got []string
+	for _, detail := range status.Convert(err).Details() {
+		badRequest, ok := detail.(*errdetails.BadRequest)
+		require.True(t, ok)
+		for _, violation := range badRequest.GetFieldViolations() {
+			got = append(got, violation.GetField())
+		}
+	}
+	require.ElementsMatch(t, fields, got)
+}
+
+func randomUser(t *testing.T) (user db.User, password string) {
+	password = util.RandomString(6)
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+
+	user = db.User{
+		Name:           util.RandomString(6),
+		HashedPassword: hashedPassword,
+		Address:        util.RandomString(100),
+		Pic:            "omama.jpg",
+		Email:          util.RandomEmail(),
+		TimeZone:       util.DefaultTimeZone,
+	}
+
+	return user, password
+}
+
+func randomCategory() db.Category {
+	return db.Category{
+		ID:      int32(util.RandomInt(1, 1000)),
+		Name:    util.RandomString(6),
+		Version: 1,
+	}
+}
@@ -0,0 +1,117 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateCategory(ctx context.Context, req *pb.CreateCategoryRequest) (*pb.CreateCategoryResponse, error) {
+	var v validator
+	v.required("name", req.GetName())
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	arg := db.CreateCategoryTxParams{
+		Name:      req.GetName(),
+		UserEmail: authPayload(ctx).Username,
+	}
+
+	category, err := server.store.CreateCategoryTx(ctx, arg)
+	if err != nil {
+		return nil, storeError(err, "category-not-found")
+	}
+
+	server.publish("", db.EventCategoryCreated, category)
+	return &pb.CreateCategoryResponse{Category: convertCategory(category)}, nil
+}
+
+func (server *Server) ListCategories(ctx context.Context, req *pb.ListCategoriesRequest) (*pb.ListCategoriesResponse, error) {
+	var v validator
+	v.min("page_id", req.GetPageId(), 1)
+	v.min("page_size", req.GetPageSize(), 5)
+	v.max("page_size", req.GetPageSize(), 10)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	arg := db.ListCategoriesParams{
+		Limit:  req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	}
+
+	categories, err := server.store.ListCategories(ctx, arg)
+	if err != nil {
+		return nil, storeError(err, "category-not-found")
+	}
+
+	resp := &pb.ListCategoriesResponse{Categories: make([]*pb.Category, len(categories))}
+	for i, category := range categories {
+		resp.Categories[i] = convertCategory(category)
+	}
+	return resp, nil
+}
+
+func (server *Server) UpdateCategory(ctx context.Context, req *pb.UpdateCategoryRequest) (*pb.UpdateCategoryResponse, error) {
+	var v validator
+	v.min("category_id", req.GetCategoryId(), 1)
+	v.required("name", req.GetName())
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	arg := db.UpdateCategoryTxParams{
+		UpdateCategoryParams: db.UpdateCategoryParams{
+			ID:   req.GetCategoryId(),
+			Name: req.GetName(),
+		},
+		UserEmail: authPayload(ctx).Username,
+		Version:   req.GetVersion(),
+	}
+
+	category, err := server.store.UpdateCategoryTx(ctx, arg)
+	if err != nil {
+		return nil, storeError(err, "category-not-found")
+	}
+
+	server.publish("", db.EventCategoryUpdated, category)
+	return &pb.UpdateCategoryResponse{Category: convertCategory(category)}, nil
+}
+
+func (server *Server) DeleteCategory(ctx context.Context, req *pb.DeleteCategoryRequest) (*pb.DeleteCategoryResponse, error) {
+	var v validator
+	v.min("category_id", req.GetCategoryId(), 1)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	arg := db.DeleteCategoryTxParams{
+		CategoryID: req.GetCategoryId(),
+		MoveTodos:  req.GetMoveTodos(),
+		UserEmail:  authPayload(ctx).Username,
+		Version:    req.GetVersion(),
+	}
+
+	result, err := server.store.DeleteCategoryTx(ctx, arg)
+	if err != nil {
+		var notEmptyErr *db.CategoryNotEmptyError
+		switch {
+		case errors.As(err, &notEmptyErr):
+			return nil, status.Error(codes.FailedPrecondition, "category-has-todos")
+		case err == db.ErrDefaultCategory:
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, storeError(err, "category-not-found")
+	}
+
+	server.publish("", db.EventCategoryDeleted, map[string]interface{}{
+		"id":          arg.CategoryID,
+		"moved_todos": result.MovedTodos,
+	})
+	return &pb.DeleteCategoryResponse{MovedTodos: result.MovedTodos}, nil
+}
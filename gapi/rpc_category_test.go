@@ -0,0 +1,230 @@
+package gapi
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/events"
+	"github.com/maslow123/todoapp-services/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestCreateCategoryRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	category := randomCategory()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateCategoryTx(gomock.Any(), gomock.Eq(db.CreateCategoryTxParams{Name: category.Name, UserEmail: user.Email})).
+		Times(1).
+		Return(category, nil)
+
+	hub := events.NewHub(8)
+	stream, unsubscribe := hub.Subscribe(user.Email)
+	defer unsubscribe()
+
+	server := newTestServer(t, store, hub, nil, nil, nil)
+	client, tokenMaker := serveTestClient(t, server), server.tokenMaker
+	res, err := client.CreateCategory(withToken(t, tokenMaker, user.Email), &pb.CreateCategoryRequest{Name: category.Name})
+	require.NoError(t, err)
+	require.Equal(t, category.ID, res.GetCategory().GetId())
+	require.Equal(t, category.Name, res.GetCategory().GetName())
+
+	// clients on the event stream hear about changes made over gRPC too
+	event := <-stream
+	require.Equal(t, db.EventCategoryCreated, event.Type)
+	require.Equal(t, category, event.Data)
+
+	_, err = client.CreateCategory(withToken(t, tokenMaker, user.Email), &pb.CreateCategoryRequest{})
+	requireFieldViolations(t, err, "name")
+}
+
+func TestUpdateCategoryRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	category := randomCategory()
+
+	testCases := []struct {
+		name       string
+		req        *pb.UpdateCategoryRequest
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+		message    string
+	}{
+		{
+			name: "OK",
+			req:  &pb.UpdateCategoryRequest{CategoryId: category.ID, Name: category.Name, Version: category.Version},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateCategoryTxParams{
+					UpdateCategoryParams: db.UpdateCategoryParams{ID: category.ID, Name: category.Name},
+					UserEmail:            user.Email,
+					Version:              category.Version,
+				}
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(category, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "NotFound",
+			req:  &pb.UpdateCategoryRequest{CategoryId: category.ID, Name: category.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Category{}, sql.ErrNoRows)
+			},
+			code:    codes.NotFound,
+			message: "category-not-found",
+		},
+		{
+			name: "VersionMismatch",
+			req:  &pb.UpdateCategoryRequest{CategoryId: category.ID, Name: category.Name, Version: 7},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Category{}, db.ErrVersionMismatch)
+			},
+			code:    codes.Aborted,
+			message: "version-mismatch",
+		},
+		{
+			name: "InvalidID",
+			req:  &pb.UpdateCategoryRequest{Name: category.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			code:    codes.InvalidArgument,
+			message: "validation-failed",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			client, tokenMaker := newTestClient(t, store)
+			res, err := client.UpdateCategory(withToken(t, tokenMaker, user.Email), tc.req)
+			if tc.code == codes.OK {
+				require.NoError(t, err)
+				require.Equal(t, category.ID, res.GetCategory().GetId())
+				return
+			}
+			requireStatus(t, err, tc.code, tc.message)
+		})
+	}
+}
+
+func TestDeleteCategoryRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	category := randomCategory()
+
+	testCases := []struct {
+		name       string
+		req        *pb.DeleteCategoryRequest
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+		message    string
+	}{
+		{
+			name: "OK",
+			req:  &pb.DeleteCategoryRequest{CategoryId: category.ID, MoveTodos: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.DeleteCategoryTxParams{
+					CategoryID: category.ID,
+					MoveTodos:  true,
+					UserEmail:  user.Email,
+				}
+				store.EXPECT().
+					DeleteCategoryTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.DeleteCategoryTxResult{MovedTodos: 3}, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "HasTodos",
+			req:  &pb.DeleteCategoryRequest{CategoryId: category.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteCategoryTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DeleteCategoryTxResult{}, &db.CategoryNotEmptyError{TodoCount: 2})
+			},
+			code:    codes.FailedPrecondition,
+			message: "category-has-todos",
+		},
+		{
+			name: "DefaultCategory",
+			req:  &pb.DeleteCategoryRequest{CategoryId: category.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteCategoryTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DeleteCategoryTxResult{}, db.ErrDefaultCategory)
+			},
+			code:    codes.FailedPrecondition,
+			message: db.ErrDefaultCategory.Error(),
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			client, tokenMaker := newTestClient(t, store)
+			res, err := client.DeleteCategory(withToken(t, tokenMaker, user.Email), tc.req)
+			if tc.code == codes.OK {
+				require.NoError(t, err)
+				require.Equal(t, int64(3), res.GetMovedTodos())
+				return
+			}
+			requireStatus(t, err, tc.code, tc.message)
+		})
+	}
+}
+
+func TestListCategoriesRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	categories := []db.Category{randomCategory(), randomCategory()}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListCategories(gomock.Any(), gomock.Eq(db.ListCategoriesParams{Limit: 5, Offset: 5})).
+		Times(1).
+		Return(categories, nil)
+
+	client, tokenMaker := newTestClient(t, store)
+	res, err := client.ListCategories(withToken(t, tokenMaker, user.Email), &pb.ListCategoriesRequest{PageId: 2, PageSize: 5})
+	require.NoError(t, err)
+	require.Len(t, res.GetCategories(), 2)
+
+	_, err = client.ListCategories(withToken(t, tokenMaker, user.Email), &pb.ListCategoriesRequest{PageId: 0, PageSize: 20})
+	requireFieldViolations(t, err, "page_id", "page_size")
+}
@@ -0,0 +1,269 @@
+package gapi
+
+import (
+	"context"
+	"time"
+
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/pb"
+	"github.com/maslow123/todoapp-services/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateTodo(ctx context.Context, req *pb.CreateTodoRequest) (*pb.CreateTodoResponse, error) {
+	var v validator
+	v.min("category_id", req.GetCategoryId(), 1)
+	v.required("title", req.GetTitle())
+	v.required("content", req.GetContent())
+	v.required("date", req.GetDate())
+	v.required("color", req.GetColor())
+	v.ids("tag_ids", req.GetTagIds())
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	payload := authPayload(ctx)
+	date, allDay, err := server.parseDueDate(ctx, payload.Username, req.GetDate())
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = server.store.GetCategory(ctx, req.GetCategoryId())
+	if err != nil {
+		return nil, storeError(err, "invalid-category")
+	}
+
+	arg := db.CreateTodoTxParams{
+		CreateTodoParams: db.CreateTodoParams{
+			UserEmail:  payload.Username,
+			CategoryID: req.GetCategoryId(),
+			Title:      req.GetTitle(),
+			Content:    req.GetContent(),
+			Date:       date,
+			AllDay:     allDay,
+			Color:      req.GetColor(),
+			IsPriority: req.GetIsPriority(),
+		},
+		TagIDs: req.GetTagIds(),
+	}
+
+	result, err := server.store.CreateTodoTx(ctx, arg)
+	if err != nil {
+		return nil, storeError(err, "todo-not-found")
+	}
+
+	server.publish(payload.Username, db.EventTodoCreated, todoEvent{Todo: result.Todo, Tags: result.Tags})
+	return &pb.CreateTodoResponse{Todo: convertTodo(result.Todo, result.Tags)}, nil
+}
+
+func (server *Server) GetTodo(ctx context.Context, req *pb.GetTodoRequest) (*pb.GetTodoResponse, error) {
+	row, err := server.ownTodo(ctx, req.GetTodoId(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := convertGetTodoRow(row)
+	if err != nil {
+		return nil, storeError(err, "todo-not-found")
+	}
+	return &pb.GetTodoResponse{Todo: todo}, nil
+}
+
+func (server *Server) ListTodos(ctx context.Context, req *pb.ListTodosRequest) (*pb.ListTodosResponse, error) {
+	var v validator
+	v.min("page_id", req.GetPageId(), 1)
+	v.min("page_size", req.GetPageSize(), 5)
+	v.max("page_size", req.GetPageSize(), 100)
+	if req.GetTagId() != 0 {
+		v.min("tag_id", req.GetTagId(), 1)
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	email := authPayload(ctx).Username
+	limit := req.GetPageSize()
+	offset := (req.GetPageId() - 1) * req.GetPageSize()
+	resp := &pb.ListTodosResponse{}
+
+	today, err := server.store.ListTodayTodo(ctx, db.ListTodayTodoParams{
+		UserEmail: email,
+		Limit:     limit,
+		Offset:    offset,
+		TagID:     req.GetTagId(),
+	})
+	if err != nil {
+		return nil, storeError(err, "todo-not-found")
+	}
+	for _, row := range today {
+		if resp.Today, err = appendTodoRow(resp.Today, row); err != nil {
+			return nil, err
+		}
+	}
+
+	upcoming, err := server.store.ListUpcomingTodo(ctx, db.ListUpcomingTodoParams{
+		UserEmail: email,
+		Limit:     limit,
+		Offset:    offset,
+		TagID:     req.GetTagId(),
+	})
+	if err != nil {
+		return nil, storeError(err, "todo-not-found")
+	}
+	for _, row := range upcoming {
+		if resp.Upcoming, err = appendTodoRow(resp.Upcoming, db.ListTodayTodoRow(row)); err != nil {
+			return nil, err
+		}
+	}
+
+	done, err := server.store.ListDoneTodo(ctx, db.ListDoneTodoParams{
+		UserEmail: email,
+		Limit:     limit,
+		Offset:    offset,
+		TagID:     req.GetTagId(),
+	})
+	if err != nil {
+		return nil, storeError(err, "todo-not-found")
+	}
+	for _, row := range done {
+		if resp.Done, err = appendTodoRow(resp.Done, db.ListTodayTodoRow(row)); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+func appendTodoRow(todos []*pb.Todo, row db.ListTodayTodoRow) ([]*pb.Todo, error) {
+	todo, err := convertTodoRow(row)
+	if err != nil {
+		return nil, storeError(err, "todo-not-found")
+	}
+	return append(todos, todo), nil
+}
+
+func (server *Server) UpdateTodo(ctx context.Context, req *pb.UpdateTodoRequest) (*pb.UpdateTodoResponse, error) {
+	var v validator
+	v.min("todo_id", req.GetTodoId(), 1)
+	v.min("category_id", req.GetCategoryId(), 1)
+	v.required("title", req.GetTitle())
+	v.required("content", req.GetContent())
+	v.required("date", req.GetDate())
+	v.required("color", req.GetColor())
+	v.ids("tag_ids", req.GetTagIds())
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	date, allDay, err := server.parseDueDate(ctx, authPayload(ctx).Username, req.GetDate())
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := server.ownTodo(ctx, req.GetTodoId(), req.GetVersion()); err != nil {
+		return nil, err
+	}
+
+	arg := db.UpdateTodoTxParams{
+		UpdateTodoByUserParams: db.UpdateTodoByUserParams{
+			ID:         req.GetTodoId(),
+			CategoryID: req.GetCategoryId(),
+			Title:      req.GetTitle(),
+			Content:    req.GetContent(),
+			Date:       date,
+			AllDay:     allDay,
+			Color:      req.GetColor(),
+			IsPriority: req.GetIsPriority(),
+		},
+		TagIDs:  req.GetTagIds(),
+		Version: req.GetVersion(),
+	}
+
+	result, err := server.store.UpdateTodoTx(ctx, arg)
+	if err != nil {
+		return nil, storeError(err, "todo-not-found")
+	}
+
+	server.publish(result.Todo.UserEmail, db.EventTodoUpdated, todoEvent{Todo: result.Todo, Tags: result.Tags})
+	return &pb.UpdateTodoResponse{Todo: convertTodo(result.Todo, result.Tags)}, nil
+}
+
+func (server *Server) CompleteTodo(ctx context.Context, req *pb.CompleteTodoRequest) (*pb.CompleteTodoResponse, error) {
+	if _, err := server.ownTodo(ctx, req.GetTodoId(), req.GetVersion()); err != nil {
+		return nil, err
+	}
+
+	arg := db.CompleteTodoTxParams{
+		ID:      req.GetTodoId(),
+		Version: req.GetVersion(),
+	}
+	todo, err := server.store.CompleteTodoTx(ctx, arg)
+	if err != nil {
+		return nil, storeError(err, "todo-not-found")
+	}
+
+	server.publish(todo.UserEmail, db.EventTodoCompleted, todo)
+	return &pb.CompleteTodoResponse{Todo: convertTodo(todo, nil)}, nil
+}
+
+func (server *Server) DeleteTodo(ctx context.Context, req *pb.DeleteTodoRequest) (*pb.DeleteTodoResponse, error) {
+	todo, err := server.ownTodo(ctx, req.GetTodoId(), req.GetVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	arg := db.DeleteTodoTxParams{
+		ID:        todo.ID,
+		UserEmail: todo.UserEmail,
+		Version:   req.GetVersion(),
+	}
+	if err := server.store.DeleteTodoTx(ctx, arg); err != nil {
+		return nil, storeError(err, "todo-not-found")
+	}
+
+	server.publish(arg.UserEmail, db.EventTodoDeleted, arg)
+	return &pb.DeleteTodoResponse{}, nil
+}
+
+// ownTodo loads a todo of the calling user. A non-zero version must match the
+// todo's current one.
+func (server *Server) ownTodo(ctx context.Context, todoID, version int32) (db.GetTodoRow, error) {
+	var v validator
+	v.min("todo_id", todoID, 1)
+	if err := v.err(); err != nil {
+		return db.GetTodoRow{}, err
+	}
+
+	todo, err := server.store.GetTodo(ctx, todoID)
+	if err != nil {
+		return db.GetTodoRow{}, storeError(err, "todo-not-found")
+	}
+	if todo.UserEmail != authPayload(ctx).Username {
+		return db.GetTodoRow{}, status.Error(codes.PermissionDenied, "wrong-user")
+	}
+	if version != 0 && todo.Version != version {
+		return db.GetTodoRow{}, status.Error(codes.Aborted, db.ErrVersionMismatch.Error())
+	}
+	return todo, nil
+}
+
+// parseDueDate reads a todo date in the user's time zone.
+func (server *Server) parseDueDate(ctx context.Context, email, value string) (time.Time, bool, error) {
+	user, err := server.store.GetUser(ctx, email)
+	if err != nil {
+		return time.Time{}, false, storeError(err, "invalid-user")
+	}
+
+	loc, err := util.LoadTimeZone(user.TimeZone)
+	if err != nil {
+		// a zone that is no longer known should not block the user
+		loc = time.UTC
+	}
+
+	date, allDay, err := util.ParseDueDate(value, loc)
+	if err != nil {
+		return time.Time{}, false, status.Error(codes.InvalidArgument, "invalid-date")
+	}
+	return date, allDay, nil
+}
@@ -0,0 +1,470 @@
+package gapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/events"
+	"github.com/maslow123/todoapp-services/pb"
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func randomTodoRow(email string) db.GetTodoRow {
+	category := randomCategory()
+	return db.GetTodoRow{
+		ID:           int32(util.RandomInt(1, 1000)),
+		CategoryID:   category.ID,
+		CategoryName: category.Name,
+		UserEmail:    email,
+		Title:        util.RandomString(10),
+		Content:      util.RandomString(30),
+		Date:         time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		Color:        util.RandomColor(),
+		Version:      2,
+		Tags:         json.RawMessage(`[{"id":1,"name":"work"}]`),
+	}
+}
+
+func TestCreateTodoRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	user.TimeZone = "Asia/Jakarta"
+	category := randomCategory()
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateTodoRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.CreateTodoResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.CreateTodoRequest{
+				CategoryId: category.ID,
+				Title:      "title",
+				Content:    "content",
+				Date:       "2022-03-01",
+				Color:      "#fff",
+				IsPriority: true,
+				TagIds:     []int32{1},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				loc, err := time.LoadLocation(user.TimeZone)
+				require.NoError(t, err)
+
+				arg := db.CreateTodoTxParams{
+					CreateTodoParams: db.CreateTodoParams{
+						UserEmail:  user.Email,
+						CategoryID: category.ID,
+						Title:      "title",
+						Content:    "content",
+						Date:       time.Date(2022, 3, 1, 0, 0, 0, 0, loc),
+						AllDay:     true,
+						Color:      "#fff",
+						IsPriority: true,
+					},
+					TagIDs: []int32{1},
+				}
+
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Email)).Times(1).Return(user, nil)
+				store.EXPECT().GetCategory(gomock.Any(), gomock.Eq(category.ID)).Times(1).Return(category, nil)
+				store.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TodoTxResult{
+						Todo: db.Todo{ID: 9, CategoryID: category.ID, Title: "title", UserEmail: user.Email, AllDay: true},
+						Tags: []db.Tag{{ID: 1, Name: "work"}},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTodoResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int32(9), res.GetTodo().GetId())
+				require.True(t, res.GetTodo().GetAllDay())
+				require.Equal(t, "work", res.GetTodo().GetTags()[0].GetName())
+			},
+		},
+		{
+			name: "InvalidDate",
+			req: &pb.CreateTodoRequest{
+				CategoryId: category.ID,
+				Title:      "title",
+				Content:    "content",
+				Date:       "tomorrow",
+				Color:      "#fff",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().CreateTodoTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTodoResponse, err error) {
+				requireStatus(t, err, codes.InvalidArgument, "invalid-date")
+			},
+		},
+		{
+			name: "InvalidCategory",
+			req: &pb.CreateTodoRequest{
+				CategoryId: category.ID,
+				Title:      "title",
+				Content:    "content",
+				Date:       "2022-03-01",
+				Color:      "#fff",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().GetCategory(gomock.Any(), gomock.Any()).Times(1).Return(db.Category{}, sql.ErrNoRows)
+				store.EXPECT().CreateTodoTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTodoResponse, err error) {
+				requireStatus(t, err, codes.NotFound, "invalid-category")
+			},
+		},
+		{
+			name: "InvalidArguments",
+			req:  &pb.CreateTodoRequest{Title: "title", TagIds: []int32{0}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTodoTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTodoResponse, err error) {
+				requireFieldViolations(t, err, "category_id", "content", "date", "color", "tag_ids")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			client, tokenMaker := newTestClient(t, store)
+			res, err := client.CreateTodo(withToken(t, tokenMaker, user.Email), tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestGetTodoRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	todo := randomTodoRow(user.Email)
+
+	testCases := []struct {
+		name          string
+		email         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.GetTodoResponse, err error)
+	}{
+		{
+			name:  "OK",
+			email: user.Email,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTodo(gomock.Any(), gomock.Eq(todo.ID)).Times(1).Return(todo, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetTodoResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, todo.ID, res.GetTodo().GetId())
+				require.Equal(t, todo.CategoryName, res.GetTodo().GetCategoryName())
+				require.Equal(t, todo.Version, res.GetTodo().GetVersion())
+				require.True(t, todo.Date.Equal(res.GetTodo().GetDate().AsTime()))
+				require.Len(t, res.GetTodo().GetTags(), 1)
+			},
+		},
+		{
+			name:  "NotFound",
+			email: user.Email,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTodo(gomock.Any(), gomock.Any()).Times(1).Return(db.GetTodoRow{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetTodoResponse, err error) {
+				requireStatus(t, err, codes.NotFound, "todo-not-found")
+			},
+		},
+		{
+			name:  "WrongUser",
+			email: util.RandomEmail(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTodo(gomock.Any(), gomock.Any()).Times(1).Return(todo, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetTodoResponse, err error) {
+				requireStatus(t, err, codes.PermissionDenied, "wrong-user")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			client, tokenMaker := newTestClient(t, store)
+			res, err := client.GetTodo(withToken(t, tokenMaker, tc.email), &pb.GetTodoRequest{TodoId: todo.ID})
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestListTodosRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	row := db.ListTodayTodoRow{
+		ID:           1,
+		UserEmail:    user.Email,
+		Title:        util.RandomString(10),
+		CategoryName: "Default",
+		Tags:         json.RawMessage(`[]`),
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListTodayTodo(gomock.Any(), gomock.Eq(db.ListTodayTodoParams{UserEmail: user.Email, Limit: 5, Offset: 0, TagID: 3})).
+		Times(1).
+		Return([]db.ListTodayTodoRow{row}, nil)
+	store.EXPECT().
+		ListUpcomingTodo(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.ListUpcomingTodoRow{db.ListUpcomingTodoRow(row), db.ListUpcomingTodoRow(row)}, nil)
+	store.EXPECT().
+		ListDoneTodo(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil, nil)
+
+	client, tokenMaker := newTestClient(t, store)
+	res, err := client.ListTodos(withToken(t, tokenMaker, user.Email), &pb.ListTodosRequest{PageId: 1, PageSize: 5, TagId: 3})
+	require.NoError(t, err)
+	require.Len(t, res.GetToday(), 1)
+	require.Len(t, res.GetUpcoming(), 2)
+	require.Empty(t, res.GetDone())
+	require.Equal(t, "Default", res.GetToday()[0].GetCategoryName())
+}
+
+func TestUpdateTodoRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	user.TimeZone = "Asia/Jakarta"
+	todo := randomTodoRow(user.Email)
+
+	req := &pb.UpdateTodoRequest{
+		TodoId:     todo.ID,
+		CategoryId: todo.CategoryID,
+		Title:      "title",
+		Content:    "content",
+		Date:       "2022-03-01",
+		Color:      "#fff",
+		TagIds:     []int32{1},
+	}
+
+	testCases := []struct {
+		name       string
+		version    int32
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+		message    string
+	}{
+		{
+			name:    "OK",
+			version: todo.Version,
+			buildStubs: func(store *mockdb.MockStore) {
+				loc, err := time.LoadLocation(user.TimeZone)
+				require.NoError(t, err)
+
+				arg := db.UpdateTodoTxParams{
+					UpdateTodoByUserParams: db.UpdateTodoByUserParams{
+						ID:         todo.ID,
+						CategoryID: todo.CategoryID,
+						Title:      "title",
+						Content:    "content",
+						Date:       time.Date(2022, 3, 1, 0, 0, 0, 0, loc),
+						AllDay:     true,
+						Color:      "#fff",
+					},
+					TagIDs:  []int32{1},
+					Version: todo.Version,
+				}
+
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Email)).Times(1).Return(user, nil)
+				store.EXPECT().GetTodo(gomock.Any(), gomock.Eq(todo.ID)).Times(1).Return(todo, nil)
+				store.EXPECT().
+					UpdateTodoTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TodoTxResult{
+						Todo: db.Todo{ID: todo.ID, Title: "title", UserEmail: user.Email, Version: todo.Version + 1},
+						Tags: []db.Tag{{ID: 1, Name: "work"}},
+					}, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name:    "WrongUser",
+			version: todo.Version,
+			buildStubs: func(store *mockdb.MockStore) {
+				other := todo
+				other.UserEmail = util.RandomEmail()
+
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().GetTodo(gomock.Any(), gomock.Eq(todo.ID)).Times(1).Return(other, nil)
+				store.EXPECT().UpdateTodoTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code:    codes.PermissionDenied,
+			message: "wrong-user",
+		},
+		{
+			name:    "NotFound",
+			version: todo.Version,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().GetTodo(gomock.Any(), gomock.Eq(todo.ID)).Times(1).Return(db.GetTodoRow{}, sql.ErrNoRows)
+				store.EXPECT().UpdateTodoTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code:    codes.NotFound,
+			message: "todo-not-found",
+		},
+		{
+			name:    "VersionMismatch",
+			version: todo.Version - 1,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().GetTodo(gomock.Any(), gomock.Eq(todo.ID)).Times(1).Return(todo, nil)
+				store.EXPECT().UpdateTodoTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code:    codes.Aborted,
+			message: "version-mismatch",
+		},
+		{
+			name:    "ChangedConcurrently",
+			version: todo.Version,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().GetTodo(gomock.Any(), gomock.Eq(todo.ID)).Times(1).Return(todo, nil)
+				store.EXPECT().
+					UpdateTodoTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TodoTxResult{}, db.ErrVersionMismatch)
+			},
+			code:    codes.Aborted,
+			message: "version-mismatch",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			client, tokenMaker := newTestClient(t, store)
+			req.Version = tc.version
+			res, err := client.UpdateTodo(withToken(t, tokenMaker, user.Email), req)
+			if tc.code == codes.OK {
+				require.NoError(t, err)
+				require.Equal(t, "title", res.GetTodo().GetTitle())
+				require.Equal(t, "work", res.GetTodo().GetTags()[0].GetName())
+				return
+			}
+			requireStatus(t, err, tc.code, tc.message)
+		})
+	}
+}
+
+func TestCompleteTodoRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	todo := randomTodoRow(user.Email)
+
+	testCases := []struct {
+		name       string
+		version    int32
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+		message    string
+	}{
+		{
+			name:    "OK",
+			version: todo.Version,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTodo(gomock.Any(), gomock.Eq(todo.ID)).Times(1).Return(todo, nil)
+				store.EXPECT().
+					CompleteTodoTx(gomock.Any(), gomock.Eq(db.CompleteTodoTxParams{ID: todo.ID, Version: todo.Version})).
+					Times(1).
+					Return(db.Todo{ID: todo.ID, Status: true, Version: todo.Version + 1}, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name:    "StaleVersion",
+			version: todo.Version - 1,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTodo(gomock.Any(), gomock.Any()).Times(1).Return(todo, nil)
+				store.EXPECT().CompleteTodoTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code:    codes.Aborted,
+			message: "version-mismatch",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			client, tokenMaker := newTestClient(t, store)
+			res, err := client.CompleteTodo(withToken(t, tokenMaker, user.Email), &pb.CompleteTodoRequest{TodoId: todo.ID, Version: tc.version})
+			if tc.code == codes.OK {
+				require.NoError(t, err)
+				require.True(t, res.GetTodo().GetStatus())
+				return
+			}
+			requireStatus(t, err, tc.code, tc.message)
+		})
+	}
+}
+
+func TestDeleteTodoRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	todo := randomTodoRow(user.Email)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetTodo(gomock.Any(), gomock.Eq(todo.ID)).Times(1).Return(todo, nil)
+	store.EXPECT().
+		DeleteTodoTx(gomock.Any(), gomock.Eq(db.DeleteTodoTxParams{ID: todo.ID, UserEmail: user.Email})).
+		Times(1).
+		Return(nil)
+
+	hub := events.NewHub(8)
+	stream, unsubscribe := hub.Subscribe(user.Email)
+	defer unsubscribe()
+
+	server := newTestServer(t, store, hub, nil, nil, nil)
+	client, tokenMaker := serveTestClient(t, server), server.tokenMaker
+	_, err := client.DeleteTodo(withToken(t, tokenMaker, user.Email), &pb.DeleteTodoRequest{TodoId: todo.ID})
+	require.NoError(t, err)
+
+	event := <-stream
+	require.Equal(t, db.EventTodoDeleted, event.Type)
+	require.Equal(t, db.DeleteTodoTxParams{ID: todo.ID, UserEmail: user.Email}, event.Data)
+}
@@ -0,0 +1,89 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/pb"
+	"github.com/maslow123/todoapp-services/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	var v validator
+	v.required("name", req.GetName())
+	v.required("address", req.GetAddress())
+	v.required("pic", req.GetPic())
+	v.required("password", req.GetPassword())
+	v.required("email", req.GetEmail())
+	v.timeZone("time_zone", req.GetTimeZone())
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	timeZone := req.GetTimeZone()
+	if timeZone == "" {
+		timeZone = util.DefaultTimeZone
+	}
+
+	hashedPassword, err := util.HashPassword(req.GetPassword())
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal-error")
+	}
+
+	arg := db.CreateUserParams{
+		Name:           req.GetName(),
+		Address:        req.GetAddress(),
+		Pic:            req.GetPic(),
+		HashedPassword: hashedPassword,
+		Email:          req.GetEmail(),
+		TimeZone:       timeZone,
+	}
+
+	user, err := server.store.CreateUser(ctx, arg)
+	if err != nil {
+		if db.ErrorCode(err) == db.UniqueViolation {
+			return nil, status.Error(codes.AlreadyExists, "email-already-exists")
+		}
+		return nil, storeError(err, "user-not-found")
+	}
+
+	return &pb.CreateUserResponse{User: convertUser(user)}, nil
+}
+
+func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	var v validator
+	v.required("email", req.GetEmail())
+	v.required("password", req.GetPassword())
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	user, err := server.store.GetUser(ctx, req.GetEmail())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.Unauthenticated, "invalid-password")
+		}
+		return nil, storeError(err, "user-not-found")
+	}
+
+	if err := util.CheckPassword(req.GetPassword(), user.HashedPassword); err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid-password")
+	}
+
+	expiresAt := time.Now().Add(server.config.AccessTokenDuration)
+	accessToken, err := server.tokenMaker.CreateToken(user.Email, server.config.AccessTokenDuration)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal-error")
+	}
+
+	return &pb.LoginUserResponse{
+		User:                 convertUser(user),
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: timestamppb.New(expiresAt),
+	}, nil
+}
@@ -0,0 +1,104 @@
+package gapi
+
+import (
+	"context"
+	"log"
+	"net"
+
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/events"
+	"github.com/maslow123/todoapp-services/pb"
+	"github.com/maslow123/todoapp-services/ratelimit"
+	"github.com/maslow123/todoapp-services/token"
+	"github.com/maslow123/todoapp-services/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server serves the gRPC API. It shares its store, token maker, event broker
+// and rate limits with the HTTP API, so a token from either one works on
+// both and changes made through either reach the same event streams.
+type Server struct {
+	pb.UnimplementedTodoAppServer
+	config     util.Config
+	store      db.Store
+	tokenMaker token.Maker
+	broker     events.Broker
+	// limiters holds the rate limiter of each public method, and
+	// userLimiter limits everything else per user.
+	limiters    map[string]*ratelimit.Limiter
+	userLimiter *ratelimit.Limiter
+	grpcServer  *grpc.Server
+}
+
+func NewServer(config util.Config, store db.Store, tokenMaker token.Maker, broker events.Broker, registerLimiter, loginLimiter, userLimiter *ratelimit.Limiter) *Server {
+	server := &Server{
+		config:     config,
+		store:      store,
+		tokenMaker: tokenMaker,
+		broker:     broker,
+		limiters: map[string]*ratelimit.Limiter{
+			"/pb.TodoApp/CreateUser": registerLimiter,
+			"/pb.TodoApp/LoginUser":  loginLimiter,
+		},
+		userLimiter: userLimiter,
+	}
+	server.grpcServer = server.newGRPCServer()
+	return server
+}
+
+func (server *Server) newGRPCServer() *grpc.Server {
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			logInterceptor,
+			rateLimitInterceptor(server.limiters),
+			authInterceptor(server.tokenMaker),
+			userRateLimitInterceptor(server.userLimiter),
+		),
+	)
+	pb.RegisterTodoAppServer(grpcServer, server)
+	reflection.Register(grpcServer)
+	return grpcServer
+}
+
+func (server *Server) Start(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	log.Println("grpc", listener.Addr())
+	return server.grpcServer.Serve(listener)
+}
+
+// Shutdown stops accepting connections and waits for in-flight calls. Calls
+// still running when ctx is done are cancelled.
+func (server *Server) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		server.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		server.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
+
+// todoEvent is a todo with its tags, the payload the HTTP API publishes too.
+type todoEvent struct {
+	db.Todo
+	Tags []db.Tag `json:"tags"`
+}
+
+func (server *Server) publish(userEmail, eventType string, data interface{}) {
+	server.broker.Publish(events.Event{
+		Type:      eventType,
+		UserEmail: userEmail,
+		Data:      data,
+	})
+}
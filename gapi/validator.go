@@ -0,0 +1,55 @@
+package gapi
+
+import (
+	"github.com/maslow123/todoapp-services/util"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// validator collects the field violations of a request. The codes match the
+// binding tags the HTTP API validates the same fields with.
+type validator struct {
+	violations []*errdetails.BadRequest_FieldViolation
+}
+
+func (v *validator) required(field, value string) {
+	if value == "" {
+		v.violations = append(v.violations, fieldViolation(field, "required"))
+	}
+}
+
+func (v *validator) min(field string, value, min int32) {
+	if value < min {
+		v.violations = append(v.violations, fieldViolation(field, "min"))
+	}
+}
+
+func (v *validator) max(field string, value, max int32) {
+	if value > max {
+		v.violations = append(v.violations, fieldViolation(field, "max"))
+	}
+}
+
+func (v *validator) ids(field string, values []int32) {
+	for _, value := range values {
+		if value < 1 {
+			v.violations = append(v.violations, fieldViolation(field, "min"))
+			return
+		}
+	}
+}
+
+func (v *validator) timeZone(field, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := util.LoadTimeZone(value); err != nil {
+		v.violations = append(v.violations, fieldViolation(field, err.Error()))
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return invalidArgumentError(v.violations)
+}
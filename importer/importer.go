@@ -0,0 +1,85 @@
+// Package importer reads the export files of other todo apps.
+package importer
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownSource = errors.New("unknown-source")
+	ErrInvalidFile   = errors.New("invalid-file")
+)
+
+// Item is a todo read from another app. Category and Tags are names, which
+// are created when they don't exist yet.
+type Item struct {
+	Title     string
+	Content   string
+	Category  string
+	Tags      []string
+	Due       time.Time
+	AllDay    bool
+	Priority  bool
+	Completed bool
+}
+
+// RowError reports an entry of the file that could not be imported. Index
+// counts the entries of the file, including skipped ones.
+type RowError struct {
+	Index int
+	Err   string
+}
+
+type Result struct {
+	Items  []Item
+	Errors []RowError
+}
+
+// Importer parses one export format. Dates without a zone are read in loc and
+// items without a due date are left with a zero Due.
+type Importer interface {
+	Import(r io.Reader, loc *time.Location) (Result, error)
+}
+
+var (
+	mu        sync.RWMutex
+	importers = map[string]Importer{
+		SourceTodoist:       Todoist{},
+		SourceTrello:        Trello{},
+		SourceMicrosoftTodo: MicrosoftTodo{},
+	}
+)
+
+// Register adds or replaces the importer of a source.
+func Register(source string, importer Importer) {
+	mu.Lock()
+	defer mu.Unlock()
+	importers[source] = importer
+}
+
+func Lookup(source string) (Importer, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	importer, ok := importers[source]
+	if !ok {
+		return nil, ErrUnknownSource
+	}
+	return importer, nil
+}
+
+func Sources() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	sources := make([]string, 0, len(importers))
+	for source := range importers {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	return sources
+}
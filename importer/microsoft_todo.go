@@ -0,0 +1,117 @@
+package importer
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+const SourceMicrosoftTodo = "microsoft-todo"
+
+// msDateTimeLayout is the Graph dateTimeTimeZone format, without an offset.
+const msDateTimeLayout = "2006-01-02T15:04:05.9999999"
+
+type msTodoList struct {
+	DisplayName string       `json:"displayName"`
+	Tasks       []msTodoTask `json:"tasks"`
+}
+
+type msTodoTask struct {
+	Title string `json:"title"`
+	Body  struct {
+		Content     string `json:"content"`
+		ContentType string `json:"contentType"`
+	} `json:"body"`
+	Importance  string   `json:"importance"`
+	Status      string   `json:"status"`
+	Categories  []string `json:"categories"`
+	DueDateTime *struct {
+		DateTime string `json:"dateTime"`
+		TimeZone string `json:"timeZone"`
+	} `json:"dueDateTime"`
+}
+
+// MicrosoftTodo reads Microsoft To Do and Outlook task lists in the Graph API
+// todoTaskList format, either as {"lists": [...]} or a Graph {"value": [...]}
+// response. Lists become categories and Outlook categories become tags.
+type MicrosoftTodo struct{}
+
+func (MicrosoftTodo) Import(r io.Reader, loc *time.Location) (Result, error) {
+	var export struct {
+		Lists []msTodoList `json:"lists"`
+		Value []msTodoList `json:"value"`
+	}
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return Result{}, ErrInvalidFile
+	}
+	lists := append(export.Lists, export.Value...)
+	if len(lists) == 0 {
+		return Result{}, ErrInvalidFile
+	}
+
+	var result Result
+	index := 0
+	for _, list := range lists {
+		for _, task := range list.Tasks {
+			item, err := newMicrosoftTodoItem(list.DisplayName, task, loc)
+			if err != "" {
+				result.Errors = append(result.Errors, RowError{Index: index, Err: err})
+			} else {
+				result.Items = append(result.Items, item)
+			}
+			index++
+		}
+	}
+
+	return result, nil
+}
+
+func newMicrosoftTodoItem(list string, task msTodoTask, loc *time.Location) (Item, string) {
+	title := strings.TrimSpace(task.Title)
+	if title == "" {
+		return Item{}, "missing-title"
+	}
+
+	item := Item{
+		Title:     title,
+		Category:  list,
+		Tags:      []string{},
+		Priority:  strings.EqualFold(task.Importance, "high"),
+		Completed: strings.EqualFold(task.Status, "completed"),
+	}
+	// html bodies would show up as markup in a todo
+	if !strings.EqualFold(task.Body.ContentType, "html") {
+		item.Content = strings.TrimSpace(task.Body.Content)
+	}
+	for _, category := range task.Categories {
+		if category = strings.TrimSpace(category); category != "" {
+			item.Tags = append(item.Tags, category)
+		}
+	}
+
+	if task.DueDateTime != nil && task.DueDateTime.DateTime != "" {
+		// Windows zone names such as "Pacific Standard Time" are not known here
+		taskLoc := loc
+		if name := task.DueDateTime.TimeZone; name != "" {
+			if tz, err := time.LoadLocation(name); err == nil {
+				taskLoc = tz
+			}
+		}
+
+		due, err := time.ParseInLocation(msDateTimeLayout, task.DueDateTime.DateTime, taskLoc)
+		if err != nil {
+			return Item{}, "invalid-date"
+		}
+
+		// To Do only has due dates, stored as midnight
+		if due.Hour() == 0 && due.Minute() == 0 && due.Second() == 0 {
+			item.Due = time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, loc)
+			item.AllDay = true
+		} else {
+			item.Due = due
+		}
+	}
+
+	return item, ""
+}
@@ -0,0 +1,57 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMicrosoftTodoImport(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	require.NoError(t, err)
+
+	export := `{
+		"value": [{
+			"displayName": "Errands",
+			"tasks": [
+				{"title": "Pay rent", "importance": "high", "status": "notStarted",
+				 "body": {"content": "before noon", "contentType": "text"},
+				 "categories": ["Money"],
+				 "dueDateTime": {"dateTime": "2020-01-01T00:00:00.0000000", "timeZone": "UTC"}},
+				{"title": "Call bank", "importance": "normal", "status": "completed",
+				 "body": {"content": "<p>hi</p>", "contentType": "html"},
+				 "dueDateTime": {"dateTime": "2020-01-02T09:30:00.0000000", "timeZone": "Pacific Standard Time"}},
+				{"title": "Broken", "dueDateTime": {"dateTime": "tomorrow", "timeZone": "UTC"}}
+			]
+		}]
+	}`
+
+	result, err := MicrosoftTodo{}.Import(strings.NewReader(export), jakarta)
+	require.NoError(t, err)
+	require.Len(t, result.Items, 2)
+
+	rent := result.Items[0]
+	require.Equal(t, "Errands", rent.Category)
+	require.Equal(t, "before noon", rent.Content)
+	require.Equal(t, []string{"Money"}, rent.Tags)
+	require.True(t, rent.Priority)
+	require.False(t, rent.Completed)
+	// a due date is kept as the same date in the user's zone
+	require.True(t, rent.AllDay)
+	require.True(t, rent.Due.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, jakarta)))
+
+	bank := result.Items[1]
+	require.True(t, bank.Completed)
+	require.Empty(t, bank.Content)
+	require.False(t, bank.AllDay)
+	require.True(t, bank.Due.Equal(time.Date(2020, 1, 2, 9, 30, 0, 0, jakarta)))
+
+	require.Equal(t, []RowError{{Index: 2, Err: "invalid-date"}}, result.Errors)
+}
+
+func TestMicrosoftTodoImportInvalid(t *testing.T) {
+	_, err := MicrosoftTodo{}.Import(strings.NewReader(`{"lists": []}`), time.UTC)
+	require.ErrorIs(t, err, ErrInvalidFile)
+}
@@ -0,0 +1,149 @@
+package importer
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const SourceTodoist = "todoist"
+
+// todoistDateLayouts are the absolute dates a Todoist export may contain.
+// Recurring dates such as "every monday" can't be imported.
+var todoistDateLayouts = []struct {
+	layout string
+	allDay bool
+}{
+	{"2006-01-02", true},
+	{"2006-01-02 15:04", false},
+	{"2006-01-02T15:04:05", false},
+	{"Jan 2 2006", true},
+	{"Jan 2 2006 15:04", false},
+	{"Jan 2 2006 3:04 PM", false},
+	{"2 Jan 2006", true},
+	{"2 Jan 2006 15:04", false},
+}
+
+// Todoist reads the CSV export of a Todoist project. Sections become
+// categories and @labels become tags; without a section the first label is
+// used as the category.
+type Todoist struct{}
+
+func (Todoist) Import(r io.Reader, loc *time.Location) (Result, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return Result{}, ErrInvalidFile
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		// the export starts with a byte order mark
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToUpper(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["CONTENT"]; !ok {
+		return Result{}, ErrInvalidFile
+	}
+
+	var result Result
+	section := ""
+	for index := 0; ; index++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return Result{}, ErrInvalidFile
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		switch strings.ToLower(field("TYPE")) {
+		case "section":
+			section = field("CONTENT")
+			continue
+		case "task":
+		case "":
+			// sections are separated by blank lines
+			if field("CONTENT") == "" {
+				continue
+			}
+		default:
+			// notes
+			continue
+		}
+
+		title, labels := splitTodoistLabels(field("CONTENT"))
+		if title == "" {
+			result.Errors = append(result.Errors, RowError{Index: index, Err: "missing-title"})
+			continue
+		}
+
+		item := Item{
+			Title:    title,
+			Content:  field("DESCRIPTION"),
+			Category: section,
+			Tags:     labels,
+		}
+		if item.Category == "" && len(labels) > 0 {
+			item.Category = labels[0]
+		}
+
+		// p1 is the most urgent priority
+		if priority, err := strconv.Atoi(field("PRIORITY")); err == nil {
+			item.Priority = priority == 1 || priority == 2
+		}
+
+		if date := field("DATE"); date != "" {
+			taskLoc := loc
+			if name := field("TIMEZONE"); name != "" {
+				if tz, err := time.LoadLocation(name); err == nil {
+					taskLoc = tz
+				}
+			}
+
+			item.Due, item.AllDay, err = parseTodoistDate(date, taskLoc)
+			if err != nil {
+				result.Errors = append(result.Errors, RowError{Index: index, Err: "unsupported-date"})
+				continue
+			}
+		}
+
+		result.Items = append(result.Items, item)
+	}
+}
+
+func splitTodoistLabels(content string) (string, []string) {
+	var words []string
+	labels := []string{}
+	for _, word := range strings.Fields(content) {
+		if len(word) > 1 && strings.HasPrefix(word, "@") {
+			labels = append(labels, word[1:])
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " "), labels
+}
+
+func parseTodoistDate(value string, loc *time.Location) (time.Time, bool, error) {
+	var err error
+	for _, format := range todoistDateLayouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(format.layout, value, loc); err == nil {
+			return t, format.allDay, nil
+		}
+	}
+	return time.Time{}, false, err
+}
@@ -0,0 +1,65 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoistImport(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	require.NoError(t, err)
+
+	export := strings.Join([]string{
+		"\ufeffTYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE",
+		"task,Pay rent @money @home,before noon,1,1,me,,2020-01-01,en,",
+		",,,,,,,,,",
+		"section,Work,,,,,,,,",
+		"task,Standup @meetings,,4,1,me,,Jan 2 2020 09:30,en,UTC",
+		"note,remember the slides,,,,,,,,",
+		"task,Gym,,3,1,me,,every monday,en,",
+		"task,@lonely,,4,1,me,,,en,",
+	}, "\n")
+
+	result, err := Todoist{}.Import(strings.NewReader(export), jakarta)
+	require.NoError(t, err)
+	require.Len(t, result.Items, 2)
+
+	rent := result.Items[0]
+	require.Equal(t, "Pay rent", rent.Title)
+	require.Equal(t, "before noon", rent.Content)
+	require.Equal(t, "money", rent.Category)
+	require.Equal(t, []string{"money", "home"}, rent.Tags)
+	require.True(t, rent.Priority)
+	require.True(t, rent.AllDay)
+	require.True(t, rent.Due.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, jakarta)))
+
+	standup := result.Items[1]
+	require.Equal(t, "Work", standup.Category)
+	require.False(t, standup.Priority)
+	require.False(t, standup.AllDay)
+	require.Equal(t, "2020-01-02T09:30:00Z", standup.Due.UTC().Format(time.RFC3339))
+
+	require.Equal(t, []RowError{
+		{Index: 5, Err: "unsupported-date"},
+		{Index: 6, Err: "missing-title"},
+	}, result.Errors)
+}
+
+func TestTodoistImportInvalid(t *testing.T) {
+	_, err := Todoist{}.Import(strings.NewReader("name,value\na,b"), time.UTC)
+	require.ErrorIs(t, err, ErrInvalidFile)
+}
+
+func TestLookup(t *testing.T) {
+	importer, err := Lookup(SourceTodoist)
+	require.NoError(t, err)
+	require.IsType(t, Todoist{}, importer)
+
+	_, err = Lookup("wunderlist")
+	require.ErrorIs(t, err, ErrUnknownSource)
+
+	require.Equal(t, []string{SourceMicrosoftTodo, SourceTodoist, SourceTrello}, Sources())
+}
@@ -0,0 +1,97 @@
+package importer
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+const SourceTrello = "trello"
+
+// trelloPriorityLabels mark a card as a priority todo.
+var trelloPriorityLabels = map[string]bool{
+	"priority": true,
+	"urgent":   true,
+	"high":     true,
+}
+
+type trelloBoard struct {
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		Name        string     `json:"name"`
+		Desc        string     `json:"desc"`
+		IDList      string     `json:"idList"`
+		Due         *time.Time `json:"due"`
+		DueComplete bool       `json:"dueComplete"`
+		Closed      bool       `json:"closed"`
+		Labels      []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+	} `json:"cards"`
+}
+
+// Trello reads the JSON export of a board. Lists become categories, labels
+// become tags, and archived cards and lists are skipped.
+type Trello struct{}
+
+func (Trello) Import(r io.Reader, loc *time.Location) (Result, error) {
+	var board trelloBoard
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return Result{}, ErrInvalidFile
+	}
+	if board.Lists == nil && board.Cards == nil {
+		return Result{}, ErrInvalidFile
+	}
+
+	lists := make(map[string]string)
+	closedLists := make(map[string]bool)
+	for _, list := range board.Lists {
+		lists[list.ID] = list.Name
+		closedLists[list.ID] = list.Closed
+	}
+
+	var result Result
+	for index, card := range board.Cards {
+		if card.Closed || closedLists[card.IDList] {
+			continue
+		}
+
+		title := strings.TrimSpace(card.Name)
+		if title == "" {
+			result.Errors = append(result.Errors, RowError{Index: index, Err: "missing-title"})
+			continue
+		}
+
+		item := Item{
+			Title:     title,
+			Content:   card.Desc,
+			Category:  lists[card.IDList],
+			Tags:      []string{},
+			Completed: card.DueComplete,
+		}
+		if card.Due != nil {
+			item.Due = *card.Due
+		}
+		for _, label := range card.Labels {
+			// unnamed labels are only a color
+			name := strings.TrimSpace(label.Name)
+			if name == "" {
+				continue
+			}
+			item.Tags = append(item.Tags, name)
+			if trelloPriorityLabels[strings.ToLower(name)] {
+				item.Priority = true
+			}
+		}
+
+		result.Items = append(result.Items, item)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,52 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrelloImport(t *testing.T) {
+	board := `{
+		"name": "Home",
+		"lists": [
+			{"id": "l1", "name": "To do", "closed": false},
+			{"id": "l2", "name": "Old", "closed": true}
+		],
+		"cards": [
+			{"name": "Pay rent", "desc": "before noon", "idList": "l1", "due": "2020-01-02T09:30:00.000Z",
+			 "labels": [{"name": "Urgent", "color": "red"}, {"name": "", "color": "green"}]},
+			{"name": "Done already", "idList": "l1", "due": null, "dueComplete": true, "labels": []},
+			{"name": "Archived", "idList": "l1", "closed": true},
+			{"name": "In an archived list", "idList": "l2"},
+			{"name": "  ", "idList": "l1"}
+		]
+	}`
+
+	result, err := Trello{}.Import(strings.NewReader(board), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, result.Items, 2)
+
+	rent := result.Items[0]
+	require.Equal(t, "Pay rent", rent.Title)
+	require.Equal(t, "To do", rent.Category)
+	require.Equal(t, []string{"Urgent"}, rent.Tags)
+	require.True(t, rent.Priority)
+	require.Equal(t, "2020-01-02T09:30:00Z", rent.Due.UTC().Format(time.RFC3339))
+
+	done := result.Items[1]
+	require.True(t, done.Completed)
+	require.True(t, done.Due.IsZero())
+
+	require.Equal(t, []RowError{{Index: 4, Err: "missing-title"}}, result.Errors)
+}
+
+func TestTrelloImportInvalid(t *testing.T) {
+	_, err := Trello{}.Import(strings.NewReader(`{"foo": 1}`), time.UTC)
+	require.ErrorIs(t, err, ErrInvalidFile)
+
+	_, err = Trello{}.Import(strings.NewReader(`not json`), time.UTC)
+	require.ErrorIs(t, err, ErrInvalidFile)
+}
@@ -1,13 +1,22 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+	_ "time/tzdata"
 
 	_ "github.com/lib/pq"
 	api "github.com/maslow123/todoapp-services/api"
 	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/gapi"
 	"github.com/maslow123/todoapp-services/util"
+	"github.com/maslow123/todoapp-services/worker"
 )
 
 func main() {
@@ -16,20 +25,111 @@ func main() {
 		log.Fatal("cannot-load-config", err)
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	conn, err := sql.Open(config.DBDriver, config.DBSource)
 	if err != nil {
 		log.Fatal("cannot-connect-db", err)
 	}
 
+	// sql.Open doesn't connect; wait for Postgres before taking traffic
+	connectCtx, cancelConnect := context.WithTimeout(ctx, config.DBConnectTimeout)
+	err = util.Retry(connectCtx, 500*time.Millisecond, 10*time.Second, func() error {
+		err := conn.PingContext(connectCtx)
+		if err != nil {
+			log.Println("waiting-for-db: ", err)
+		}
+		return err
+	})
+	cancelConnect()
+	if err != nil {
+		log.Fatal("cannot-connect-db: ", err)
+	}
+
 	store := db.NewStore(conn)
 	server, err := api.NewServer(config, store)
 	if err != nil {
 		log.Fatal("cannot-create-server", err)
 	}
 
-	log.Println(config.ServerAddress)
-	err = server.Start(config.ServerAddress)
-	if err != nil {
-		log.Fatal("cannot-start-server: ", err)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	runWorker := func(start func(context.Context, time.Duration), interval time.Duration) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			start(workerCtx, interval)
+		}()
+	}
+
+	purger := worker.NewTrashPurger(store, config.TrashRetention)
+	runWorker(purger.Start, config.TrashPurgeInterval)
+
+	idempotencyPurger := worker.NewIdempotencyPurger(store)
+	runWorker(idempotencyPurger.Start, config.IdempotencyPurgeInterval)
+
+	notifiers := map[string]worker.Notifier{
+		worker.ChannelLog:     worker.LogNotifier{},
+		worker.ChannelWebhook: worker.NewWebhookNotifier(config.WebhookTimeout, config.WebhookAllowPrivateTargets),
+		worker.ChannelEmail:   worker.NewEmailNotifier(config.SMTPAddress, config.SMTPFrom),
+	}
+	scheduler := worker.NewReminderScheduler(store, notifiers, config.ReminderBatchSize, config.ReminderMaxAttempts, config.ReminderLease)
+	runWorker(scheduler.Start, config.ReminderPollInterval)
+
+	dispatcher := worker.NewWebhookDispatcher(store, config.WebhookTimeout, config.WebhookAllowPrivateTargets,
+		config.WebhookBatchSize, config.WebhookMaxAttempts, config.WebhookLease)
+	runWorker(dispatcher.Start, config.WebhookPollInterval)
+
+	serverErrs := make(chan error, 2)
+	grpcServer := gapi.NewServer(config, store, server.TokenMaker(), server.Broker(), server.RegisterLimiter(), server.LoginLimiter(), server.UserLimiter())
+	go func() {
+		if err := grpcServer.Start(config.GRPCServerAddress); err != nil {
+			serverErrs <- err
+		}
+	}()
+	go func() {
+		if err := server.Start(config.ServerAddress); err != nil {
+			serverErrs <- err
+		}
+	}()
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		log.Println("shutting-down")
+	case err := <-serverErrs:
+		log.Println("cannot-start-server: ", err)
+		exitCode = 1
+	}
+	stop()
+
+	// the HTTP server keeps serving for the drain delay before its timeout starts
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownDrainDelay+config.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Println("cannot-shutdown-server: ", err)
+	}
+	if err := grpcServer.Shutdown(shutdownCtx); err != nil {
+		log.Println("cannot-shutdown-grpc-server: ", err)
+	}
+
+	// workers stop between runs; a run in progress has its context cancelled
+	stopWorkers()
+	stopped := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		log.Println("cannot-stop-workers: ", shutdownCtx.Err())
+	}
+
+	if err := conn.Close(); err != nil {
+		log.Println("cannot-close-db: ", err)
 	}
+	os.Exit(exitCode)
 }
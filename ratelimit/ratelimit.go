@@ -0,0 +1,109 @@
+// Package ratelimit holds the token bucket limiter shared by the HTTP and
+// gRPC APIs, so a client gets one quota however it connects.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter is a token bucket per key. Each bucket holds up to limit tokens and
+// refills at limit tokens per window, so a client can burst up to limit
+// requests and then keeps a steady limit/window rate.
+type Limiter struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// New returns a limiter, or nil when a zero limit or window turns limiting
+// off. A nil limiter allows everything.
+func New(limit int, window time.Duration) *Limiter {
+	if limit <= 0 || window <= 0 {
+		return nil
+	}
+	return &Limiter{
+		limit:   limit,
+		window:  window,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+	Reset      time.Duration
+}
+
+func (limiter *Limiter) Limit() int {
+	if limiter == nil {
+		return 0
+	}
+	return limiter.limit
+}
+
+func (limiter *Limiter) Allow(key string) Result {
+	if limiter == nil {
+		return Result{Allowed: true}
+	}
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	now := limiter.now()
+	limiter.sweep(now)
+
+	capacity := float64(limiter.limit)
+	perToken := limiter.window / time.Duration(limiter.limit)
+
+	b, ok := limiter.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		limiter.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.last)
+	if elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+float64(elapsed)/float64(perToken))
+		b.last = now
+	}
+
+	result := Result{}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - b.tokens) * float64(perToken))
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = time.Duration((capacity - b.tokens) * float64(perToken))
+
+	return result
+}
+
+// sweep drops buckets that have been idle long enough to refill, since they
+// are no different from a new bucket.
+func (limiter *Limiter) sweep(now time.Time) {
+	if now.Sub(limiter.lastSweep) < limiter.window {
+		return
+	}
+	limiter.lastSweep = now
+
+	for key, b := range limiter.buckets {
+		if now.Sub(b.last) >= limiter.window {
+			delete(limiter.buckets, key)
+		}
+	}
+}
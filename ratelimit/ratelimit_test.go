@@ -0,0 +1,49 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+	limiter := New(2, time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	result := limiter.Allow("a")
+	require.True(t, result.Allowed)
+	require.Equal(t, 1, result.Remaining)
+
+	result = limiter.Allow("a")
+	require.True(t, result.Allowed)
+	require.Equal(t, 0, result.Remaining)
+	require.Equal(t, time.Minute, result.Reset)
+
+	result = limiter.Allow("a")
+	require.False(t, result.Allowed)
+	require.Equal(t, 30*time.Second, result.RetryAfter)
+
+	// other keys have their own bucket
+	require.True(t, limiter.Allow("b").Allowed)
+
+	now = now.Add(30 * time.Second)
+	require.True(t, limiter.Allow("a").Allowed)
+	require.False(t, limiter.Allow("a").Allowed)
+
+	// idle buckets are swept once they would be full again
+	now = now.Add(2 * time.Minute)
+	require.True(t, limiter.Allow("c").Allowed)
+	require.Len(t, limiter.buckets, 1)
+}
+
+func TestDisabledLimiter(t *testing.T) {
+	limiter := New(0, time.Minute)
+	require.Nil(t, limiter)
+	require.Zero(t, limiter.Limit())
+
+	for i := 0; i < 5; i++ {
+		require.True(t, limiter.Allow("a").Allowed)
+	}
+}
@@ -7,15 +7,55 @@ import (
 )
 
 type Config struct {
-	DBDriver               string        `mapstructure:"DB_DRIVER"`
-	DBSource               string        `mapstructure:"DB_SOURCE"`
-	ServerAddress          string        `mapstructure:"SERVER_ADDRESS"`
-	TokenSymmetricKey      string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration    time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	CloudinaryCloudName    string        `mapstructure:"CLOUDINARY_CLOUD_NAME"`
-	CloudinaryApiKey       string        `mapstructure:"CLOUDINARY_API_KEY"`
-	CloudinaryApiSecret    string        `mapstructure:"CLOUDINARY_API_SECRET"`
-	CloudinaryUploadFolder string        `mapstructure:"CLOUDINARY_UPLOAD_FOLDER"`
+	DBDriver                 string        `mapstructure:"DB_DRIVER"`
+	DBSource                 string        `mapstructure:"DB_SOURCE"`
+	DBConnectTimeout         time.Duration `mapstructure:"DB_CONNECT_TIMEOUT"`
+	ServerAddress            string        `mapstructure:"SERVER_ADDRESS"`
+	GRPCServerAddress        string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	HTTPReadHeaderTimeout    time.Duration `mapstructure:"HTTP_READ_HEADER_TIMEOUT"`
+	HTTPReadTimeout          time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`
+	HTTPWriteTimeout         time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`
+	HTTPIdleTimeout          time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout          time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	ShutdownDrainDelay       time.Duration `mapstructure:"SHUTDOWN_DRAIN_DELAY"`
+	ReadinessTimeout         time.Duration `mapstructure:"READINESS_TIMEOUT"`
+	ReadinessCheckUploads    bool          `mapstructure:"READINESS_CHECK_UPLOADS"`
+	TokenSymmetricKey        string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration      time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	CloudinaryCloudName      string        `mapstructure:"CLOUDINARY_CLOUD_NAME"`
+	CloudinaryApiKey         string        `mapstructure:"CLOUDINARY_API_KEY"`
+	CloudinaryApiSecret      string        `mapstructure:"CLOUDINARY_API_SECRET"`
+	CloudinaryUploadFolder   string        `mapstructure:"CLOUDINARY_UPLOAD_FOLDER"`
+	TrashRetention           time.Duration `mapstructure:"TRASH_RETENTION"`
+	TrashPurgeInterval       time.Duration `mapstructure:"TRASH_PURGE_INTERVAL"`
+	ReminderPollInterval     time.Duration `mapstructure:"REMINDER_POLL_INTERVAL"`
+	ReminderBatchSize        int32         `mapstructure:"REMINDER_BATCH_SIZE"`
+	ReminderMaxAttempts      int32         `mapstructure:"REMINDER_MAX_ATTEMPTS"`
+	ReminderLease            time.Duration `mapstructure:"REMINDER_LEASE"`
+	WebhookTimeout           time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	SMTPAddress              string        `mapstructure:"SMTP_ADDRESS"`
+	SMTPFrom                 string        `mapstructure:"SMTP_FROM"`
+	WebhookPollInterval      time.Duration `mapstructure:"WEBHOOK_POLL_INTERVAL"`
+	WebhookBatchSize         int32         `mapstructure:"WEBHOOK_BATCH_SIZE"`
+	WebhookMaxAttempts       int32         `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookLease             time.Duration `mapstructure:"WEBHOOK_LEASE"`
+	EventsKeepAlive          time.Duration `mapstructure:"EVENTS_KEEP_ALIVE"`
+	IdempotencyKeyTTL        time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+	IdempotencyPurgeInterval time.Duration `mapstructure:"IDEMPOTENCY_PURGE_INTERVAL"`
+	RateLimitPublicRequests  int           `mapstructure:"RATE_LIMIT_PUBLIC_REQUESTS"`
+	RateLimitPublicWindow    time.Duration `mapstructure:"RATE_LIMIT_PUBLIC_WINDOW"`
+	RateLimitUserRequests    int           `mapstructure:"RATE_LIMIT_USER_REQUESTS"`
+	RateLimitUserWindow      time.Duration `mapstructure:"RATE_LIMIT_USER_WINDOW"`
+	CORSAllowedOrigins       []string      `mapstructure:"CORS_ALLOWED_ORIGINS"`
+	TrustedProxies           []string      `mapstructure:"TRUSTED_PROXIES"`
+	CORSMaxAge               time.Duration `mapstructure:"CORS_MAX_AGE"`
+	LegacyRoutesSunset       string        `mapstructure:"LEGACY_ROUTES_SUNSET"`
+	GraphQLMaxDepth          int           `mapstructure:"GRAPHQL_MAX_DEPTH"`
+	GraphQLMaxComplexity     int           `mapstructure:"GRAPHQL_MAX_COMPLEXITY"`
+
+	// WebhookAllowPrivateTargets lets webhooks and reminders reach loopback
+	// and private addresses, for local development only.
+	WebhookAllowPrivateTargets bool `mapstructure:"WEBHOOK_ALLOW_PRIVATE_TARGETS"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -23,6 +63,43 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.SetConfigName("app")
 	viper.SetConfigType("env")
 
+	viper.SetDefault("DB_CONNECT_TIMEOUT", "1m")
+	viper.SetDefault("GRPC_SERVER_ADDRESS", "0.0.0.0:9090")
+	viper.SetDefault("HTTP_READ_HEADER_TIMEOUT", "5s")
+	viper.SetDefault("HTTP_READ_TIMEOUT", "30s")
+	viper.SetDefault("HTTP_WRITE_TIMEOUT", "30s")
+	viper.SetDefault("HTTP_IDLE_TIMEOUT", "2m")
+	viper.SetDefault("SHUTDOWN_TIMEOUT", "20s")
+	viper.SetDefault("SHUTDOWN_DRAIN_DELAY", "5s")
+	viper.SetDefault("READINESS_TIMEOUT", "2s")
+	viper.SetDefault("READINESS_CHECK_UPLOADS", false)
+	viper.SetDefault("TRASH_RETENTION", "720h")
+	viper.SetDefault("TRASH_PURGE_INTERVAL", "1h")
+	viper.SetDefault("REMINDER_POLL_INTERVAL", "30s")
+	viper.SetDefault("REMINDER_BATCH_SIZE", 50)
+	viper.SetDefault("REMINDER_MAX_ATTEMPTS", 5)
+	viper.SetDefault("REMINDER_LEASE", "10m")
+	viper.SetDefault("WEBHOOK_TIMEOUT", "10s")
+	viper.SetDefault("WEBHOOK_ALLOW_PRIVATE_TARGETS", false)
+	viper.SetDefault("SMTP_FROM", "todoapp@localhost")
+	viper.SetDefault("WEBHOOK_POLL_INTERVAL", "5s")
+	viper.SetDefault("WEBHOOK_BATCH_SIZE", 50)
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	viper.SetDefault("WEBHOOK_LEASE", "10m")
+	viper.SetDefault("EVENTS_KEEP_ALIVE", "25s")
+	viper.SetDefault("IDEMPOTENCY_KEY_TTL", "24h")
+	viper.SetDefault("IDEMPOTENCY_PURGE_INTERVAL", "1h")
+	viper.SetDefault("RATE_LIMIT_PUBLIC_REQUESTS", 10)
+	viper.SetDefault("RATE_LIMIT_PUBLIC_WINDOW", "1m")
+	viper.SetDefault("RATE_LIMIT_USER_REQUESTS", 300)
+	viper.SetDefault("RATE_LIMIT_USER_WINDOW", "1m")
+	viper.SetDefault("CORS_ALLOWED_ORIGINS", "*")
+	viper.SetDefault("TRUSTED_PROXIES", "")
+	viper.SetDefault("CORS_MAX_AGE", "12h")
+	viper.SetDefault("LEGACY_ROUTES_SUNSET", "2027-04-30")
+	viper.SetDefault("GRAPHQL_MAX_DEPTH", 10)
+	viper.SetDefault("GRAPHQL_MAX_COMPLEXITY", 1000)
+
 	viper.AutomaticEnv()
 
 	err = viper.ReadInConfig()
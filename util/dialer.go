@@ -0,0 +1,62 @@
+package util
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+var ErrNonPublicAddress = errors.New("non-public-address")
+
+// sharedAddressSpace is carrier-grade NAT space, which net.IP.IsPrivate
+// doesn't cover but is just as internal.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPublicIP reports whether ip is a unicast address on the public internet,
+// i.e. not loopback, link-local (which includes cloud metadata endpoints),
+// private or otherwise reserved for local use.
+func IsPublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() &&
+		!ip.IsPrivate() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// publicOnly is a net.Dialer Control func. It runs on the address actually
+// being dialed, after DNS resolution, so a hostname that resolves to an
+// internal address is refused too, as is every redirect.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublicIP(ip) {
+		return ErrNonPublicAddress
+	}
+	return nil
+}
+
+// NewOutboundHTTPClient returns a client for calling URLs users supplied,
+// such as webhooks. Unless allowPrivate is set it only connects to public
+// addresses, so the URLs can't be used to reach internal services.
+func NewOutboundHTTPClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	if allowPrivate {
+		return &http.Client{Timeout: timeout}
+	}
+
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: publicOnly,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would do the dialing itself and bypass the check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"context"
+	"errors"
 	"log"
 	"time"
 
@@ -37,3 +38,24 @@ func ImageUploadHelper(input interface{}) (string, error) {
 
 	return uploadParam.SecureURL, nil
 }
+
+// PingUploads checks that the Cloudinary account uploads go to answers.
+func PingUploads(ctx context.Context, config Config) error {
+	cld, err := cloudinary.NewFromParams(
+		config.CloudinaryCloudName,
+		config.CloudinaryApiKey,
+		config.CloudinaryApiSecret,
+	)
+	if err != nil {
+		return err
+	}
+
+	result, err := cld.Admin.Ping(ctx)
+	if err != nil {
+		return err
+	}
+	if result.Error.Message != "" {
+		return errors.New(result.Error.Message)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package util
+
+import (
+	"context"
+	"time"
+)
+
+// Retry calls fn until it succeeds or ctx is done, waiting between attempts
+// from initial, doubling up to max. It gives fn's last error when ctx ends
+// first.
+func Retry(ctx context.Context, initial, max time.Duration, fn func() error) error {
+	wait := initial
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		wait *= 2
+		if wait > max {
+			wait = max
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package util
+
+import (
+	"errors"
+	"time"
+)
+
+const DefaultTimeZone = "UTC"
+
+var ErrInvalidTimeZone = errors.New("invalid-time-zone")
+
+// LoadTimeZone resolves an IANA time zone name such as "Asia/Jakarta".
+func LoadTimeZone(name string) (*time.Location, error) {
+	// "" and "Local" are accepted by time.LoadLocation but depend on the server
+	if name == "" || name == "Local" {
+		return nil, ErrInvalidTimeZone
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimeZone
+	}
+	return loc, nil
+}
+
+// ParseDueDate accepts an RFC 3339 timestamp, a local "2006-01-02T15:04" date
+// time or a plain "2006-01-02" date. Values without an offset are read in loc.
+// allDay is true when no time of day was given.
+func ParseDueDate(value string, loc *time.Location) (due time.Time, allDay bool, err error) {
+	if due, err = time.Parse(time.RFC3339, value); err == nil {
+		return due, false, nil
+	}
+	if due, err = time.ParseInLocation("2006-01-02T15:04", value, loc); err == nil {
+		return due, false, nil
+	}
+	if due, err = time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return due, true, nil
+	}
+	return time.Time{}, false, err
+}
+
+// StartOfDay returns midnight of t's date in loc.
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
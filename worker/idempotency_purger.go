@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+)
+
+// IdempotencyPurger removes stored responses whose idempotency keys have
+// expired. Expired keys can already be reused, so this only reclaims space.
+type IdempotencyPurger struct {
+	store db.Store
+}
+
+func NewIdempotencyPurger(store db.Store) *IdempotencyPurger {
+	return &IdempotencyPurger{
+		store: store,
+	}
+}
+
+// Start runs a purge every interval until ctx is cancelled.
+func (purger *IdempotencyPurger) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := purger.Purge(ctx); err != nil {
+				log.Println("cannot-purge-idempotency-keys: ", err)
+			}
+		}
+	}
+}
+
+func (purger *IdempotencyPurger) Purge(ctx context.Context) error {
+	keys, err := purger.store.PurgeExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	if keys > 0 {
+		log.Printf("purged %d expired idempotency keys", keys)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"time"
+
+	"github.com/maslow123/todoapp-services/util"
+)
+
+const (
+	ChannelLog     = "log"
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+)
+
+var ErrUnknownChannel = errors.New("unknown-channel")
+
+// Notification is what a delivery channel receives for a due reminder.
+type Notification struct {
+	ReminderID int32     `json:"reminder_id"`
+	TodoID     int32     `json:"todo_id"`
+	UserEmail  string    `json:"user_email"`
+	Title      string    `json:"title"`
+	DueAt      time.Time `json:"due_at"`
+	RemindAt   time.Time `json:"remind_at"`
+	// Target is the channel specific destination, e.g. a webhook URL.
+	Target string `json:"-"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, notification Notification) error {
+	log.Printf("reminder %d: %q for %s is due at %s",
+		notification.ReminderID, notification.Title, notification.UserEmail, notification.DueAt.Format(time.RFC3339))
+	return nil
+}
+
+// WebhookNotifier POSTs the notification as JSON to the reminder's target URL.
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+// NewWebhookNotifier only posts to public addresses unless allowPrivate is
+// set, see util.NewOutboundHTTPClient.
+func NewWebhookNotifier(timeout time.Duration, allowPrivate bool) *WebhookNotifier {
+	return &WebhookNotifier{
+		client: util.NewOutboundHTTPClient(timeout, allowPrivate),
+	}
+}
+
+func (notifier *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, notification.Target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := notifier.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// EmailNotifier sends plain text mail through an SMTP relay, such as a local
+// MailHog in development. It only mails the user's own address, so reminders
+// can't be used to send text of the user's choosing to anyone else.
+type EmailNotifier struct {
+	address string
+	from    string
+}
+
+func NewEmailNotifier(address, from string) *EmailNotifier {
+	return &EmailNotifier{
+		address: address,
+		from:    from,
+	}
+}
+
+func (notifier *EmailNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notifier.address == "" {
+		return errors.New("smtp-not-configured")
+	}
+
+	to := notification.UserEmail
+
+	// the encoded subject can't carry line breaks into the headers
+	subject := mime.QEncoding.Encode("utf-8", "Reminder: "+notification.Title)
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%q is due at %s.\r\n",
+		notifier.from, to, subject, notification.Title, notification.DueAt.Format(time.RFC1123Z))
+
+	return smtp.SendMail(notifier.address, nil, notifier.from, []string{to}, []byte(message))
+}
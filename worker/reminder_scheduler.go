@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+)
+
+// ReminderScheduler polls for due reminders and hands them to the notifier
+// registered for their channel.
+type ReminderScheduler struct {
+	store       db.Store
+	notifiers   map[string]Notifier
+	batchSize   int32
+	maxAttempts int32
+	lease       time.Duration
+}
+
+func NewReminderScheduler(store db.Store, notifiers map[string]Notifier, batchSize, maxAttempts int32, lease time.Duration) *ReminderScheduler {
+	return &ReminderScheduler{
+		store:       store,
+		notifiers:   notifiers,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+		lease:       lease,
+	}
+}
+
+// Start delivers due reminders every interval until ctx is cancelled.
+func (scheduler *ReminderScheduler) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := scheduler.RunOnce(ctx); err != nil {
+				log.Println("cannot-deliver-reminders: ", err)
+			}
+		}
+	}
+}
+
+func (scheduler *ReminderScheduler) RunOnce(ctx context.Context) error {
+	result, err := scheduler.store.DeliverReminders(ctx, db.DeliverRemindersParams{
+		Limit:       scheduler.batchSize,
+		Lease:       scheduler.lease,
+		Deliver:     scheduler.deliver,
+		MaxAttempts: scheduler.maxAttempts,
+		RetryDelay:  retryDelay,
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.Sent > 0 || result.Retried > 0 || result.Failed > 0 {
+		log.Printf("reminders: %d sent, %d retried, %d failed", result.Sent, result.Retried, result.Failed)
+	}
+	return nil
+}
+
+func (scheduler *ReminderScheduler) deliver(ctx context.Context, reminder db.ClaimDueRemindersRow) error {
+	notifier, ok := scheduler.notifiers[reminder.Channel]
+	if !ok {
+		return ErrUnknownChannel
+	}
+
+	return notifier.Notify(ctx, Notification{
+		ReminderID: reminder.ID,
+		TodoID:     reminder.TodoID,
+		UserEmail:  reminder.UserEmail,
+		Title:      reminder.Title,
+		DueAt:      reminder.Date,
+		RemindAt:   reminder.RemindAt,
+		Target:     reminder.Target,
+	})
+}
+
+// retryDelay backs off exponentially from 30s, capped at an hour.
+func retryDelay(attempts int32) time.Duration {
+	delay := 30 * time.Second
+	for i := int32(1); i < attempts && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	if delay > time.Hour {
+		delay = time.Hour
+	}
+	return delay
+}
@@ -0,0 +1,180 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReminderScheduler(t *testing.T) {
+	var received Notification
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer hook.Close()
+
+	notifiers := map[string]Notifier{
+		ChannelLog:     LogNotifier{},
+		ChannelWebhook: NewWebhookNotifier(time.Second, true),
+	}
+
+	reminders := []db.ClaimDueRemindersRow{
+		{ID: 1, TodoID: 10, Channel: ChannelLog, Title: "log me"},
+		{ID: 2, TodoID: 11, Channel: ChannelWebhook, Target: hook.URL, Title: "hook me"},
+		{ID: 3, TodoID: 12, Channel: "pigeon", Title: "lost"},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		DeliverReminders(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.DeliverRemindersParams) (db.DeliverRemindersResult, error) {
+			require.Equal(t, int32(10), arg.Limit)
+			require.Equal(t, time.Minute, arg.Lease)
+			require.Equal(t, int32(3), arg.MaxAttempts)
+
+			require.NoError(t, arg.Deliver(ctx, reminders[0]))
+			require.NoError(t, arg.Deliver(ctx, reminders[1]))
+			require.ErrorIs(t, arg.Deliver(ctx, reminders[2]), ErrUnknownChannel)
+			return db.DeliverRemindersResult{Sent: 2, Retried: 1}, nil
+		})
+
+	scheduler := NewReminderScheduler(store, notifiers, 10, 3, time.Minute)
+	err := scheduler.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int32(2), received.ReminderID)
+	require.Equal(t, "hook me", received.Title)
+}
+
+func TestReminderSchedulerError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		DeliverReminders(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.DeliverRemindersResult{}, sql.ErrConnDone)
+
+	scheduler := NewReminderScheduler(store, map[string]Notifier{}, 10, 3, time.Minute)
+	err := scheduler.RunOnce(context.Background())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
+
+func TestWebhookNotifierStatus(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer hook.Close()
+
+	err := NewWebhookNotifier(time.Second, true).Notify(context.Background(), Notification{Target: hook.URL})
+	require.Error(t, err)
+}
+
+func TestWebhookNotifierPrivateTarget(t *testing.T) {
+	var called bool
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer hook.Close()
+
+	// the test server listens on loopback, which is refused when dialing
+	err := NewWebhookNotifier(time.Second, false).Notify(context.Background(), Notification{Target: hook.URL})
+	require.ErrorIs(t, err, util.ErrNonPublicAddress)
+	require.False(t, called)
+}
+
+func TestEmailNotifier(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan smtpMessage, 1)
+	go serveSMTP(t, listener, received)
+
+	notification := Notification{
+		UserEmail: util.RandomEmail(),
+		Title:     "Pay rent\r\nBcc: victim@example.com\r\n\r\nspam",
+		DueAt:     time.Now(),
+		Target:    "victim@example.com",
+	}
+	err = NewEmailNotifier(listener.Addr().String(), "todoapp@localhost").Notify(context.Background(), notification)
+	require.NoError(t, err)
+
+	// the target is ignored and the title can't add headers
+	message := <-received
+	require.Equal(t, []string{notification.UserEmail}, message.recipients)
+	headers := strings.Split(message.data[:strings.Index(message.data, "\r\n\r\n")], "\r\n")
+	require.Len(t, headers, 3)
+	require.True(t, strings.HasPrefix(headers[2], "Subject: =?utf-8?q?Reminder:"))
+}
+
+type smtpMessage struct {
+	recipients []string
+	data       string
+}
+
+// serveSMTP accepts a single message, speaking just enough SMTP for
+// smtp.SendMail.
+func serveSMTP(t *testing.T, listener net.Listener, received chan<- smtpMessage) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	var message smtpMessage
+	text.PrintfLine("220 localhost")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO", "HELO", "MAIL", "RSET", "NOOP":
+			text.PrintfLine("250 ok")
+		case "RCPT":
+			address := line[strings.Index(line, "<")+1 : strings.LastIndex(line, ">")]
+			message.recipients = append(message.recipients, address)
+			text.PrintfLine("250 ok")
+		case "DATA":
+			text.PrintfLine("354 go ahead")
+			data, err := text.ReadDotBytes()
+			require.NoError(t, err)
+			message.data = strings.ReplaceAll(string(data), "\n", "\r\n")
+			text.PrintfLine("250 ok")
+			received <- message
+		case "QUIT":
+			text.PrintfLine("221 bye")
+			return
+		default:
+			text.PrintfLine("502 unsupported")
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	require.Equal(t, 30*time.Second, retryDelay(1))
+	require.Equal(t, time.Minute, retryDelay(2))
+	require.Equal(t, 2*time.Minute, retryDelay(3))
+	require.Equal(t, time.Hour, retryDelay(20))
+}
@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+)
+
+// TrashPurger permanently removes todos and categories that have been in the
+// trash for longer than the retention period.
+type TrashPurger struct {
+	store     db.Store
+	retention time.Duration
+}
+
+func NewTrashPurger(store db.Store, retention time.Duration) *TrashPurger {
+	return &TrashPurger{
+		store:     store,
+		retention: retention,
+	}
+}
+
+// Start runs a purge every interval until ctx is cancelled.
+func (purger *TrashPurger) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := purger.Purge(ctx); err != nil {
+				log.Println("cannot-purge-trash: ", err)
+			}
+		}
+	}
+}
+
+func (purger *TrashPurger) Purge(ctx context.Context) error {
+	deletedBefore := time.Now().Add(-purger.retention)
+
+	// todos first, so categories emptied by this run can go as well
+	todos, err := purger.store.PurgeTrashedTodos(ctx, deletedBefore)
+	if err != nil {
+		return err
+	}
+
+	categories, err := purger.store.PurgeTrashedCategories(ctx, deletedBefore)
+	if err != nil {
+		return err
+	}
+
+	if todos > 0 || categories > 0 {
+		log.Printf("purged %d todos and %d categories from trash", todos, categories)
+	}
+
+	return nil
+}
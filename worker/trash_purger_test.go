@@ -0,0 +1,110 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type deletedBeforeMatcher struct {
+	expected time.Time
+}
+
+func (m deletedBeforeMatcher) Matches(x interface{}) bool {
+	got, ok := x.(time.Time)
+	if !ok {
+		return false
+	}
+
+	diff := got.Sub(m.expected)
+	return diff > -time.Second && diff < time.Second
+}
+
+func (m deletedBeforeMatcher) String() string {
+	return "is around " + m.expected.String()
+}
+
+func TestTrashPurger(t *testing.T) {
+	retention := 24 * time.Hour
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				deletedBefore := deletedBeforeMatcher{time.Now().Add(-retention)}
+
+				gomock.InOrder(
+					store.EXPECT().
+						PurgeTrashedTodos(gomock.Any(), deletedBefore).
+						Times(1).
+						Return(int64(3), nil),
+					store.EXPECT().
+						PurgeTrashedCategories(gomock.Any(), deletedBefore).
+						Times(1).
+						Return(int64(1), nil),
+				)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "TodosError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PurgeTrashedTodos(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+
+				store.EXPECT().
+					PurgeTrashedCategories(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+		{
+			name: "CategoriesError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PurgeTrashedTodos(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+
+				store.EXPECT().
+					PurgeTrashedCategories(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			purger := NewTrashPurger(store, retention)
+			err := purger.Purge(context.Background())
+			tc.checkError(t, err)
+		})
+	}
+}
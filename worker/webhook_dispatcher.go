@@ -0,0 +1,115 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/util"
+)
+
+const (
+	HeaderWebhookEvent     = "X-Todoapp-Event"
+	HeaderWebhookDelivery  = "X-Todoapp-Delivery"
+	HeaderWebhookTimestamp = "X-Todoapp-Timestamp"
+	HeaderWebhookSignature = "X-Todoapp-Signature"
+)
+
+// WebhookDispatcher sends outbox deliveries to the users' webhook endpoints.
+type WebhookDispatcher struct {
+	store       db.Store
+	client      *http.Client
+	batchSize   int32
+	maxAttempts int32
+	lease       time.Duration
+}
+
+// NewWebhookDispatcher only posts to public addresses unless allowPrivate is
+// set, see util.NewOutboundHTTPClient.
+func NewWebhookDispatcher(store db.Store, timeout time.Duration, allowPrivate bool, batchSize, maxAttempts int32, lease time.Duration) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		store:       store,
+		client:      util.NewOutboundHTTPClient(timeout, allowPrivate),
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+		lease:       lease,
+	}
+}
+
+// Start dispatches due deliveries every interval until ctx is cancelled.
+func (dispatcher *WebhookDispatcher) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := dispatcher.RunOnce(ctx); err != nil {
+				log.Println("cannot-dispatch-webhooks: ", err)
+			}
+		}
+	}
+}
+
+func (dispatcher *WebhookDispatcher) RunOnce(ctx context.Context) error {
+	result, err := dispatcher.store.DeliverWebhooks(ctx, db.DeliverWebhooksParams{
+		Limit:       dispatcher.batchSize,
+		Lease:       dispatcher.lease,
+		Deliver:     dispatcher.deliver,
+		MaxAttempts: dispatcher.maxAttempts,
+		RetryDelay:  retryDelay,
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.Delivered > 0 || result.Retried > 0 || result.Failed > 0 {
+		log.Printf("webhooks: %d delivered, %d retried, %d failed", result.Delivered, result.Retried, result.Failed)
+	}
+	return nil
+}
+
+func (dispatcher *WebhookDispatcher) deliver(ctx context.Context, delivery db.ClaimWebhookDeliveriesRow) (int32, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderWebhookEvent, delivery.Event)
+	request.Header.Set(HeaderWebhookDelivery, strconv.Itoa(int(delivery.ID)))
+	request.Header.Set(HeaderWebhookTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderWebhookSignature, SignWebhookPayload(delivery.Secret, timestamp, delivery.Payload))
+
+	response, err := dispatcher.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	status := int32(response.StatusCode)
+	if status < 200 || status >= 300 {
+		return status, fmt.Errorf("webhook responded with status %d", status)
+	}
+	return status, nil
+}
+
+// SignWebhookPayload returns the signature header value for payload. Receivers
+// recompute it over "<timestamp>.<body>" with their secret to verify a request.
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
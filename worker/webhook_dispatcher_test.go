@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/maslow123/todoapp-services/db/mock"
+	db "github.com/maslow123/todoapp-services/db/sqlc"
+	"github.com/maslow123/todoapp-services/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDispatcher(t *testing.T) {
+	secret := "top-secret"
+	payload := []byte(`{"event":"todo.created"}`)
+
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		timestamp, err := strconv.ParseInt(r.Header.Get(HeaderWebhookTimestamp), 10, 64)
+		require.NoError(t, err)
+		require.Equal(t, SignWebhookPayload(secret, timestamp, body), r.Header.Get(HeaderWebhookSignature))
+		require.Equal(t, db.EventTodoCreated, r.Header.Get(HeaderWebhookEvent))
+		require.Equal(t, "7", r.Header.Get(HeaderWebhookDelivery))
+
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer hook.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		DeliverWebhooks(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.DeliverWebhooksParams) (db.DeliverWebhooksResult, error) {
+			require.Equal(t, time.Minute, arg.Lease)
+
+			delivery := db.ClaimWebhookDeliveriesRow{
+				ID:      7,
+				Event:   db.EventTodoCreated,
+				Payload: payload,
+				Url:     hook.URL,
+				Secret:  secret,
+			}
+
+			status, err := arg.Deliver(ctx, delivery)
+			require.NoError(t, err)
+			require.Equal(t, int32(http.StatusAccepted), status)
+
+			delivery.Url = hook.URL + "/down"
+			status, err = arg.Deliver(ctx, delivery)
+			require.Error(t, err)
+			require.Equal(t, int32(http.StatusServiceUnavailable), status)
+
+			return db.DeliverWebhooksResult{Delivered: 1, Retried: 1}, nil
+		})
+
+	dispatcher := NewWebhookDispatcher(store, time.Second, true, 10, 3, time.Minute)
+	err := dispatcher.RunOnce(context.Background())
+	require.NoError(t, err)
+}
+
+func TestWebhookDispatcherPrivateTarget(t *testing.T) {
+	var called bool
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer hook.Close()
+
+	dispatcher := NewWebhookDispatcher(nil, time.Second, false, 10, 3, time.Minute)
+	status, err := dispatcher.deliver(context.Background(), db.ClaimWebhookDeliveriesRow{Url: hook.URL})
+	require.ErrorIs(t, err, util.ErrNonPublicAddress)
+	require.Zero(t, status)
+	require.False(t, called)
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	a := SignWebhookPayload("secret", 1, []byte("{}"))
+	require.Equal(t, a, SignWebhookPayload("secret", 1, []byte("{}")))
+	require.NotEqual(t, a, SignWebhookPayload("other", 1, []byte("{}")))
+	require.NotEqual(t, a, SignWebhookPayload("secret", 2, []byte("{}")))
+	require.Len(t, a, len("sha256=")+64)
+}